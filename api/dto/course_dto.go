@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/google/uuid"
 )
 
 // CourseRequest defines the JSON body for creating/updating courses.
@@ -16,6 +17,7 @@ type CourseRequest struct {
 	IsPublished bool           `json:"is_published"`
 	Tags        []string       `json:"tags"`
 	Metadata    map[string]any `json:"metadata"`
+	Version     int            `json:"version"` // Required on update unless If-Match is sent
 }
 
 // CourseResponse defines the JSON returned by course endpoints.
@@ -30,8 +32,17 @@ type CourseResponse struct {
 	Tags        []string       `json:"tags,omitempty"`
 	Metadata    map[string]any `json:"metadata,omitempty"`
 	CreatorID   *string        `json:"creator_id,omitempty"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
+
+	PublishAt            *time.Time `json:"publish_at,omitempty"`
+	UnpublishAt          *time.Time `json:"unpublish_at,omitempty"`
+	PublishScheduledBy   *string    `json:"publish_scheduled_by,omitempty"`
+	UnpublishScheduledBy *string    `json:"unpublish_scheduled_by,omitempty"`
+
+	Version   int        `json:"version"`
+	Status    string     `json:"status"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
 }
 
 // ToModel converts CourseRequest to model.Course.
@@ -67,8 +78,26 @@ func FromModel(c model.Course) CourseResponse {
 		Tags:        c.Tags,
 		Metadata:    c.Metadata,
 		CreatorID:   creatorID,
-		CreatedAt:   c.CreatedAt,
-		UpdatedAt:   c.UpdatedAt,
+
+		PublishAt:            c.PublishAt,
+		UnpublishAt:          c.UnpublishAt,
+		PublishScheduledBy:   optionalID(c.PublishScheduledBy),
+		UnpublishScheduledBy: optionalID(c.UnpublishScheduledBy),
+
+		Version:   c.Version,
+		Status:    string(c.Status),
+		DeletedAt: c.DeletedAt,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
+
+// optionalID renders an optional UUID as an optional string.
+func optionalID(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
 	}
+	s := id.String()
+	return &s
 }
 
@@ -0,0 +1,116 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/utils"
+)
+
+// ExerciseRequest defines the incoming JSON for creating or updating an
+// exercise. Updates ignore lesson_id and order_index; exercises are moved
+// and reordered through PUT /api/exercises/lesson/:lessonId/order.
+type ExerciseRequest struct {
+	LessonID     string         `json:"lesson_id"`               // Required on create
+	Title        string         `json:"title"`                   // Required
+	Type         string         `json:"type"`                    // Required
+	MatchingType *string        `json:"matching_type,omitempty"` // Only for matching exercises
+	Prompt       string         `json:"prompt"`
+	MediaURL     *string        `json:"media_url,omitempty"`
+	OrderIndex   int            `json:"order_index"`
+	Points       int            `json:"points"`
+	Grade        int            `json:"grade"`
+	Syllabus     string         `json:"syllabus"`
+	ObjectiveTag string         `json:"objective"`
+	Metadata     map[string]any `json:"metadata"`
+	Version      int            `json:"version"` // Required on update unless If-Match is sent
+}
+
+// GradeExerciseRequest defines the JSON body for grading a learner response.
+type GradeExerciseRequest struct {
+	Response map[string]any `json:"response"`
+}
+
+// ExerciseResponse defines the JSON response for exercise data.
+type ExerciseResponse struct {
+	ID           string         `json:"id"`
+	SkillID      string         `json:"skill_id"`
+	LessonID     string         `json:"lesson_id"`
+	Title        string         `json:"title"`
+	Type         string         `json:"type"`
+	MatchingType *string        `json:"matching_type,omitempty"`
+	Prompt       string         `json:"prompt"`
+	MediaURL     *string        `json:"media_url,omitempty"`
+	OrderIndex   int            `json:"order_index"`
+	Points       int            `json:"points"`
+	Grade        int            `json:"grade"`
+	Syllabus     string         `json:"syllabus"`
+	ObjectiveTag string         `json:"objective"`
+	Metadata     map[string]any `json:"metadata"`
+	Version      int            `json:"version"`
+	Status       string         `json:"status"`
+	DeletedAt    *time.Time     `json:"deleted_at,omitempty"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+
+	// MediaURLExpiresAt is set when media_url is a signed URL
+	MediaURLExpiresAt *time.Time `json:"media_url_expires_at,omitempty"`
+	// MediaSrcset lists the resized variants of an image media_url by
+	// format, e.g. {"jpeg": "<url> 256w, <url> 1080w, <url> 2048w"}
+	MediaSrcset map[string]string `json:"media_srcset,omitempty"`
+}
+
+// ToModel converts an ExerciseRequest to model.Exercise.
+func (r ExerciseRequest) ToModel() model.Exercise {
+	var matchingType *model.MatchingType
+	if r.MatchingType != nil && *r.MatchingType != "" {
+		mt := model.MatchingType(*r.MatchingType)
+		matchingType = &mt
+	}
+
+	return model.Exercise{
+		LessonID:     utils.ParseUUID(r.LessonID),
+		Title:        r.Title,
+		Type:         model.ExerciseType(r.Type),
+		MatchingType: matchingType,
+		Prompt:       r.Prompt,
+		MediaURL:     r.MediaURL,
+		OrderIndex:   r.OrderIndex,
+		Points:       r.Points,
+		Grade:        r.Grade,
+		Syllabus:     r.Syllabus,
+		ObjectiveTag: r.ObjectiveTag,
+		Metadata:     r.Metadata,
+	}
+}
+
+// FromExerciseModel maps model.Exercise to ExerciseResponse.
+func FromExerciseModel(e model.Exercise) ExerciseResponse {
+	var matchingType *string
+	if e.MatchingType != nil {
+		mt := string(*e.MatchingType)
+		matchingType = &mt
+	}
+
+	return ExerciseResponse{
+		ID:           e.ID.String(),
+		SkillID:      e.SkillID.String(),
+		LessonID:     e.LessonID.String(),
+		Title:        e.Title,
+		Type:         string(e.Type),
+		MatchingType: matchingType,
+		Prompt:       e.Prompt,
+		MediaURL:     e.MediaURL,
+		OrderIndex:   e.OrderIndex,
+		Points:       e.Points,
+		Grade:        e.Grade,
+		Syllabus:     e.Syllabus,
+		ObjectiveTag: e.ObjectiveTag,
+		Metadata:     e.Metadata,
+		Version:      e.Version,
+		Status:       string(e.Status),
+		DeletedAt:    e.DeletedAt,
+		CreatedAt:    e.CreatedAt,
+		UpdatedAt:    e.UpdatedAt,
+	}
+}
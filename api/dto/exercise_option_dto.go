@@ -0,0 +1,83 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/utils"
+)
+
+// ExerciseOptionRequest defines the incoming JSON for a single exercise option.
+type ExerciseOptionRequest struct {
+	ID        string  `json:"id,omitempty"` // Optional, keeps an existing option on replace
+	Label     string  `json:"label"`
+	Value     string  `json:"value"`
+	IsCorrect bool    `json:"is_correct"`
+	MediaURL  *string `json:"media_url,omitempty"`
+}
+
+// ReplaceExerciseOptionsRequest defines the JSON body for replacing all options.
+// Options are stored in the order they appear in the list.
+type ReplaceExerciseOptionsRequest struct {
+	Options []ExerciseOptionRequest `json:"options"`
+}
+
+// ReorderExerciseOptionsRequest defines the JSON body for reordering options.
+type ReorderExerciseOptionsRequest struct {
+	OptionIDs []string `json:"option_ids"`
+}
+
+// ExerciseOptionResponse defines the JSON response for exercise option data.
+type ExerciseOptionResponse struct {
+	ID         string    `json:"id"`
+	ExerciseID string    `json:"exercise_id"`
+	Label      string    `json:"label"`
+	Value      string    `json:"value"`
+	IsCorrect  bool      `json:"is_correct"`
+	MediaURL   *string   `json:"media_url,omitempty"`
+	OrderIndex int       `json:"order_index"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	// MediaURLExpiresAt is set when media_url is a signed URL
+	MediaURLExpiresAt *time.Time `json:"media_url_expires_at,omitempty"`
+	// MediaSrcset lists the resized variants of an image media_url by
+	// format, e.g. {"jpeg": "<url> 256w, <url> 1080w, <url> 2048w"}
+	MediaSrcset map[string]string `json:"media_srcset,omitempty"`
+}
+
+// ToModel converts an ExerciseOptionRequest to model.ExerciseOption.
+func (r ExerciseOptionRequest) ToModel() model.ExerciseOption {
+	return model.ExerciseOption{
+		ID:        utils.ParseUUID(r.ID),
+		Label:     r.Label,
+		Value:     r.Value,
+		IsCorrect: r.IsCorrect,
+		MediaURL:  r.MediaURL,
+	}
+}
+
+// ToModels converts the replacement list to model.ExerciseOption pointers.
+func (r ReplaceExerciseOptionsRequest) ToModels() []*model.ExerciseOption {
+	options := make([]*model.ExerciseOption, 0, len(r.Options))
+	for _, o := range r.Options {
+		option := o.ToModel()
+		options = append(options, &option)
+	}
+	return options
+}
+
+// FromExerciseOptionModel maps model.ExerciseOption to ExerciseOptionResponse.
+func FromExerciseOptionModel(o model.ExerciseOption) ExerciseOptionResponse {
+	return ExerciseOptionResponse{
+		ID:         o.ID.String(),
+		ExerciseID: o.ExerciseID.String(),
+		Label:      o.Label,
+		Value:      o.Value,
+		IsCorrect:  o.IsCorrect,
+		MediaURL:   o.MediaURL,
+		OrderIndex: o.OrderIndex,
+		CreatedAt:  o.CreatedAt,
+		UpdatedAt:  o.UpdatedAt,
+	}
+}
@@ -0,0 +1,56 @@
+package dto
+
+import "github.com/bytebeatz/bandroom-cms/core/model"
+
+// ImportChangeResponse defines the JSON for one entity an import creates,
+// updates or deletes. Key is the slug or title the document knows it by.
+type ImportChangeResponse struct {
+	Action     string   `json:"action"`
+	EntityType string   `json:"entity_type"`
+	EntityID   string   `json:"entity_id"`
+	Key        string   `json:"key"`
+	Fields     []string `json:"fields,omitempty"`
+}
+
+// ImportSummary counts the changes of an import by action.
+type ImportSummary struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Deleted int `json:"deleted"`
+}
+
+// ImportResponse defines the JSON returned for an import. With dry_run the
+// changes are what the import would do; nothing has been written.
+type ImportResponse struct {
+	DryRun   bool                   `json:"dry_run"`
+	CourseID string                 `json:"course_id"`
+	Summary  ImportSummary          `json:"summary"`
+	Changes  []ImportChangeResponse `json:"changes"`
+}
+
+// FromImportPlan maps model.ImportPlan to ImportResponse.
+func FromImportPlan(p model.ImportPlan, dryRun bool) ImportResponse {
+	res := ImportResponse{
+		DryRun:   dryRun,
+		CourseID: p.CourseID.String(),
+		Changes:  make([]ImportChangeResponse, 0, len(p.Changes)),
+	}
+	for _, ch := range p.Changes {
+		switch ch.Action {
+		case model.ImportCreate:
+			res.Summary.Created++
+		case model.ImportUpdate:
+			res.Summary.Updated++
+		case model.ImportDelete:
+			res.Summary.Deleted++
+		}
+		res.Changes = append(res.Changes, ImportChangeResponse{
+			Action:     string(ch.Action),
+			EntityType: string(ch.EntityType),
+			EntityID:   ch.EntityID.String(),
+			Key:        ch.Key,
+			Fields:     ch.Fields,
+		})
+	}
+	return res
+}
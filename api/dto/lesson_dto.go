@@ -25,6 +25,7 @@ type LessonRequest struct {
 	IsTestable        bool           `json:"is_testable"`
 	Tags              []string       `json:"tags"`
 	Metadata          map[string]any `json:"metadata"`
+	Version           int            `json:"version"` // Required on update unless If-Match is sent
 }
 
 // LessonResponse defines the JSON response for lesson data.
@@ -48,6 +49,7 @@ type LessonResponse struct {
 	Tags              []string       `json:"tags"`
 	Metadata          map[string]any `json:"metadata"`
 	Version           int            `json:"version"`
+	Status            string         `json:"status"`
 	DeletedAt         *time.Time     `json:"deleted_at,omitempty"`
 	CreatedAt         time.Time      `json:"created_at"`
 	UpdatedAt         time.Time      `json:"updated_at"`
@@ -97,6 +99,7 @@ func FromLessonModel(l model.Lesson) LessonResponse {
 		Tags:              l.Tags,
 		Metadata:          l.Metadata,
 		Version:           l.Version,
+		Status:            string(l.Status),
 		DeletedAt:         l.DeletedAt,
 		CreatedAt:         l.CreatedAt,
 		UpdatedAt:         l.UpdatedAt,
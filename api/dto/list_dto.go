@@ -0,0 +1,8 @@
+package dto
+
+// ListResponse is the envelope shared by paginated list endpoints. NextCursor
+// is null on the last page; pass it back as ?cursor= to fetch the next one.
+type ListResponse[T any] struct {
+	Items      []T     `json:"items"`
+	NextCursor *string `json:"next_cursor"`
+}
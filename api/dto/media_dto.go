@@ -0,0 +1,96 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+)
+
+// MediaResponse defines the JSON returned for a media asset. URL is what
+// exercises and options put in their media_url.
+type MediaResponse struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Checksum    string    `json:"checksum"`
+	DurationMS  *int      `json:"duration_ms,omitempty"`
+	UploadedBy  *string   `json:"uploaded_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	Audio *AudioAnalysisResponse `json:"audio,omitempty"`
+
+	// Width, Height and Variants are set for images
+	Width    *int                   `json:"width,omitempty"`
+	Height   *int                   `json:"height,omitempty"`
+	Variants []MediaVariantResponse `json:"variants,omitempty"`
+}
+
+// MediaVariantResponse defines the JSON for a resized copy of an image.
+type MediaVariantResponse struct {
+	Name   string `json:"name"`
+	Format string `json:"format"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Size   int64  `json:"size"`
+	URL    string `json:"url"`
+}
+
+// AudioAnalysisResponse defines the JSON for the analysis of an audio
+// asset. Levels are in dBFS; the waveform has up to 200 peak levels
+// between 0 and 1.
+type AudioAnalysisResponse struct {
+	SampleRate    int       `json:"sample_rate"`
+	Channels      int       `json:"channels"`
+	PeakDB        float64   `json:"peak_db"`
+	RMSDB         float64   `json:"rms_db"`
+	Waveform      []float64 `json:"waveform"`
+	LoudnessFlags []string  `json:"loudness_flags"`
+}
+
+// FromMediaModel maps model.MediaAsset to MediaResponse.
+func FromMediaModel(a model.MediaAsset) MediaResponse {
+	res := MediaResponse{
+		ID:          a.ID.String(),
+		URL:         a.URL,
+		FileName:    a.FileName,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		Checksum:    a.Checksum,
+		DurationMS:  a.DurationMS,
+		UploadedBy:  optionalID(a.UploadedBy),
+		CreatedAt:   a.CreatedAt,
+		Width:       a.Width,
+		Height:      a.Height,
+	}
+	for _, v := range a.Variants {
+		res.Variants = append(res.Variants, MediaVariantResponse{
+			Name:   v.Name,
+			Format: v.Format,
+			Width:  v.Width,
+			Height: v.Height,
+			Size:   v.Size,
+			URL:    v.URL,
+		})
+	}
+	if au := a.Audio; au != nil {
+		res.Audio = &AudioAnalysisResponse{
+			SampleRate:    au.SampleRate,
+			Channels:      au.Channels,
+			PeakDB:        au.PeakDB,
+			RMSDB:         au.RMSDB,
+			Waveform:      nonNil(au.Waveform),
+			LoudnessFlags: nonNil(au.LoudnessFlags),
+		}
+	}
+	return res
+}
+
+// nonNil makes empty lists encode as [] rather than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
@@ -0,0 +1,7 @@
+package dto
+
+// ReorderRequest defines the JSON body for reordering the children of a
+// course, unit, skill or lesson. IDs are listed in their new order.
+type ReorderRequest struct {
+	IDs []string `json:"ids"`
+}
@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/release"
+)
+
+// CreateReleaseRequest defines the JSON body for cutting a release.
+type CreateReleaseRequest struct {
+	Notes string `json:"notes"`
+}
+
+// PromoteReleaseRequest defines the JSON body for promoting a release to a
+// channel, beta or stable.
+type PromoteReleaseRequest struct {
+	Channel string `json:"channel" binding:"required"`
+}
+
+// ReleaseResponse defines the JSON returned for a release. Content is the
+// frozen curriculum and is omitted in listings.
+type ReleaseResponse struct {
+	ID          string                `json:"id"`
+	CourseID    string                `json:"course_id"`
+	Version     int                   `json:"version"`
+	ContentHash string                `json:"content_hash"`
+	Channels    []string              `json:"channels"`
+	Notes       string                `json:"notes,omitempty"`
+	CreatedBy   *string               `json:"created_by,omitempty"`
+	CreatedAt   time.Time             `json:"created_at"`
+	Content     *model.ReleaseContent `json:"content,omitempty"`
+}
+
+// ReleaseDiffResponse defines the JSON returned when comparing two releases.
+type ReleaseDiffResponse struct {
+	From    int              `json:"from"`
+	To      int              `json:"to"`
+	Changes []release.Change `json:"changes"`
+}
+
+// FromReleaseModel maps model.Release to ReleaseResponse.
+func FromReleaseModel(r model.Release) ReleaseResponse {
+	channels := make([]string, 0, len(r.Channels))
+	for _, ch := range r.Channels {
+		channels = append(channels, string(ch))
+	}
+
+	return ReleaseResponse{
+		ID:          r.ID.String(),
+		CourseID:    r.CourseID.String(),
+		Version:     r.Version,
+		ContentHash: r.ContentHash,
+		Channels:    channels,
+		Notes:       r.Notes,
+		CreatedBy:   optionalID(r.CreatedBy),
+		CreatedAt:   r.CreatedAt,
+		Content:     r.Content,
+	}
+}
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/diff"
+	"github.com/bytebeatz/bandroom-cms/core/model"
+)
+
+// RevisionResponse defines the JSON returned for a stored revision.
+type RevisionResponse struct {
+	ID         string         `json:"id"`
+	EntityType string         `json:"entity_type"`
+	EntityID   string         `json:"entity_id"`
+	Version    int            `json:"version"`
+	Snapshot   map[string]any `json:"snapshot,omitempty"` // omitted in listings
+	AuthorID   *string        `json:"author_id,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// RevisionDiffResponse defines the JSON returned when comparing two versions.
+type RevisionDiffResponse struct {
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Changes []diff.Change `json:"changes"`
+}
+
+// FromRevisionModel maps model.Revision to RevisionResponse.
+func FromRevisionModel(r model.Revision) RevisionResponse {
+	var authorID *string
+	if r.AuthorID != nil {
+		id := r.AuthorID.String()
+		authorID = &id
+	}
+
+	return RevisionResponse{
+		ID:         r.ID.String(),
+		EntityType: string(r.EntityType),
+		EntityID:   r.EntityID.String(),
+		Version:    r.Version,
+		Snapshot:   r.Snapshot,
+		AuthorID:   authorID,
+		CreatedAt:  r.CreatedAt,
+	}
+}
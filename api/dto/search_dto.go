@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"strings"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+)
+
+// BreadcrumbResponse defines one ancestor of a search hit.
+type BreadcrumbResponse struct {
+	Type  string `json:"type"`
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// SearchHitResponse defines the JSON returned for each search hit.
+type SearchHitResponse struct {
+	Type        string               `json:"type"`
+	ID          string               `json:"id"`
+	Title       string               `json:"title"`
+	Highlight   string               `json:"highlight"`
+	Rank        float64              `json:"rank"`
+	Path        string               `json:"path"` // e.g. "Guitar › Chords › Triads"
+	Breadcrumbs []BreadcrumbResponse `json:"breadcrumbs"`
+}
+
+// FromSearchHitModel maps model.SearchHit to SearchHitResponse.
+func FromSearchHitModel(h model.SearchHit) SearchHitResponse {
+	crumbs := make([]BreadcrumbResponse, 0, len(h.Breadcrumbs))
+	titles := make([]string, 0, len(h.Breadcrumbs))
+	for _, b := range h.Breadcrumbs {
+		crumbs = append(crumbs, BreadcrumbResponse{
+			Type:  string(b.EntityType),
+			ID:    b.ID.String(),
+			Title: b.Title,
+		})
+		titles = append(titles, b.Title)
+	}
+
+	return SearchHitResponse{
+		Type:        string(h.EntityType),
+		ID:          h.ID.String(),
+		Title:       h.Title,
+		Highlight:   h.Highlight,
+		Rank:        h.Rank,
+		Path:        strings.Join(titles, " › "),
+		Breadcrumbs: crumbs,
+	}
+}
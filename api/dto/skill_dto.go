@@ -7,10 +7,12 @@ import (
 	"github.com/bytebeatz/bandroom-cms/utils"
 )
 
-// SkillRequest defines the incoming JSON payload for creating or updating a skill.
+// SkillRequest defines the incoming JSON payload for creating or updating a
+// skill. Updates ignore course_id and unit_id; skills are moved through
+// PUT /api/skills/unit/:unitId/order.
 type SkillRequest struct {
-	CourseID             string         `json:"course_id"`              // Required
-	UnitID               string         `json:"unit_id"`                // Required
+	CourseID             string         `json:"course_id"`              // Required on create
+	UnitID               string         `json:"unit_id"`                // Required on create
 	Title                string         `json:"title"`                  // Required
 	Icon                 string         `json:"icon"`                   // Optional (default: 🎯)
 	OrderIndex           int            `json:"order_index"`            // Required
@@ -21,6 +23,7 @@ type SkillRequest struct {
 	PrerequisiteSkillIDs []string       `json:"prerequisite_skill_ids"` // Optional
 	Tags                 []string       `json:"tags"`                   // Optional
 	Metadata             map[string]any `json:"metadata"`               // Optional
+	Version              int            `json:"version"`                // Required on update unless If-Match is sent
 }
 
 // SkillResponse defines the JSON response sent back to the client.
@@ -41,6 +44,7 @@ type SkillResponse struct {
 	Tags                 []string       `json:"tags"`
 	Metadata             map[string]any `json:"metadata"`
 	Version              int            `json:"version"`
+	Status               string         `json:"status"`
 	DeletedAt            *time.Time     `json:"deleted_at,omitempty"`
 	CreatedAt            time.Time      `json:"created_at"`
 	UpdatedAt            time.Time      `json:"updated_at"`
@@ -88,6 +92,7 @@ func FromSkillModel(s model.Skill) SkillResponse {
 		Tags:                 s.Tags,
 		Metadata:             s.Metadata,
 		Version:              s.Version,
+		Status:               string(s.Status),
 		DeletedAt:            s.DeletedAt,
 		CreatedAt:            s.CreatedAt,
 		UpdatedAt:            s.UpdatedAt,
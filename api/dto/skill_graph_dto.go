@@ -0,0 +1,76 @@
+package dto
+
+import (
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/google/uuid"
+)
+
+// SkillGraphResponse defines the JSON returned by GET /api/courses/:id/skill-graph.
+type SkillGraphResponse struct {
+	CourseID    string                   `json:"course_id"`
+	Nodes       []SkillGraphNodeResponse `json:"nodes"`
+	Edges       []SkillGraphEdgeResponse `json:"edges"`
+	UnlockOrder []string                 `json:"unlock_order"`
+	Unreachable []string                 `json:"unreachable"`
+	Orphaned    []string                 `json:"orphaned"`
+}
+
+// SkillGraphNodeResponse describes one skill; depth is null when the skill
+// is unreachable.
+type SkillGraphNodeResponse struct {
+	ID                     string   `json:"id"`
+	UnitID                 string   `json:"unit_id"`
+	Title                  string   `json:"title"`
+	Slug                   string   `json:"slug"`
+	Depth                  *int     `json:"depth"`
+	Reachable              bool     `json:"reachable"`
+	Orphaned               bool     `json:"orphaned"`
+	MissingPrerequisiteIDs []string `json:"missing_prerequisite_ids,omitempty"`
+}
+
+// SkillGraphEdgeResponse points from a prerequisite to the skill it unlocks.
+type SkillGraphEdgeResponse struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// FromSkillGraphModel maps model.SkillGraph to SkillGraphResponse.
+func FromSkillGraphModel(g model.SkillGraph) SkillGraphResponse {
+	res := SkillGraphResponse{
+		CourseID:    g.CourseID.String(),
+		Nodes:       make([]SkillGraphNodeResponse, 0, len(g.Nodes)),
+		Edges:       make([]SkillGraphEdgeResponse, 0, len(g.Edges)),
+		UnlockOrder: idStrings(g.UnlockOrder),
+		Unreachable: idStrings(g.Unreachable),
+		Orphaned:    idStrings(g.Orphaned),
+	}
+	for _, n := range g.Nodes {
+		res.Nodes = append(res.Nodes, SkillGraphNodeResponse{
+			ID:                     n.Skill.ID.String(),
+			UnitID:                 n.Skill.UnitID.String(),
+			Title:                  n.Skill.Title,
+			Slug:                   n.Skill.Slug,
+			Depth:                  n.Depth,
+			Reachable:              n.Depth != nil,
+			Orphaned:               n.Orphaned,
+			MissingPrerequisiteIDs: idStrings(n.MissingPrerequisiteIDs),
+		})
+	}
+	for _, e := range g.Edges {
+		res.Edges = append(res.Edges, SkillGraphEdgeResponse{
+			From: e.From.String(),
+			To:   e.To.String(),
+		})
+	}
+	return res
+}
+
+// idStrings is like utils.StringifyUUIDs but never returns nil, so empty
+// lists encode as [] rather than null.
+func idStrings(ids []uuid.UUID) []string {
+	s := make([]string, 0, len(ids))
+	for _, id := range ids {
+		s = append(s, id.String())
+	}
+	return s
+}
@@ -0,0 +1,96 @@
+package dto
+
+import "github.com/bytebeatz/bandroom-cms/core/model"
+
+// TreeStatsResponse defines the aggregate counts of a tree node. Counts of
+// levels that cannot appear beneath the node are omitted.
+type TreeStatsResponse struct {
+	Units             *int `json:"units,omitempty"`
+	Skills            *int `json:"skills,omitempty"`
+	Lessons           *int `json:"lessons,omitempty"`
+	Exercises         int  `json:"exercises"`
+	EstimatedDuration int  `json:"estimated_duration"`
+}
+
+// CourseTreeResponse defines the JSON returned by GET /api/courses/:id/tree.
+type CourseTreeResponse struct {
+	CourseResponse
+	Stats TreeStatsResponse  `json:"stats"`
+	Units []UnitNodeResponse `json:"units"`
+}
+
+type UnitNodeResponse struct {
+	UnitResponse
+	Stats  TreeStatsResponse   `json:"stats"`
+	Skills []SkillNodeResponse `json:"skills,omitempty"`
+}
+
+type SkillNodeResponse struct {
+	SkillResponse
+	Stats   TreeStatsResponse    `json:"stats"`
+	Lessons []LessonNodeResponse `json:"lessons,omitempty"`
+}
+
+type LessonNodeResponse struct {
+	LessonResponse
+	Stats     TreeStatsResponse  `json:"stats"`
+	Exercises []ExerciseResponse `json:"exercises,omitempty"`
+}
+
+// FromCourseTreeModel maps model.CourseTree to CourseTreeResponse.
+func FromCourseTreeModel(t model.CourseTree) CourseTreeResponse {
+	res := CourseTreeResponse{
+		CourseResponse: FromModel(*t.Course),
+		Stats: TreeStatsResponse{
+			Units:             intPtr(t.Stats.Units),
+			Skills:            intPtr(t.Stats.Skills),
+			Lessons:           intPtr(t.Stats.Lessons),
+			Exercises:         t.Stats.Exercises,
+			EstimatedDuration: t.Stats.EstimatedDuration,
+		},
+		Units: make([]UnitNodeResponse, 0, len(t.Units)),
+	}
+
+	for _, u := range t.Units {
+		unit := UnitNodeResponse{
+			UnitResponse: FromUnitModel(*u.Unit),
+			Stats: TreeStatsResponse{
+				Skills:            intPtr(u.Stats.Skills),
+				Lessons:           intPtr(u.Stats.Lessons),
+				Exercises:         u.Stats.Exercises,
+				EstimatedDuration: u.Stats.EstimatedDuration,
+			},
+		}
+		for _, s := range u.Skills {
+			skill := SkillNodeResponse{
+				SkillResponse: FromSkillModel(*s.Skill),
+				Stats: TreeStatsResponse{
+					Lessons:           intPtr(s.Stats.Lessons),
+					Exercises:         s.Stats.Exercises,
+					EstimatedDuration: s.Stats.EstimatedDuration,
+				},
+			}
+			for _, l := range s.Lessons {
+				lesson := LessonNodeResponse{
+					LessonResponse: FromLessonModel(*l.Lesson),
+					Stats: TreeStatsResponse{
+						Exercises:         l.Stats.Exercises,
+						EstimatedDuration: l.Stats.EstimatedDuration,
+					},
+				}
+				for _, e := range l.Exercises {
+					lesson.Exercises = append(lesson.Exercises, FromExerciseModel(*e))
+				}
+				skill.Lessons = append(skill.Lessons, lesson)
+			}
+			unit.Skills = append(unit.Skills, skill)
+		}
+		res.Units = append(res.Units, unit)
+	}
+
+	return res
+}
+
+func intPtr(n int) *int {
+	return &n
+}
@@ -13,18 +13,21 @@ type UnitRequest struct {
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	OrderIndex  int    `json:"order_index"`
+	Version     int    `json:"version"` // Required on update unless If-Match is sent
 }
 
 // UnitResponse defines the JSON returned by unit endpoints.
 type UnitResponse struct {
-	ID          string    `json:"id"`
-	CourseID    string    `json:"course_id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	OrderIndex  int       `json:"order_index"`
-	Version     int       `json:"version"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string     `json:"id"`
+	CourseID    string     `json:"course_id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	OrderIndex  int        `json:"order_index"`
+	Version     int        `json:"version"`
+	Status      string     `json:"status"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
 }
 
 // ToModel converts UnitRequest to model.Unit.
@@ -48,8 +51,9 @@ func FromUnitModel(u model.Unit) UnitResponse {
 		Description: u.Description,
 		OrderIndex:  u.OrderIndex,
 		Version:     u.Version,
+		Status:      string(u.Status),
+		DeletedAt:   u.DeletedAt,
 		CreatedAt:   u.CreatedAt,
 		UpdatedAt:   u.UpdatedAt,
 	}
 }
-
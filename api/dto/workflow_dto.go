@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+)
+
+// TransitionRequest defines the JSON body for a workflow transition. Action is
+// one of submit, reject, approve, publish, unpublish, archive or reopen;
+// Cascade also publishes approved descendants.
+type TransitionRequest struct {
+	Action  string `json:"action" binding:"required"`
+	Cascade bool   `json:"cascade"`
+}
+
+// StatusChangeResponse defines one entity's move between workflow states.
+type StatusChangeResponse struct {
+	EntityType string `json:"entity_type"`
+	EntityID   string `json:"entity_id"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+}
+
+// FromStatusChangeModel maps model.StatusChange to StatusChangeResponse.
+func FromStatusChangeModel(ch model.StatusChange) StatusChangeResponse {
+	return StatusChangeResponse{
+		EntityType: string(ch.EntityType),
+		EntityID:   ch.EntityID.String(),
+		From:       string(ch.From),
+		To:         string(ch.To),
+	}
+}
+
+// ScheduleRequest defines the JSON body for scheduling a course. A null or
+// missing time clears that part of the schedule.
+type ScheduleRequest struct {
+	PublishAt   *time.Time `json:"publish_at"`
+	UnpublishAt *time.Time `json:"unpublish_at"`
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/bytebeatz/bandroom-cms/api/dto"
+	"github.com/bytebeatz/bandroom-cms/core/model"
 	"github.com/bytebeatz/bandroom-cms/core/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -57,7 +58,7 @@ func (h *CourseHandler) Create(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, dto.FromModel(course)) // No need to deref
+	writeVersioned(c, http.StatusCreated, course.Version, dto.FromModel(course)) // No need to deref
 }
 
 // GetByID handles GET /api/courses/:id
@@ -68,13 +69,13 @@ func (h *CourseHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	course, err := h.courseService.GetCourseByID(c, id)
+	course, err := h.courseService.GetCourseByID(c.Request.Context(), id, includeDeleted(c))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.FromModel(*course))
+	writeVersioned(c, http.StatusOK, course.Version, dto.FromModel(*course))
 }
 
 // Update handles PUT /api/courses/:id
@@ -93,19 +94,26 @@ func (h *CourseHandler) Update(c *gin.Context) {
 
 	course := req.ToModel()
 	course.ID = id
-	userID := c.GetString("user_id")
-	if userID != "" {
-		if parsed, err := uuid.Parse(userID); err == nil {
-			course.CreatorID = &parsed
-		}
+
+	version, ok := expectedVersion(c, req.Version)
+	if !ok {
+		return
 	}
+	course.Version = version
 
 	if err := h.courseService.UpdateCourse(c.Request.Context(), &course); err != nil {
+		if writeVersionConflict(c, err) || writePublishedEdit(c, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not update course"})
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.FromModel(course))
+	writeVersioned(c, http.StatusOK, course.Version, dto.FromModel(course))
 }
 
 // Delete handles DELETE /api/courses/:id
@@ -117,6 +125,10 @@ func (h *CourseHandler) Delete(c *gin.Context) {
 	}
 
 	if err := h.courseService.DeleteCourse(c.Request.Context(), id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not delete course"})
 		return
 	}
@@ -126,19 +138,44 @@ func (h *CourseHandler) Delete(c *gin.Context) {
 
 // List handles GET /api/courses
 func (h *CourseHandler) List(c *gin.Context) {
-	courses, err := h.courseService.ListCourses(c, false)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not list courses"})
+	q, ok := listQuery(c)
+	if !ok {
 		return
 	}
 
-	var res []dto.CourseResponse
-	for _, course := range courses {
-		res = append(res, dto.FromModel(*course))
+	page, err := h.courseService.ListCourses(c.Request.Context(), q)
+	if err != nil {
+		if writeQueryError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not list courses"})
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"courses": res,
+	writePage(c, page, func(v *model.Course) dto.CourseResponse {
+		return dto.FromModel(*v)
 	})
 }
 
+// Restore handles POST /api/courses/:id/restore
+func (h *CourseHandler) Restore(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	course, err := h.courseService.RestoreCourse(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+		default:
+			log.Println("Failed to restore course:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not restore course"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromModel(*course))
+}
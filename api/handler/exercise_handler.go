@@ -0,0 +1,344 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/bytebeatz/bandroom-cms/api/dto"
+	"github.com/bytebeatz/bandroom-cms/core/exercisecsv"
+	"github.com/bytebeatz/bandroom-cms/core/grading"
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/bytebeatz/bandroom-cms/core/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ExerciseHandler defines HTTP handlers for exercise operations.
+type ExerciseHandler struct {
+	exerciseService *service.ExerciseService
+	mediaService    *service.MediaService
+}
+
+// NewExerciseHandler initializes a new ExerciseHandler. The media service
+// resolves media URLs to image variants and signed URLs.
+func NewExerciseHandler(
+	svc *service.ExerciseService,
+	media *service.MediaService,
+) *ExerciseHandler {
+	return &ExerciseHandler{exerciseService: svc, mediaService: media}
+}
+
+// Create handles POST /api/exercises
+func (h *ExerciseHandler) Create(c *gin.Context) {
+	var req dto.ExerciseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Println("Failed to bind exercise JSON:", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	exercise := req.ToModel()
+	if exercise.LessonID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
+		return
+	}
+
+	err := h.exerciseService.CreateExercise(c.Request.Context(), &exercise, exercise.LessonID)
+	if err != nil {
+		if writeValidationError(c, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "lesson not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+			return
+		}
+		log.Println("Failed to create exercise:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create exercise"})
+		return
+	}
+
+	writeVersioned(c, http.StatusCreated, exercise.Version, dto.FromExerciseModel(exercise))
+}
+
+// GetByID handles GET /api/exercises/:id
+func (h *ExerciseHandler) GetByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exercise ID"})
+		return
+	}
+
+	exercise, err := h.exerciseService.GetExerciseByID(c.Request.Context(), id, includeDeleted(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Exercise not found"})
+		return
+	}
+
+	res := dto.FromExerciseModel(*exercise)
+	if !resolveMedia(c, h.mediaService, exerciseMedia(&res)) {
+		return
+	}
+	writeVersioned(c, http.StatusOK, exercise.Version, res)
+}
+
+// List handles GET /api/exercises?lesson_id=... or ?skill_id=...
+func (h *ExerciseHandler) List(c *gin.Context) {
+	var (
+		exercises []*model.Exercise
+		err       error
+	)
+
+	switch {
+	case c.Query("lesson_id") != "":
+		lessonID, parseErr := uuid.Parse(c.Query("lesson_id"))
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
+			return
+		}
+		exercises, err = h.exerciseService.ListExercisesByLessonID(
+			c.Request.Context(),
+			lessonID,
+			includeDeleted(c),
+		)
+	case c.Query("skill_id") != "":
+		skillID, parseErr := uuid.Parse(c.Query("skill_id"))
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid skill ID"})
+			return
+		}
+		exercises, err = h.exerciseService.ListExercisesBySkillID(
+			c.Request.Context(),
+			skillID,
+			includeDeleted(c),
+		)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing lesson_id or skill_id"})
+		return
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not list exercises"})
+		return
+	}
+
+	var res []dto.ExerciseResponse
+	for _, e := range exercises {
+		res = append(res, dto.FromExerciseModel(*e))
+	}
+	fields := make([]mediaFields, len(res))
+	for i := range res {
+		fields[i] = exerciseMedia(&res[i])
+	}
+	if !resolveMedia(c, h.mediaService, fields...) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"exercises": res})
+}
+
+// Update handles PUT /api/exercises/:id
+func (h *ExerciseHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exercise ID"})
+		return
+	}
+
+	var req dto.ExerciseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return
+	}
+
+	exercise := req.ToModel()
+	exercise.ID = id
+
+	version, ok := expectedVersion(c, req.Version)
+	if !ok {
+		return
+	}
+	exercise.Version = version
+
+	if err := h.exerciseService.UpdateExercise(c.Request.Context(), &exercise); err != nil {
+		if writeVersionConflict(c, err) || writePublishedEdit(c, err) {
+			return
+		}
+		if writeValidationError(c, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Exercise not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not update exercise"})
+		return
+	}
+
+	writeVersioned(c, http.StatusOK, exercise.Version, dto.FromExerciseModel(exercise))
+}
+
+// Delete handles DELETE /api/exercises/:id
+func (h *ExerciseHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exercise ID"})
+		return
+	}
+
+	if err := h.exerciseService.DeleteExercise(c.Request.Context(), id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Exercise not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not delete exercise"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Grade handles POST /api/exercises/:id/grade
+func (h *ExerciseHandler) Grade(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exercise ID"})
+		return
+	}
+
+	var req dto.GradeExerciseRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Response == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	result, err := h.exerciseService.GradeResponse(c.Request.Context(), id, req.Response)
+	if err != nil {
+		switch {
+		case errors.Is(err, grading.ErrInvalidResponse):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, grading.ErrNoAnswerKey):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "exercise not found"):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Exercise not found"})
+		default:
+			log.Println("Failed to grade response:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not grade response"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Restore handles POST /api/exercises/:id/restore
+func (h *ExerciseHandler) Restore(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exercise ID"})
+		return
+	}
+
+	exercise, err := h.exerciseService.RestoreExercise(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrParentDeleted):
+			c.JSON(
+				http.StatusConflict,
+				gin.H{"error": "Restore the parent lesson before restoring this exercise"},
+			)
+		case strings.Contains(err.Error(), "not found"):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Exercise not found"})
+		default:
+			log.Println("Failed to restore exercise:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not restore exercise"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromExerciseModel(*exercise))
+}
+
+// Reorder handles PUT /api/exercises/lesson/:lessonId/order
+func (h *ExerciseHandler) Reorder(c *gin.Context) {
+	lessonID, err := uuid.Parse(c.Param("lessonId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
+		return
+	}
+
+	ids, ok := orderIDs(c)
+	if !ok {
+		return
+	}
+
+	exercises, err := h.exerciseService.ReorderExercises(c.Request.Context(), lessonID, ids)
+	if err != nil {
+		writeReorderError(c, err, "Lesson not found", "Could not reorder exercises")
+		return
+	}
+
+	page := &repository.Page[*model.Exercise]{Items: exercises}
+	writePage(c, page, func(v *model.Exercise) dto.ExerciseResponse {
+		return dto.FromExerciseModel(*v)
+	})
+}
+
+// maxCSVSize caps the size of an uploaded CSV file.
+const maxCSVSize = 10 << 20
+
+// ImportCSV handles POST /api/exercises/lesson/:lessonId/import
+//
+// The CSV is sent as the "file" field of a multipart form or as the raw
+// request body; see package exercisecsv for its columns. Every row is
+// validated first and either all exercises are created or none.
+func (h *ExerciseHandler) ImportCSV(c *gin.Context) {
+	lessonID, err := uuid.Parse(c.Param("lessonId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxCSVSize)
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, _, err := c.Request.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing CSV file in form field \"file\""})
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	rows, err := exercisecsv.Parse(body)
+	if err != nil {
+		var rowErrs exercisecsv.Errors
+		if errors.As(err, &rowErrs) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "CSV import failed", "rows": rowErrs})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Could not read CSV file"})
+		return
+	}
+
+	exercises, err := h.exerciseService.ImportExercises(c.Request.Context(), lessonID, rows)
+	if err != nil {
+		if strings.Contains(err.Error(), "lesson not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+			return
+		}
+		log.Println("Failed to import exercises:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not import exercises"})
+		return
+	}
+
+	res := make([]dto.ExerciseResponse, 0, len(exercises))
+	for _, e := range exercises {
+		res = append(res, dto.FromExerciseModel(*e))
+	}
+	c.JSON(http.StatusCreated, gin.H{"created": len(res), "exercises": res})
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/bytebeatz/bandroom-cms/api/dto"
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ExerciseOptionHandler defines HTTP handlers for options nested under an exercise.
+type ExerciseOptionHandler struct {
+	exerciseService *service.ExerciseService
+	mediaService    *service.MediaService
+}
+
+// NewExerciseOptionHandler initializes a new ExerciseOptionHandler. The media
+// service resolves media URLs to image variants and signed URLs.
+func NewExerciseOptionHandler(
+	svc *service.ExerciseService,
+	media *service.MediaService,
+) *ExerciseOptionHandler {
+	return &ExerciseOptionHandler{exerciseService: svc, mediaService: media}
+}
+
+// List handles GET /api/exercises/:id/options
+func (h *ExerciseOptionHandler) List(c *gin.Context) {
+	exerciseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exercise ID"})
+		return
+	}
+
+	options, err := h.exerciseService.ListOptions(c.Request.Context(), exerciseID)
+	if err != nil {
+		writeOptionError(c, err, "Could not list options")
+		return
+	}
+
+	res := optionResponses(options)
+	fields := make([]mediaFields, len(res))
+	for i := range res {
+		fields[i] = optionMedia(&res[i])
+	}
+	if !resolveMedia(c, h.mediaService, fields...) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"options": res})
+}
+
+// Create handles POST /api/exercises/:id/options
+func (h *ExerciseOptionHandler) Create(c *gin.Context) {
+	exerciseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exercise ID"})
+		return
+	}
+
+	var req dto.ExerciseOptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Println("Failed to bind option JSON:", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	option := req.ToModel()
+	if err := h.exerciseService.AddOption(c.Request.Context(), exerciseID, &option); err != nil {
+		writeOptionError(c, err, "Could not create option")
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.FromExerciseOptionModel(option))
+}
+
+// Update handles PUT /api/exercises/:id/options/:optionId
+func (h *ExerciseOptionHandler) Update(c *gin.Context) {
+	exerciseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exercise ID"})
+		return
+	}
+	optionID, err := uuid.Parse(c.Param("optionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid option ID"})
+		return
+	}
+
+	var req dto.ExerciseOptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return
+	}
+
+	option := req.ToModel()
+	option.ID = optionID
+
+	if err := h.exerciseService.UpdateOption(c.Request.Context(), exerciseID, &option); err != nil {
+		writeOptionError(c, err, "Could not update option")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromExerciseOptionModel(option))
+}
+
+// Delete handles DELETE /api/exercises/:id/options/:optionId
+func (h *ExerciseOptionHandler) Delete(c *gin.Context) {
+	exerciseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exercise ID"})
+		return
+	}
+	optionID, err := uuid.Parse(c.Param("optionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid option ID"})
+		return
+	}
+
+	if err := h.exerciseService.DeleteOption(c.Request.Context(), exerciseID, optionID); err != nil {
+		writeOptionError(c, err, "Could not delete option")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Replace handles PUT /api/exercises/:id/options
+func (h *ExerciseOptionHandler) Replace(c *gin.Context) {
+	exerciseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exercise ID"})
+		return
+	}
+
+	var req dto.ReplaceExerciseOptionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return
+	}
+
+	options := req.ToModels()
+	if err := h.exerciseService.ReplaceOptions(c.Request.Context(), exerciseID, options); err != nil {
+		writeOptionError(c, err, "Could not replace options")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"options": optionResponses(options)})
+}
+
+// Reorder handles PUT /api/exercises/:id/options/order
+func (h *ExerciseOptionHandler) Reorder(c *gin.Context) {
+	exerciseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exercise ID"})
+		return
+	}
+
+	var req dto.ReorderExerciseOptionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return
+	}
+
+	ids := make([]uuid.UUID, 0, len(req.OptionIDs))
+	for _, raw := range req.OptionIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid option ID: " + raw})
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	if err := h.exerciseService.ReorderOptions(c.Request.Context(), exerciseID, ids); err != nil {
+		writeOptionError(c, err, "Could not reorder options")
+		return
+	}
+
+	options, err := h.exerciseService.ListOptions(c.Request.Context(), exerciseID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not list options"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"options": optionResponses(options)})
+}
+
+func writeOptionError(c *gin.Context, err error, fallback string) {
+	if writeValidationError(c, err) || writePublishedEdit(c, err) {
+		return
+	}
+
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "exercise not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Exercise not found"})
+	case strings.Contains(msg, "option not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Option not found"})
+	case strings.Contains(msg, "must contain exactly"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	default:
+		log.Println(fallback+":", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+func optionResponses(options []*model.ExerciseOption) []dto.ExerciseOptionResponse {
+	res := make([]dto.ExerciseOptionResponse, 0, len(options))
+	for _, o := range options {
+		res = append(res, dto.FromExerciseOptionModel(*o))
+	}
+	return res
+}
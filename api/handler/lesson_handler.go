@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strings"
 
 	"github.com/bytebeatz/bandroom-cms/api/dto"
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
 	"github.com/bytebeatz/bandroom-cms/core/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -57,7 +60,7 @@ func (h *LessonHandler) Create(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, dto.FromLessonModel(lesson))
+	writeVersioned(c, http.StatusCreated, lesson.Version, dto.FromLessonModel(lesson))
 }
 
 func (h *LessonHandler) GetByID(c *gin.Context) {
@@ -67,34 +70,36 @@ func (h *LessonHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	lesson, err := h.lessonService.GetLessonByID(c, id)
+	lesson, err := h.lessonService.GetLessonByID(c.Request.Context(), id, includeDeleted(c))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.FromLessonModel(*lesson))
+	writeVersioned(c, http.StatusOK, lesson.Version, dto.FromLessonModel(*lesson))
 }
 
-func (h *LessonHandler) ListBySkill(c *gin.Context) {
-	skillID, err := uuid.Parse(c.Query("skill_id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid skill ID"})
+func (h *LessonHandler) List(c *gin.Context) {
+	q, ok := listQuery(c)
+	if !ok {
+		return
+	}
+	if !parentQuery(c, &q, "skill_id") {
 		return
 	}
 
-	lessons, err := h.lessonService.ListLessonsBySkillID(c, skillID)
+	page, err := h.lessonService.ListLessons(c.Request.Context(), q)
 	if err != nil {
+		if writeQueryError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not list lessons"})
 		return
 	}
 
-	var res []dto.LessonResponse
-	for _, l := range lessons {
-		res = append(res, dto.FromLessonModel(*l))
-	}
-
-	c.JSON(http.StatusOK, gin.H{"lessons": res})
+	writePage(c, page, func(v *model.Lesson) dto.LessonResponse {
+		return dto.FromLessonModel(*v)
+	})
 }
 
 func (h *LessonHandler) Update(c *gin.Context) {
@@ -113,12 +118,25 @@ func (h *LessonHandler) Update(c *gin.Context) {
 	lesson := req.ToModel()
 	lesson.ID = id
 
+	version, ok := expectedVersion(c, req.Version)
+	if !ok {
+		return
+	}
+	lesson.Version = version
+
 	if err := h.lessonService.UpdateLesson(c.Request.Context(), &lesson); err != nil {
+		if writeVersionConflict(c, err) || writePublishedEdit(c, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not update lesson"})
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.FromLessonModel(lesson))
+	writeVersioned(c, http.StatusOK, lesson.Version, dto.FromLessonModel(lesson))
 }
 
 func (h *LessonHandler) Delete(c *gin.Context) {
@@ -129,9 +147,64 @@ func (h *LessonHandler) Delete(c *gin.Context) {
 	}
 
 	if err := h.lessonService.DeleteLesson(c.Request.Context(), id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not delete lesson"})
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
+
+func (h *LessonHandler) Restore(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
+		return
+	}
+
+	lesson, err := h.lessonService.RestoreLesson(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrParentDeleted):
+			c.JSON(
+				http.StatusConflict,
+				gin.H{"error": "Restore the parent skill before restoring this lesson"},
+			)
+		case strings.Contains(err.Error(), "not found"):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+		default:
+			log.Println("Failed to restore lesson:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not restore lesson"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromLessonModel(*lesson))
+}
+
+func (h *LessonHandler) Reorder(c *gin.Context) {
+	skillID, err := uuid.Parse(c.Param("skillId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid skill ID"})
+		return
+	}
+
+	ids, ok := orderIDs(c)
+	if !ok {
+		return
+	}
+
+	lessons, err := h.lessonService.ReorderLessons(c.Request.Context(), skillID, ids)
+	if err != nil {
+		writeReorderError(c, err, "Skill not found", "Could not reorder lessons")
+		return
+	}
+
+	page := &repository.Page[*model.Lesson]{Items: lessons}
+	writePage(c, page, func(v *model.Lesson) dto.LessonResponse {
+		return dto.FromLessonModel(*v)
+	})
+}
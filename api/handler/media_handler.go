@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/api/dto"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/bytebeatz/bandroom-cms/core/service"
+	"github.com/bytebeatz/bandroom-cms/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// MediaHandler defines HTTP handlers for uploaded media files.
+type MediaHandler struct {
+	mediaService *service.MediaService
+}
+
+// NewMediaHandler initializes a new MediaHandler.
+func NewMediaHandler(svc *service.MediaService) *MediaHandler {
+	return &MediaHandler{mediaService: svc}
+}
+
+// Upload handles POST /api/media with the file in the multipart field "file".
+func (h *MediaHandler) Upload(c *gin.Context) {
+	// Leave room for the rest of the form
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.MaxMediaSize+1<<20)
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing file in form field \"file\""})
+		return
+	}
+	defer file.Close()
+
+	asset, err := h.mediaService.UploadMedia(c.Request.Context(), file, header)
+	if err != nil {
+		if writeValidationError(c, err) {
+			return
+		}
+		log.Println("Failed to upload media:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not upload media"})
+		return
+	}
+	c.JSON(http.StatusCreated, dto.FromMediaModel(*asset))
+}
+
+// Get handles GET /api/media/:id
+func (h *MediaHandler) Get(c *gin.Context) {
+	id, ok := mediaID(c)
+	if !ok {
+		return
+	}
+
+	asset, err := h.mediaService.GetMedia(c.Request.Context(), id)
+	if err != nil {
+		writeMediaError(c, err, "Could not get media")
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromMediaModel(*asset))
+}
+
+// Download handles GET /api/media/:id/content
+func (h *MediaHandler) Download(c *gin.Context) {
+	id, ok := mediaID(c)
+	if !ok {
+		return
+	}
+
+	asset, content, err := h.mediaService.OpenMedia(c.Request.Context(), id)
+	if err != nil {
+		writeMediaError(c, err, "Could not download media")
+		return
+	}
+	defer content.Close()
+
+	// Content never changes under an ID, so the checksum makes a strong ETag
+	tag := `"` + asset.Checksum + `"`
+	c.Header("ETag", tag)
+	if c.GetHeader("If-None-Match") == tag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	setContentHeaders(c, asset.ContentType, asset.Size, asset.FileName)
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, content); err != nil {
+		log.Println("Failed to stream media:", err)
+	}
+}
+
+// Delete handles DELETE /api/media/:id
+func (h *MediaHandler) Delete(c *gin.Context) {
+	id, ok := mediaID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.mediaService.DeleteMedia(c.Request.Context(), id); err != nil {
+		writeMediaError(c, err, "Could not delete media")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ServeFile handles GET /files/*path?expires=...&signature=... for storage
+// backends without URLs of their own (local and memory). The URL must come
+// from a signed media URL.
+func (h *MediaHandler) ServeFile(c *gin.Context) {
+	filePath := strings.TrimPrefix(c.Param("path"), "/")
+	obj, content, err := h.mediaService.OpenFile(
+		c.Request.Context(),
+		filePath,
+		c.Query("expires"),
+		c.Query("signature"),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrInvalidSignature):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid or expired signature"})
+		case strings.Contains(err.Error(), "not found"):
+			c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		default:
+			log.Println("Failed to open file:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not open file"})
+		}
+		return
+	}
+	defer content.Close()
+
+	setContentHeaders(c, obj.ContentType, obj.Size, path.Base(filePath))
+	c.Header("Last-Modified", obj.Updated.UTC().Format(http.TimeFormat))
+	c.Header("Cache-Control", "private, max-age=86400")
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, content); err != nil {
+		log.Println("Failed to stream file:", err)
+	}
+}
+
+// inlineTypes are the content types browsers may show in the page. Anything
+// else, SVG included, could run script on the API's origin and is sent as
+// an attachment.
+var inlineTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// setContentHeaders sets the headers for streaming a stored file. Browsers
+// must not guess another type than the one recorded.
+func setContentHeaders(c *gin.Context, contentType string, size int64, fileName string) {
+	disposition := "attachment"
+	if inlineTypes[contentType] || strings.HasPrefix(contentType, "audio/") {
+		disposition = "inline"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Length", strconv.FormatInt(size, 10))
+	c.Header("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, fileName))
+	c.Header("X-Content-Type-Options", "nosniff")
+}
+
+// wantsSignedURLs reports whether ?signed_urls=true asked for stored media
+// URLs in exercise and option responses to be replaced by signed ones.
+func wantsSignedURLs(c *gin.Context) bool {
+	signed, _ := strconv.ParseBool(c.Query("signed_urls"))
+	return signed
+}
+
+// mediaFields points at the media fields of an exercise or option
+// response.
+type mediaFields struct {
+	url       **string
+	expiresAt **time.Time
+	srcset    *map[string]string
+}
+
+func exerciseMedia(r *dto.ExerciseResponse) mediaFields {
+	return mediaFields{&r.MediaURL, &r.MediaURLExpiresAt, &r.MediaSrcset}
+}
+
+func optionMedia(r *dto.ExerciseOptionResponse) mediaFields {
+	return mediaFields{&r.MediaURL, &r.MediaURLExpiresAt, &r.MediaSrcset}
+}
+
+// resolveMedia fills in the srcsets of image variants and, for
+// ?signed_urls=true, replaces stored media URLs with signed ones. It
+// answers 500 and returns false if that fails.
+func resolveMedia(c *gin.Context, media *service.MediaService, fields ...mediaFields) bool {
+	var urls []string
+	for _, f := range fields {
+		if *f.url != nil {
+			urls = append(urls, **f.url)
+		}
+	}
+	resolved, err := media.ResolveURLs(c.Request.Context(), urls, wantsSignedURLs(c))
+	if err != nil {
+		log.Println("Failed to resolve media URLs:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not resolve media URLs"})
+		return false
+	}
+	for _, f := range fields {
+		if *f.url == nil {
+			continue
+		}
+		if r, ok := resolved[**f.url]; ok {
+			*f.url, *f.expiresAt, *f.srcset = &r.URL, r.ExpiresAt, r.Srcset
+		}
+	}
+	return true
+}
+
+func mediaID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid media ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func writeMediaError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrMediaInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": "Media is used by an exercise, option or release"})
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+	default:
+		log.Println(fallback+":", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/bytebeatz/bandroom-cms/api/dto"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// orderIDs reads the ID list of a reorder request. It writes a 400 and
+// returns false when the body is malformed.
+func orderIDs(c *gin.Context) ([]uuid.UUID, bool) {
+	var req dto.ReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return nil, false
+	}
+
+	ids := make([]uuid.UUID, 0, len(req.IDs))
+	for _, raw := range req.IDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID: " + raw})
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	return ids, true
+}
+
+// writeReorderError answers a failed reorder: 400 when the list does not
+// match the children, 409 when one of them changed meanwhile, 404 when the
+// parent is gone.
+func writeReorderError(c *gin.Context, err error, notFound, fallback string) {
+	if writeValidationError(c, err) || writeVersionConflict(c, err) {
+		return
+	}
+	if strings.Contains(err.Error(), "not found") {
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+		return
+	}
+	log.Println(fallback+":", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/bytebeatz/bandroom-cms/core/service"
+	"github.com/gin-gonic/gin"
+)
+
+// PackHandler defines HTTP handlers for offline course packs.
+type PackHandler struct {
+	packService *service.PackService
+}
+
+// NewPackHandler initializes a new PackHandler.
+func NewPackHandler(svc *service.PackService) *PackHandler {
+	return &PackHandler{packService: svc}
+}
+
+// GetPack handles GET /api/courses/:id/pack?version=&since=
+//
+// version is a release number or channel and defaults to the latest
+// release; since makes a delta pack on top of an earlier release.
+func (h *PackHandler) GetPack(c *gin.Context) {
+	since := 0
+	if raw := c.Query("since"); raw != "" {
+		var ok bool
+		if since, ok = versionParam(c, raw); !ok {
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	rel, base, err := h.packService.GetPackReleases(ctx, c.Param("id"), c.Query("version"), since)
+	if err != nil {
+		writeReleaseError(c, err, "Could not build pack")
+		return
+	}
+
+	name := rel.Content.Slug
+	if name == "" {
+		name = rel.CourseID.String()
+	}
+	if base != nil {
+		name += "-v" + strconv.Itoa(base.Version)
+	}
+	name += "-v" + strconv.Itoa(rel.Version) + ".zip"
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	c.Status(http.StatusOK)
+
+	// The zip is streamed, so a failure part way can only cut it short
+	if err := h.packService.WritePack(ctx, c.Writer, rel, base); err != nil {
+		log.Println("Failed to stream pack:", err)
+	}
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bytebeatz/bandroom-cms/api/dto"
+	"github.com/bytebeatz/bandroom-cms/core/portable"
+	"github.com/bytebeatz/bandroom-cms/core/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PortableHandler defines HTTP handlers for exporting and importing whole
+// courses as documents.
+type PortableHandler struct {
+	portableService *service.PortableService
+}
+
+// NewPortableHandler initializes a new PortableHandler.
+func NewPortableHandler(svc *service.PortableService) *PortableHandler {
+	return &PortableHandler{portableService: svc}
+}
+
+// Export handles GET /api/courses/:id/export?format=json|yaml
+func (h *PortableHandler) Export(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+	format, err := portable.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	doc, err := h.portableService.Export(c.Request.Context(), id)
+	if err != nil {
+		writeImportError(c, err, "Could not export course")
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := portable.Encode(&buf, doc, format); err != nil {
+		log.Println("Failed to encode course export:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not export course"})
+		return
+	}
+
+	contentType := "application/json; charset=utf-8"
+	if format == portable.FormatYAML {
+		contentType = "application/yaml; charset=utf-8"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", doc.Slug+"."+string(format)))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// Import handles POST /api/courses/import?dry_run=true&format=json|yaml
+//
+// The format defaults to YAML when the Content-Type says so and to JSON
+// otherwise. With dry_run the changes are reported but not written.
+func (h *PortableHandler) Import(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be true or false"})
+		return
+	}
+
+	rawFormat := c.Query("format")
+	if rawFormat == "" && strings.Contains(c.ContentType(), "yaml") {
+		rawFormat = "yaml"
+	}
+	format, err := portable.ParseFormat(rawFormat)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	doc, err := portable.Decode(c.Request.Body, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := h.portableService.Import(c.Request.Context(), doc, dryRun)
+	if err != nil {
+		writeImportError(c, err, "Could not import course")
+		return
+	}
+
+	status := http.StatusOK
+	if !dryRun && len(plan.Changes) > 0 && plan.Created[plan.CourseID] {
+		status = http.StatusCreated
+	}
+	c.JSON(status, dto.FromImportPlan(*plan, dryRun))
+}
+
+func writeImportError(c *gin.Context, err error, fallback string) {
+	if writeValidationError(c, err) || writeVersionConflict(c, err) || writePublishedEdit(c, err) {
+		return
+	}
+
+	switch {
+	case strings.Contains(err.Error(), "already exists"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Course or content not found"})
+	default:
+		log.Println(fallback+":", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
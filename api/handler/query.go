@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bytebeatz/bandroom-cms/api/dto"
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// includeDeleted reports whether the request asked for soft-deleted rows via
+// ?include_deleted=true. Only admins may see deleted content.
+func includeDeleted(c *gin.Context) bool {
+	return c.Query("include_deleted") == "true" && c.GetString("role") == "admin"
+}
+
+// listQuery reads the pagination, sort and filter parameters shared by list
+// endpoints:
+//
+//	?limit=20&cursor=<next_cursor>&sort=title&order=desc
+//	&language=en&difficulty=2&is_published=true&tags=rhythm,jazz&creator_id=<uuid>
+//	&status=in_review
+//
+// It writes a 400 and returns false when a parameter is malformed. Whether a
+// filter applies to the entity is checked by the repository.
+func listQuery(c *gin.Context) (repository.ListQuery, bool) {
+	q := repository.ListQuery{
+		IncludeDeleted: includeDeleted(c),
+		Language:       c.Query("language"),
+		Sort:           c.Query("sort"),
+	}
+	bad := func(msg string) (repository.ListQuery, bool) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return repository.ListQuery{}, false
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > repository.MaxPageSize {
+			return bad("limit must be between 1 and " + strconv.Itoa(repository.MaxPageSize))
+		}
+		q.Limit = limit
+	}
+
+	switch c.DefaultQuery("order", "asc") {
+	case "asc":
+	case "desc":
+		q.Desc = true
+	default:
+		return bad("order must be asc or desc")
+	}
+
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := repository.DecodeCursor(raw)
+		if err != nil {
+			return bad("Invalid cursor")
+		}
+		q.After = cursor
+	}
+
+	if raw := c.Query("difficulty"); raw != "" {
+		difficulty, err := strconv.Atoi(raw)
+		if err != nil {
+			return bad("Invalid difficulty")
+		}
+		q.Difficulty = &difficulty
+	}
+
+	if raw := c.Query("is_published"); raw != "" {
+		published, err := strconv.ParseBool(raw)
+		if err != nil {
+			return bad("Invalid is_published")
+		}
+		q.IsPublished = &published
+	}
+
+	for _, raw := range c.QueryArray("tags") {
+		for _, tag := range strings.Split(raw, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				q.Tags = append(q.Tags, tag)
+			}
+		}
+	}
+
+	if raw := c.Query("creator_id"); raw != "" {
+		creatorID, err := uuid.Parse(raw)
+		if err != nil {
+			return bad("Invalid creator_id")
+		}
+		q.CreatorID = &creatorID
+	}
+
+	switch status := model.WorkflowState(c.Query("status")); status {
+	case "", model.StateDraft, model.StateInReview, model.StateApproved,
+		model.StatePublished, model.StateArchived:
+		q.Status = status
+	default:
+		return bad("Invalid status")
+	}
+
+	return q, true
+}
+
+// parentQuery sets q.ParentID from an optional UUID query parameter.
+func parentQuery(c *gin.Context, q *repository.ListQuery, param string) bool {
+	raw := c.Query(param)
+	if raw == "" {
+		return true
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param})
+		return false
+	}
+	q.ParentID = &id
+	return true
+}
+
+// writePage writes one page of results in the shared list envelope.
+func writePage[M any, R any](c *gin.Context, page *repository.Page[M], toResponse func(M) R) {
+	res := dto.ListResponse[R]{Items: make([]R, 0, len(page.Items))}
+	for _, item := range page.Items {
+		res.Items = append(res.Items, toResponse(item))
+	}
+	if page.NextCursor != "" {
+		res.NextCursor = &page.NextCursor
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// writeQueryError answers 400 for unsupported sorts, filters, cursors or
+// search terms and reports whether it did.
+func writeQueryError(c *gin.Context, err error) bool {
+	if !errors.Is(err, repository.ErrInvalidQuery) {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	return true
+}
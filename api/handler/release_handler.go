@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/bytebeatz/bandroom-cms/api/dto"
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/release"
+	"github.com/bytebeatz/bandroom-cms/core/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ReleaseHandler defines HTTP handlers for course releases. Courses are
+// addressed by ID or slug.
+type ReleaseHandler struct {
+	releaseService *service.ReleaseService
+}
+
+// NewReleaseHandler initializes a new ReleaseHandler.
+func NewReleaseHandler(svc *service.ReleaseService) *ReleaseHandler {
+	return &ReleaseHandler{releaseService: svc}
+}
+
+// Create handles POST /api/releases/:course
+func (h *ReleaseHandler) Create(c *gin.Context) {
+	// The body is optional
+	var req dto.CreateReleaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	rel, err := h.releaseService.CreateRelease(c.Request.Context(), c.Param("course"), req.Notes)
+	if err != nil {
+		writeReleaseError(c, err, "Could not create release")
+		return
+	}
+	c.JSON(http.StatusCreated, dto.FromReleaseModel(*rel))
+}
+
+// List handles GET /api/releases/:course
+func (h *ReleaseHandler) List(c *gin.Context) {
+	releases, err := h.releaseService.ListReleases(c.Request.Context(), c.Param("course"))
+	if err != nil {
+		writeReleaseError(c, err, "Could not list releases")
+		return
+	}
+
+	res := make([]dto.ReleaseResponse, 0, len(releases))
+	for _, rel := range releases {
+		res = append(res, dto.FromReleaseModel(*rel))
+	}
+	c.JSON(http.StatusOK, gin.H{"releases": res})
+}
+
+// Get handles GET /api/releases/:course/:version, where version is a
+// release number or a channel name (beta, stable).
+func (h *ReleaseHandler) Get(c *gin.Context) {
+	rel, err := h.releaseService.ResolveRelease(c.Request.Context(), c.Param("course"),
+		c.Param("version"))
+	if err != nil {
+		writeReleaseError(c, err, "Could not get release")
+		return
+	}
+
+	// Content never changes, so the hash makes a strong ETag
+	tag := `"` + rel.ContentHash + `"`
+	c.Header("ETag", tag)
+	if c.GetHeader("If-None-Match") == tag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromReleaseModel(*rel))
+}
+
+// Diff handles GET /api/releases/:course/diff?from=&to=
+func (h *ReleaseHandler) Diff(c *gin.Context) {
+	from, ok := versionParam(c, c.Query("from"))
+	if !ok {
+		return
+	}
+	to, ok := versionParam(c, c.Query("to"))
+	if !ok {
+		return
+	}
+
+	changes, err := h.releaseService.DiffReleases(c.Request.Context(), c.Param("course"), from, to)
+	if err != nil {
+		writeReleaseError(c, err, "Could not diff releases")
+		return
+	}
+	if changes == nil {
+		changes = []release.Change{}
+	}
+
+	c.JSON(http.StatusOK, dto.ReleaseDiffResponse{From: from, To: to, Changes: changes})
+}
+
+// Promote handles POST /api/releases/:course/:version/promote
+func (h *ReleaseHandler) Promote(c *gin.Context) {
+	version, ok := versionParam(c, c.Param("version"))
+	if !ok {
+		return
+	}
+
+	var req dto.PromoteReleaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	rel, err := h.releaseService.Promote(c.Request.Context(), c.Param("course"), version,
+		model.ReleaseChannel(req.Channel))
+	if err != nil {
+		writeReleaseError(c, err, "Could not promote release")
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromReleaseModel(*rel))
+}
+
+func writeReleaseError(c *gin.Context, err error, fallback string) {
+	if writeValidationError(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, release.ErrNotPublished), errors.Is(err, release.ErrUnchanged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Release or course not found"})
+	default:
+		log.Println(fallback+":", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
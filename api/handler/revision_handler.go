@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bytebeatz/bandroom-cms/api/dto"
+	"github.com/bytebeatz/bandroom-cms/core/diff"
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RevisionHandler defines HTTP handlers for the revision history of content
+// entities. Each method returns a handler bound to one entity type, so the
+// same routes can be mounted under every content group.
+type RevisionHandler struct {
+	revisionService *service.RevisionService
+}
+
+// NewRevisionHandler initializes a new RevisionHandler.
+func NewRevisionHandler(svc *service.RevisionService) *RevisionHandler {
+	return &RevisionHandler{revisionService: svc}
+}
+
+// List handles GET /api/<entities>/:id/revisions
+func (h *RevisionHandler) List(entityType model.EntityType) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		revisions, err := h.revisionService.ListRevisions(c.Request.Context(), entityType, id)
+		if err != nil {
+			log.Println("Failed to list revisions:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not list revisions"})
+			return
+		}
+
+		res := make([]dto.RevisionResponse, 0, len(revisions))
+		for _, rev := range revisions {
+			res = append(res, dto.FromRevisionModel(*rev))
+		}
+
+		c.JSON(http.StatusOK, gin.H{"revisions": res})
+	}
+}
+
+// Get handles GET /api/<entities>/:id/revisions/:version
+func (h *RevisionHandler) Get(entityType model.EntityType) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+			return
+		}
+		version, ok := versionParam(c, c.Param("version"))
+		if !ok {
+			return
+		}
+
+		rev, err := h.revisionService.GetRevision(c.Request.Context(), entityType, id, version)
+		if err != nil {
+			writeRevisionError(c, err, "Could not get revision")
+			return
+		}
+
+		c.JSON(http.StatusOK, dto.FromRevisionModel(*rev))
+	}
+}
+
+// Diff handles GET /api/<entities>/:id/revisions/diff?from=&to=
+func (h *RevisionHandler) Diff(entityType model.EntityType) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+			return
+		}
+		from, ok := versionParam(c, c.Query("from"))
+		if !ok {
+			return
+		}
+		to, ok := versionParam(c, c.Query("to"))
+		if !ok {
+			return
+		}
+
+		changes, err := h.revisionService.DiffRevisions(
+			c.Request.Context(), entityType, id, from, to,
+		)
+		if err != nil {
+			writeRevisionError(c, err, "Could not diff revisions")
+			return
+		}
+		if changes == nil {
+			changes = []diff.Change{}
+		}
+
+		c.JSON(http.StatusOK, dto.RevisionDiffResponse{From: from, To: to, Changes: changes})
+	}
+}
+
+// Rollback handles POST /api/<entities>/:id/revisions/:version/rollback.
+// An optional If-Match header guards against overwriting a newer edit.
+func (h *RevisionHandler) Rollback(entityType model.EntityType) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+			return
+		}
+		version, ok := versionParam(c, c.Param("version"))
+		if !ok {
+			return
+		}
+
+		expected := 0
+		if header := c.GetHeader("If-Match"); header != "" {
+			if expected, err = parseETag(header); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid If-Match header"})
+				return
+			}
+		}
+
+		rev, err := h.revisionService.Rollback(
+			c.Request.Context(), entityType, id, version, expected,
+		)
+		if err != nil {
+			if writeValidationError(c, err) || writeVersionConflict(c, err) ||
+				writePublishedEdit(c, err) {
+				return
+			}
+			writeRevisionError(c, err, "Could not roll back")
+			return
+		}
+
+		writeVersioned(c, http.StatusOK, rev.Version, dto.FromRevisionModel(*rev))
+	}
+}
+
+func versionParam(c *gin.Context, raw string) (int, bool) {
+	version, err := strconv.Atoi(raw)
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version"})
+		return 0, false
+	}
+	return version, true
+}
+
+func writeRevisionError(c *gin.Context, err error, fallback string) {
+	if strings.Contains(err.Error(), "not found") {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision or entity not found"})
+		return
+	}
+	log.Println(fallback+":", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bytebeatz/bandroom-cms/api/dto"
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/bytebeatz/bandroom-cms/core/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SearchHandler defines HTTP handlers for curriculum search.
+type SearchHandler struct {
+	searchService *service.SearchService
+}
+
+// NewSearchHandler initializes a new SearchHandler.
+func NewSearchHandler(svc *service.SearchService) *SearchHandler {
+	return &SearchHandler{searchService: svc}
+}
+
+// Search handles GET /api/search?q=...&type=lesson,exercise&course_id=...&limit=20
+func (h *SearchHandler) Search(c *gin.Context) {
+	q := repository.SearchQuery{Text: c.Query("q")}
+
+	for _, raw := range c.QueryArray("type") {
+		for _, t := range strings.Split(raw, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				q.Types = append(q.Types, model.EntityType(t))
+			}
+		}
+	}
+
+	if raw := c.Query("course_id"); raw != "" {
+		courseID, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course_id"})
+			return
+		}
+		q.CourseID = &courseID
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		q.Limit = limit
+	}
+
+	hits, err := h.searchService.Search(c.Request.Context(), q)
+	if err != nil {
+		if writeQueryError(c, err) {
+			return
+		}
+		log.Println("Search failed:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not search"})
+		return
+	}
+
+	res := make([]dto.SearchHitResponse, 0, len(hits))
+	for _, hit := range hits {
+		res = append(res, dto.FromSearchHitModel(*hit))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"query": q.Text, "hits": res})
+}
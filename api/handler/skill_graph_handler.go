@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/bytebeatz/bandroom-cms/api/dto"
+	"github.com/bytebeatz/bandroom-cms/core/service"
+	"github.com/bytebeatz/bandroom-cms/core/skillgraph"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SkillGraphHandler defines HTTP handlers for course skill graphs.
+type SkillGraphHandler struct {
+	skillGraphService *service.SkillGraphService
+}
+
+// NewSkillGraphHandler initializes a new SkillGraphHandler.
+func NewSkillGraphHandler(svc *service.SkillGraphService) *SkillGraphHandler {
+	return &SkillGraphHandler{skillGraphService: svc}
+}
+
+// GetSkillGraph handles GET /api/courses/:id/skill-graph?format=json|dot|mermaid
+func (h *SkillGraphHandler) GetSkillGraph(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	switch format {
+	case "json", "dot", "mermaid":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json, dot or mermaid"})
+		return
+	}
+
+	graph, err := h.skillGraphService.GetSkillGraph(c.Request.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+			return
+		}
+		log.Println("Failed to build skill graph:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not load skill graph"})
+		return
+	}
+
+	switch format {
+	case "dot":
+		c.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(skillgraph.DOT(graph)))
+	case "mermaid":
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(skillgraph.Mermaid(graph)))
+	default:
+		c.JSON(http.StatusOK, dto.FromSkillGraphModel(*graph))
+	}
+}
@@ -1,12 +1,17 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
 
 	"github.com/bytebeatz/bandroom-cms/api/dto"
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
 	"github.com/bytebeatz/bandroom-cms/core/service"
+	"github.com/bytebeatz/bandroom-cms/core/validation"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -29,6 +34,10 @@ func (h *SkillHandler) Create(c *gin.Context) {
 
 	log.Printf("Parsed SkillRequest: %+v\n", req)
 
+	if !checkPrerequisiteIDs(c, req.PrerequisiteSkillIDs) {
+		return
+	}
+
 	userIDStr := c.GetString("user_id")
 	role := c.GetString("role")
 	log.Printf("Authenticated user: %s with role %s\n", userIDStr, role)
@@ -45,6 +54,9 @@ func (h *SkillHandler) Create(c *gin.Context) {
 
 	err = h.skillService.CreateSkill(c.Request.Context(), &skill, skill.CourseID)
 	if err != nil {
+		if writeValidationError(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "already exists") {
 			c.JSON(
 				http.StatusConflict,
@@ -57,7 +69,7 @@ func (h *SkillHandler) Create(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, dto.FromSkillModel(skill))
+	writeVersioned(c, http.StatusCreated, skill.Version, dto.FromSkillModel(skill))
 }
 
 func (h *SkillHandler) GetByID(c *gin.Context) {
@@ -67,35 +79,36 @@ func (h *SkillHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	skill, err := h.skillService.GetSkillByID(c, id)
+	skill, err := h.skillService.GetSkillByID(c.Request.Context(), id, includeDeleted(c))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Skill not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.FromSkillModel(*skill))
+	writeVersioned(c, http.StatusOK, skill.Version, dto.FromSkillModel(*skill))
 }
 
-func (h *SkillHandler) ListByUnit(c *gin.Context) {
-	unitID, err := uuid.Parse(c.Query("unit_id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unit ID"})
+func (h *SkillHandler) List(c *gin.Context) {
+	q, ok := listQuery(c)
+	if !ok {
+		return
+	}
+	if !parentQuery(c, &q, "unit_id") {
 		return
 	}
 
-	skills, err := h.skillService.ListSkillsByUnitID(c, unitID)
+	page, err := h.skillService.ListSkills(c.Request.Context(), q)
 	if err != nil {
+		if writeQueryError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not list skills"})
 		return
 	}
 
-	var res []dto.SkillResponse
-	for _, s := range skills {
-		res = append(res, dto.FromSkillModel(*s))
-	}
-
-	c.JSON(http.StatusOK, res)
-
+	writePage(c, page, func(v *model.Skill) dto.SkillResponse {
+		return dto.FromSkillModel(*v)
+	})
 }
 
 func (h *SkillHandler) Update(c *gin.Context) {
@@ -110,16 +123,33 @@ func (h *SkillHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
 		return
 	}
+	if !checkPrerequisiteIDs(c, req.PrerequisiteSkillIDs) {
+		return
+	}
 
 	skill := req.ToModel()
 	skill.ID = id
 
+	version, ok := expectedVersion(c, req.Version)
+	if !ok {
+		return
+	}
+	skill.Version = version
+
 	if err := h.skillService.UpdateSkill(c.Request.Context(), &skill); err != nil {
+		if writeVersionConflict(c, err) || writePublishedEdit(c, err) ||
+			writeValidationError(c, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Skill not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not update skill"})
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.FromSkillModel(skill))
+	writeVersioned(c, http.StatusOK, skill.Version, dto.FromSkillModel(skill))
 }
 
 func (h *SkillHandler) Delete(c *gin.Context) {
@@ -130,9 +160,76 @@ func (h *SkillHandler) Delete(c *gin.Context) {
 	}
 
 	if err := h.skillService.DeleteSkill(c.Request.Context(), id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Skill not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not delete skill"})
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
+
+func (h *SkillHandler) Restore(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid skill ID"})
+		return
+	}
+
+	skill, err := h.skillService.RestoreSkill(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrParentDeleted):
+			c.JSON(
+				http.StatusConflict,
+				gin.H{"error": "Restore the parent unit before restoring this skill"},
+			)
+		case strings.Contains(err.Error(), "not found"):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Skill not found"})
+		default:
+			log.Println("Failed to restore skill:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not restore skill"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromSkillModel(*skill))
+}
+
+// checkPrerequisiteIDs rejects prerequisite IDs that are not UUIDs, which
+// ToModel would otherwise drop silently.
+func checkPrerequisiteIDs(c *gin.Context, ids []string) bool {
+	var errs validation.Errors
+	for i, raw := range ids {
+		if _, err := uuid.Parse(raw); err != nil {
+			errs.Add(fmt.Sprintf("prerequisite_skill_ids[%d]", i), "must be a valid UUID")
+		}
+	}
+	return !writeValidationError(c, errs.Err())
+}
+
+func (h *SkillHandler) Reorder(c *gin.Context) {
+	unitID, err := uuid.Parse(c.Param("unitId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unit ID"})
+		return
+	}
+
+	ids, ok := orderIDs(c)
+	if !ok {
+		return
+	}
+
+	skills, err := h.skillService.ReorderSkills(c.Request.Context(), unitID, ids)
+	if err != nil {
+		writeReorderError(c, err, "Unit not found", "Could not reorder skills")
+		return
+	}
+
+	page := &repository.Page[*model.Skill]{Items: skills}
+	writePage(c, page, func(v *model.Skill) dto.SkillResponse {
+		return dto.FromSkillModel(*v)
+	})
+}
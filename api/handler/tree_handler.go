@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/bytebeatz/bandroom-cms/api/dto"
+	"github.com/bytebeatz/bandroom-cms/core/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TreeHandler defines HTTP handlers for whole-course hierarchies.
+type TreeHandler struct {
+	treeService *service.TreeService
+}
+
+// NewTreeHandler initializes a new TreeHandler.
+func NewTreeHandler(svc *service.TreeService) *TreeHandler {
+	return &TreeHandler{treeService: svc}
+}
+
+// GetCourseTree handles GET /api/courses/:id/tree?depth=units|skills|lessons|exercises
+func (h *TreeHandler) GetCourseTree(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	depth, err := service.ParseTreeDepth(c.Query("depth"))
+	if err != nil {
+		writeQueryError(c, err)
+		return
+	}
+
+	tree, err := h.treeService.GetCourseTree(c.Request.Context(), id, depth)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+			return
+		}
+		log.Println("Failed to build course tree:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not load course tree"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromCourseTreeModel(*tree))
+}
@@ -1,10 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/bytebeatz/bandroom-cms/api/dto"
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
 	"github.com/bytebeatz/bandroom-cms/core/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -36,7 +40,7 @@ func (h *UnitHandler) Create(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, dto.FromUnitModel(unit))
+	writeVersioned(c, http.StatusCreated, unit.Version, dto.FromUnitModel(unit))
 }
 
 // GetByID handles GET /api/units/:id
@@ -47,13 +51,13 @@ func (h *UnitHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	unit, err := h.unitService.GetUnitByID(c.Request.Context(), id)
+	unit, err := h.unitService.GetUnitByID(c.Request.Context(), id, includeDeleted(c))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Unit not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.FromUnitModel(*unit))
+	writeVersioned(c, http.StatusOK, unit.Version, dto.FromUnitModel(*unit))
 }
 
 // Update handles PUT /api/units/:id
@@ -73,12 +77,25 @@ func (h *UnitHandler) Update(c *gin.Context) {
 	unit := req.ToModel()
 	unit.ID = id
 
+	version, ok := expectedVersion(c, req.Version)
+	if !ok {
+		return
+	}
+	unit.Version = version
+
 	if err := h.unitService.UpdateUnit(c.Request.Context(), &unit); err != nil {
+		if writeVersionConflict(c, err) || writePublishedEdit(c, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Unit not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not update unit"})
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.FromUnitModel(unit))
+	writeVersioned(c, http.StatusOK, unit.Version, dto.FromUnitModel(unit))
 }
 
 // Delete handles DELETE /api/units/:id
@@ -90,6 +107,10 @@ func (h *UnitHandler) Delete(c *gin.Context) {
 	}
 
 	if err := h.unitService.DeleteUnit(c.Request.Context(), id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Unit not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not delete unit"})
 		return
 	}
@@ -97,7 +118,7 @@ func (h *UnitHandler) Delete(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
-// ListByCourse handles GET /api/courses/:courseId/units
+// ListByCourse handles GET /api/units/course/:courseId
 func (h *UnitHandler) ListByCourse(c *gin.Context) {
 	courseID, err := uuid.Parse(c.Param("courseId"))
 	if err != nil {
@@ -105,44 +126,99 @@ func (h *UnitHandler) ListByCourse(c *gin.Context) {
 		return
 	}
 
-	units, err := h.unitService.ListUnitsByCourseID(c.Request.Context(), courseID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch units"})
+	q, ok := listQuery(c)
+	if !ok {
 		return
 	}
+	q.ParentID = &courseID
 
-	var res []dto.UnitResponse
-	for _, unit := range units {
-		res = append(res, dto.FromUnitModel(*unit))
+	page, err := h.unitService.ListUnits(c.Request.Context(), q)
+	if err != nil {
+		if writeQueryError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch units"})
+		return
 	}
 
-	c.JSON(http.StatusOK, res)
+	writePage(c, page, func(v *model.Unit) dto.UnitResponse {
+		return dto.FromUnitModel(*v)
+	})
 }
 
 // List handles GET /api/units?course_id=...
 func (h *UnitHandler) List(c *gin.Context) {
-	courseIDStr := c.Query("course_id")
-	if courseIDStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing course_id"})
+	q, ok := listQuery(c)
+	if !ok {
+		return
+	}
+	if !parentQuery(c, &q, "course_id") {
 		return
 	}
 
-	courseID, err := uuid.Parse(courseIDStr)
+	page, err := h.unitService.ListUnits(c.Request.Context(), q)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course_id"})
+		if writeQueryError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch units"})
 		return
 	}
 
-	units, err := h.unitService.ListUnitsByCourseID(c.Request.Context(), courseID)
+	writePage(c, page, func(v *model.Unit) dto.UnitResponse {
+		return dto.FromUnitModel(*v)
+	})
+}
+
+// Restore handles POST /api/units/:id/restore
+func (h *UnitHandler) Restore(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch units"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unit ID"})
 		return
 	}
 
-	var res []dto.UnitResponse
-	for _, u := range units {
-		res = append(res, dto.FromUnitModel(*u))
+	unit, err := h.unitService.RestoreUnit(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrParentDeleted):
+			c.JSON(
+				http.StatusConflict,
+				gin.H{"error": "Restore the parent course before restoring this unit"},
+			)
+		case strings.Contains(err.Error(), "not found"):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Unit not found"})
+		default:
+			log.Println("Failed to restore unit:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not restore unit"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromUnitModel(*unit))
+}
+
+// Reorder handles PUT /api/units/course/:courseId/order
+func (h *UnitHandler) Reorder(c *gin.Context) {
+	courseID, err := uuid.Parse(c.Param("courseId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	ids, ok := orderIDs(c)
+	if !ok {
+		return
+	}
+
+	units, err := h.unitService.ReorderUnits(c.Request.Context(), courseID, ids)
+	if err != nil {
+		writeReorderError(c, err, "Course not found", "Could not reorder units")
+		return
 	}
 
-	c.JSON(http.StatusOK, res)
+	page := &repository.Page[*model.Unit]{Items: units}
+	writePage(c, page, func(v *model.Unit) dto.UnitResponse {
+		return dto.FromUnitModel(*v)
+	})
 }
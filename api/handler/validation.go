@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bytebeatz/bandroom-cms/core/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// writeValidationError responds with 400 and the field-level errors when err
+// carries validation failures. It reports whether a response was written.
+func writeValidationError(c *gin.Context, err error) bool {
+	var verrs validation.Errors
+	if !errors.As(err, &verrs) {
+		return false
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":  "Validation failed",
+		"fields": verrs,
+	})
+	return true
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// etag formats an entity version as a strong ETag.
+func etag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// parseETag reads the version back out of an ETag, accepting weak tags too.
+func parseETag(tag string) (int, error) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+	version, err := strconv.Atoi(strings.Trim(tag, `"`))
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("invalid ETag %q", tag)
+	}
+	return version, nil
+}
+
+// expectedVersion returns the version an update is based on. An If-Match
+// header (the ETag from an earlier GET) wins over the body's version field.
+// When neither is usable it writes the error response and returns false.
+func expectedVersion(c *gin.Context, bodyVersion int) (int, bool) {
+	if header := c.GetHeader("If-Match"); header != "" {
+		version, err := parseETag(header)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid If-Match header"})
+			return 0, false
+		}
+		return version, true
+	}
+
+	if bodyVersion > 0 {
+		return bodyVersion, true
+	}
+
+	c.JSON(http.StatusPreconditionRequired, gin.H{
+		"error": "Version required: send an If-Match header or a version field",
+	})
+	return 0, false
+}
+
+// writeVersioned writes body with an ETag for version. A GET whose
+// If-None-Match already names that version gets 304 Not Modified instead.
+func writeVersioned(c *gin.Context, status int, version int, body any) {
+	tag := etag(version)
+	c.Header("ETag", tag)
+	if status == http.StatusOK && c.GetHeader("If-None-Match") == tag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.JSON(status, body)
+}
+
+// writeVersionConflict answers 409 if err is a stale-version update and
+// reports whether it did.
+func writeVersionConflict(c *gin.Context, err error) bool {
+	if !errors.Is(err, repository.ErrVersionConflict) {
+		return false
+	}
+	c.JSON(http.StatusConflict, gin.H{
+		"error": "Version conflict: the resource was changed by someone else, reload and retry",
+	})
+	return true
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/bytebeatz/bandroom-cms/api/dto"
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/service"
+	"github.com/bytebeatz/bandroom-cms/core/workflow"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// WorkflowHandler defines HTTP handlers for publishing workflow transitions.
+// Like RevisionHandler, it returns handlers bound to one entity type.
+type WorkflowHandler struct {
+	workflowService *service.WorkflowService
+}
+
+// NewWorkflowHandler initializes a new WorkflowHandler.
+func NewWorkflowHandler(svc *service.WorkflowService) *WorkflowHandler {
+	return &WorkflowHandler{workflowService: svc}
+}
+
+// Transition handles POST /api/<entities>/:id/transitions
+func (h *WorkflowHandler) Transition(entityType model.EntityType) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		var req dto.TransitionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		changes, err := h.workflowService.Transition(c.Request.Context(), entityType, id,
+			workflow.Action(req.Action), c.GetString("role"), req.Cascade)
+		if err != nil {
+			writeWorkflowError(c, err)
+			return
+		}
+
+		res := make([]dto.StatusChangeResponse, 0, len(changes))
+		for _, ch := range changes {
+			res = append(res, dto.FromStatusChangeModel(ch))
+		}
+		c.JSON(http.StatusOK, gin.H{"status": changes[0].To, "changes": res})
+	}
+}
+
+// Schedule handles PUT /api/courses/:id/schedule
+func (h *WorkflowHandler) Schedule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var req dto.ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	course, err := h.workflowService.ScheduleCourse(c.Request.Context(), id,
+		req.PublishAt, req.UnpublishAt)
+	if err != nil {
+		writeWorkflowError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromModel(*course))
+}
+
+// writePublishedEdit answers 409 if err rejects an edit of published content
+// and reports whether it did.
+func writePublishedEdit(c *gin.Context, err error) bool {
+	if !errors.Is(err, workflow.ErrPublished) {
+		return false
+	}
+	c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	return true
+}
+
+func writeWorkflowError(c *gin.Context, err error) {
+	if writeValidationError(c, err) || writeVersionConflict(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, workflow.ErrUnknownAction):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, workflow.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, workflow.ErrInvalidTransition), errors.Is(err, workflow.ErrBlocked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+	default:
+		log.Println("Failed to apply workflow transition:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not apply transition"})
+	}
+}
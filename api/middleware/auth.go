@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -49,6 +50,12 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Set("email", claims["email"])
 		c.Set("role", claims["role"])
 
+		// Services only see the request context, so expose the user there too
+		if userID, ok := claims["user_id"].(string); ok {
+			ctx := context.WithValue(c.Request.Context(), "user_id", userID)
+			c.Request = c.Request.WithContext(ctx)
+		}
+
 		c.Next()
 	}
 }
@@ -3,6 +3,8 @@ package router
 import (
 	"github.com/bytebeatz/bandroom-cms/api/handler"
 	"github.com/bytebeatz/bandroom-cms/api/middleware"
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/workflow"
 	"github.com/gin-gonic/gin"
 )
 
@@ -11,6 +13,17 @@ func SetupRouter(
 	unitHandler *handler.UnitHandler,
 	skillHandler *handler.SkillHandler,
 	lessonHandler *handler.LessonHandler,
+	exerciseHandler *handler.ExerciseHandler,
+	exerciseOptionHandler *handler.ExerciseOptionHandler,
+	revisionHandler *handler.RevisionHandler,
+	searchHandler *handler.SearchHandler,
+	treeHandler *handler.TreeHandler,
+	skillGraphHandler *handler.SkillGraphHandler,
+	workflowHandler *handler.WorkflowHandler,
+	releaseHandler *handler.ReleaseHandler,
+	packHandler *handler.PackHandler,
+	portableHandler *handler.PortableHandler,
+	mediaHandler *handler.MediaHandler,
 ) *gin.Engine {
 	r := gin.New()
 
@@ -22,9 +35,16 @@ func SetupRouter(
 	// Health check
 	r.GET("/health", handler.HealthCheck)
 
+	// Files of the local and memory storage backends, without auth but only
+	// through signed URLs that expire
+	r.GET("/files/*path", mediaHandler.ServeFile)
+
 	// Protected API
 	api := r.Group("/api", middleware.AuthMiddleware())
 	{
+		// Search across courses, units, skills, lessons and exercises
+		api.GET("/search", middleware.RequireAdmin(), searchHandler.Search)
+
 		// Course routes
 		courses := api.Group("/courses")
 		courses.Use(middleware.RequireAdmin())
@@ -34,6 +54,13 @@ func SetupRouter(
 			courses.GET("/:id", courseHandler.GetByID)
 			courses.PUT("/:id", courseHandler.Update)
 			courses.DELETE("/:id", courseHandler.Delete)
+			courses.POST("/:id/restore", courseHandler.Restore)
+			courses.GET("/:id/tree", treeHandler.GetCourseTree)              // optional ?depth= units|skills|lessons
+			courses.GET("/:id/skill-graph", skillGraphHandler.GetSkillGraph) // optional ?format= json|dot|mermaid
+			courses.PUT("/:id/schedule", workflowHandler.Schedule)
+			courses.GET("/:id/export", portableHandler.Export) // optional ?format= json|yaml
+			courses.POST("/import", portableHandler.Import)    // optional ?dry_run=true and ?format=
+			revisionRoutes(courses, revisionHandler, model.EntityCourse)
 		}
 
 		// Unit routes
@@ -41,10 +68,14 @@ func SetupRouter(
 		units.Use(middleware.RequireAdmin())
 		{
 			units.POST("", unitHandler.Create)
+			units.GET("", unitHandler.List) // optional ?course_id= query param
 			units.GET("/course/:courseId", unitHandler.ListByCourse)
+			units.PUT("/course/:courseId/order", unitHandler.Reorder)
 			units.GET("/:id", unitHandler.GetByID)
 			units.PUT("/:id", unitHandler.Update)
 			units.DELETE("/:id", unitHandler.Delete)
+			units.POST("/:id/restore", unitHandler.Restore)
+			revisionRoutes(units, revisionHandler, model.EntityUnit)
 		}
 
 		// Skill routes
@@ -52,10 +83,13 @@ func SetupRouter(
 		skills.Use(middleware.RequireAdmin())
 		{
 			skills.POST("", skillHandler.Create)
-			skills.GET("", skillHandler.ListByUnit) // expects ?unit_id= query param
+			skills.GET("", skillHandler.List) // optional ?unit_id= query param
+			skills.PUT("/unit/:unitId/order", skillHandler.Reorder)
 			skills.GET("/:id", skillHandler.GetByID)
 			skills.PUT("/:id", skillHandler.Update)
 			skills.DELETE("/:id", skillHandler.Delete)
+			skills.POST("/:id/restore", skillHandler.Restore)
+			revisionRoutes(skills, revisionHandler, model.EntitySkill)
 		}
 
 		// Lesson routes
@@ -63,13 +97,87 @@ func SetupRouter(
 		lessons.Use(middleware.RequireAdmin())
 		{
 			lessons.POST("", lessonHandler.Create)
-			lessons.GET("", lessonHandler.ListBySkill) // expects ?skill_id= query param
+			lessons.GET("", lessonHandler.List) // optional ?skill_id= query param
+			lessons.PUT("/skill/:skillId/order", lessonHandler.Reorder)
 			lessons.GET("/:id", lessonHandler.GetByID)
 			lessons.PUT("/:id", lessonHandler.Update)
 			lessons.DELETE("/:id", lessonHandler.Delete)
+			lessons.POST("/:id/restore", lessonHandler.Restore)
+			revisionRoutes(lessons, revisionHandler, model.EntityLesson)
+		}
+
+		// Exercise routes
+		exercises := api.Group("/exercises")
+		exercises.Use(middleware.RequireAdmin())
+		{
+			exercises.POST("", exerciseHandler.Create)
+			exercises.GET("", exerciseHandler.List) // expects ?lesson_id= or ?skill_id= query param
+			exercises.PUT("/lesson/:lessonId/order", exerciseHandler.Reorder)
+			exercises.POST("/lesson/:lessonId/import", exerciseHandler.ImportCSV) // CSV as multipart "file" or raw body
+			exercises.GET("/:id", exerciseHandler.GetByID)
+			exercises.PUT("/:id", exerciseHandler.Update)
+			exercises.DELETE("/:id", exerciseHandler.Delete)
+			exercises.POST("/:id/restore", exerciseHandler.Restore)
+			revisionRoutes(exercises, revisionHandler, model.EntityExercise)
+			exercises.POST("/:id/grade", exerciseHandler.Grade)
+
+			// Nested option routes
+			exercises.GET("/:id/options", exerciseOptionHandler.List)
+			exercises.POST("/:id/options", exerciseOptionHandler.Create)
+			exercises.PUT("/:id/options", exerciseOptionHandler.Replace)
+			exercises.PUT("/:id/options/order", exerciseOptionHandler.Reorder)
+			exercises.PUT("/:id/options/:optionId", exerciseOptionHandler.Update)
+			exercises.DELETE("/:id/options/:optionId", exerciseOptionHandler.Delete)
+		}
+
+		// Media routes; exercises and options use a media asset by its url
+		media := api.Group("/media")
+		media.Use(middleware.RequireAdmin())
+		{
+			media.POST("", mediaHandler.Upload) // multipart with the file in "file"
+			media.GET("/:id", mediaHandler.Get)
+			media.GET("/:id/content", mediaHandler.Download)
+			media.DELETE("/:id", mediaHandler.Delete)
 		}
+
+		// Workflow transitions are open to editors and reviewers too; which
+		// role may perform which action is checked per transition
+		transitions := api.Group("", middleware.RequireRole(workflow.Roles...))
+		{
+			transitions.POST("/courses/:id/transitions", workflowHandler.Transition(model.EntityCourse))
+			transitions.POST("/units/:id/transitions", workflowHandler.Transition(model.EntityUnit))
+			transitions.POST("/skills/:id/transitions", workflowHandler.Transition(model.EntitySkill))
+			transitions.POST("/lessons/:id/transitions", workflowHandler.Transition(model.EntityLesson))
+			transitions.POST("/exercises/:id/transitions", workflowHandler.Transition(model.EntityExercise))
+		}
+
+		// Release routes; courses are addressed by ID or slug. Learner apps
+		// read releases, only admins cut and promote them
+		releases := api.Group("/releases")
+		{
+			releases.GET("/:course", releaseHandler.List)
+			releases.GET("/:course/diff", releaseHandler.Diff)    // expects ?from= and ?to= versions
+			releases.GET("/:course/:version", releaseHandler.Get) // version number or channel (beta, stable)
+			releases.POST("/:course", middleware.RequireAdmin(), releaseHandler.Create)
+			releases.POST("/:course/:version/promote", middleware.RequireAdmin(), releaseHandler.Promote)
+		}
+
+		// Offline pack of a release, for learner apps
+		api.GET("/courses/:id/pack", packHandler.GetPack) // optional ?version= and ?since= for a delta
 	}
 
 	return r
 }
 
+// revisionRoutes mounts the revision history endpoints under a content group.
+func revisionRoutes(
+	group *gin.RouterGroup,
+	h *handler.RevisionHandler,
+	entityType model.EntityType,
+) {
+	group.GET("/:id/revisions", h.List(entityType))
+	group.GET("/:id/revisions/diff", h.Diff(entityType)) // expects ?from= and ?to= versions
+	group.GET("/:id/revisions/:version", h.Get(entityType))
+	group.POST("/:id/revisions/:version/rollback", h.Rollback(entityType))
+}
+
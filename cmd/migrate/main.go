@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/bytebeatz/bandroom-cms/config"
+	"github.com/bytebeatz/bandroom-cms/db"
+)
+
+const usage = `usage: migrate <command>
+
+commands:
+  up            apply all pending migrations
+  down [steps]  roll back the last migration (or the last N)
+  status        list migrations and when they were applied`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	config.LoadConfig()
+	config.InitPostgres()
+	defer db.CloseDB()
+
+	ctx := context.Background()
+	m, err := db.NewMigrator(config.DB)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	switch os.Args[1] {
+	case "up":
+		applied, err := m.Up(ctx)
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		log.Printf("Applied %d migration(s).", len(applied))
+
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			steps, err = strconv.Atoi(os.Args[2])
+			if err != nil || steps < 1 {
+				log.Fatalf("Invalid step count %q", os.Args[2])
+			}
+		}
+		reverted, err := m.Down(ctx, steps)
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+		log.Printf("Reverted %d migration(s).", len(reverted))
+
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+		for _, st := range statuses {
+			applied := "pending"
+			if st.AppliedAt != nil {
+				applied = st.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%04d  %-32s %s\n", st.Version, st.Name, applied)
+		}
+
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"log"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
@@ -15,6 +16,30 @@ type Config struct {
 	GCSEnabled  bool
 	GoogleCreds string
 	JWTSecret   string
+	AutoMigrate bool
+
+	// StorageBackend picks where media files are kept: gcs, local or memory
+	StorageBackend string
+	// StorageDir is the directory of the local backend
+	StorageDir string
+	// PublicURL is where clients reach this server; the local and memory
+	// backends serve files under it
+	PublicURL string
+	// GCSPublicURL is where bucket objects are served from, if not
+	// storage.googleapis.com
+	GCSPublicURL string
+	// GCSSignerEmail and GCSSignerKey are the service account that signs
+	// GCS URLs; see loadGCSSigner
+	GCSSignerEmail string
+	GCSSignerKey   []byte
+	// StorageSigningKey signs the URLs of the local and memory backends,
+	// which refuse to start without it
+	StorageSigningKey string
+	// SignedURLExpiry is how long signed media URLs stay valid
+	SignedURLExpiry time.Duration
+
+	// SchedulerInterval is how often scheduled publishing is checked
+	SchedulerInterval time.Duration
 }
 
 var AppConfig *Config
@@ -39,7 +64,24 @@ func LoadConfig() {
 		GCSEnabled:  viper.GetBool("GCS_ENABLED"),
 		GoogleCreds: getString("GOOGLE_APPLICATION_CREDENTIALS", ""),
 		JWTSecret:   getString("JWT_SECRET", "your-secret-here"),
+		AutoMigrate: getBool("AUTO_MIGRATE", true),
+
+		StorageDir:   getString("STORAGE_DIR", "data/storage"),
+		GCSPublicURL: getString("GCS_PUBLIC_URL", ""),
+
+		SignedURLExpiry: getDuration("SIGNED_URL_EXPIRY", 15*time.Minute),
+
+		SchedulerInterval: getDuration("SCHEDULER_INTERVAL", time.Minute),
+	}
+
+	defaultBackend := "local"
+	if AppConfig.GCSEnabled {
+		defaultBackend = "gcs"
 	}
+	AppConfig.StorageBackend = getString("STORAGE_BACKEND", defaultBackend)
+	AppConfig.PublicURL = getString("PUBLIC_URL", "http://localhost:"+AppConfig.Port)
+	AppConfig.StorageSigningKey = getString("STORAGE_SIGNING_KEY", "")
+	loadGCSSigner(AppConfig)
 
 	log.Printf("Loaded DATABASE_URL: %s", AppConfig.DBUrl)
 	log.Printf("Loaded JWT_SECRET: %s", AppConfig.JWTSecret)
@@ -51,3 +93,17 @@ func getString(key string, fallback string) string {
 	}
 	return fallback
 }
+
+func getDuration(key string, fallback time.Duration) time.Duration {
+	if d := viper.GetDuration(key); d > 0 {
+		return d
+	}
+	return fallback
+}
+
+func getBool(key string, fallback bool) bool {
+	if !viper.IsSet(key) {
+		return fallback
+	}
+	return viper.GetBool(key)
+}
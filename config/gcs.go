@@ -2,7 +2,10 @@ package config
 
 import (
 	"context"
+	"encoding/json"
 	"log"
+	"os"
+	"strings"
 
 	"cloud.google.com/go/storage"
 )
@@ -23,3 +26,43 @@ func InitGCS(ctx context.Context) {
 	GCSClient = client
 	log.Println("Connected to Google Cloud Storage.")
 }
+
+// loadGCSSigner sets the credentials GCS URLs are signed with: the
+// service account in GCS_SIGNER_EMAIL and GCS_SIGNER_KEY (a PEM private
+// key; "\n" escapes are accepted), or else the one in the
+// GOOGLE_APPLICATION_CREDENTIALS key file. Without either, signing falls
+// back to what the client library can find, such as the IAM credentials
+// of the instance.
+func loadGCSSigner(cfg *Config) {
+	cfg.GCSSignerEmail = getString("GCS_SIGNER_EMAIL", "")
+	if key := getString("GCS_SIGNER_KEY", ""); key != "" {
+		cfg.GCSSignerKey = []byte(strings.ReplaceAll(key, `\n`, "\n"))
+	}
+	if (cfg.GCSSignerEmail != "" && cfg.GCSSignerKey != nil) || cfg.GoogleCreds == "" {
+		return
+	}
+
+	data, err := os.ReadFile(cfg.GoogleCreds)
+	if err != nil {
+		log.Printf("Could not read %s for URL signing: %v", cfg.GoogleCreds, err)
+		return
+	}
+	var creds struct {
+		Type        string `json:"type"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+	}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		log.Printf("Could not parse %s for URL signing: %v", cfg.GoogleCreds, err)
+		return
+	}
+	if creds.Type != "service_account" {
+		return
+	}
+	if cfg.GCSSignerEmail == "" {
+		cfg.GCSSignerEmail = creds.ClientEmail
+	}
+	if cfg.GCSSignerKey == nil {
+		cfg.GCSSignerKey = []byte(creds.PrivateKey)
+	}
+}
@@ -0,0 +1,185 @@
+// Package audio analyses uploaded audio files in pure Go: WAV, MP3 and Ogg
+// Vorbis are decoded to measure their length, loudness and waveform.
+package audio
+
+import (
+	"errors"
+	"io"
+	"math"
+	"time"
+)
+
+// ErrUnsupported is returned for audio formats the package cannot read.
+var ErrUnsupported = errors.New("unsupported audio format")
+
+// EnvelopePoints is the most points a waveform envelope has.
+const EnvelopePoints = 200
+
+// SilenceDB is the level reported for digital silence, which has no
+// finite level in dBFS.
+const SilenceDB = -120.0
+
+// Loudness flags. Exercise audio is expected to be mixed with an RMS level
+// between QuietDB and LoudDB and not to reach full scale.
+const (
+	FlagSilent   = "silent"
+	FlagClipping = "clipping"
+	FlagTooQuiet = "too_quiet"
+	FlagTooLoud  = "too_loud"
+
+	SilentDB = -60.0 // peak at or below this is silent
+	ClipDB   = -0.01 // peak at or above this has clipped
+	QuietDB  = -30.0 // RMS below this is too quiet
+	LoudDB   = -8.0  // RMS above this is too loud
+)
+
+// Analysis is what Analyze finds out about an audio file.
+type Analysis struct {
+	Duration   time.Duration
+	SampleRate int
+	Channels   int
+	// PeakDB is the loudest sample and RMSDB the average level over all
+	// channels, both in dBFS (0 is full scale).
+	PeakDB float64
+	RMSDB  float64
+	// Envelope is the waveform as at most EnvelopePoints peak levels
+	// between 0 and 1, evenly spaced over the file.
+	Envelope []float64
+}
+
+// Flags returns the loudness flags that apply, in a fixed order. Silent
+// audio is only flagged as silent.
+func (a *Analysis) Flags() []string {
+	if a.PeakDB <= SilentDB {
+		return []string{FlagSilent}
+	}
+	var flags []string
+	if a.PeakDB >= ClipDB {
+		flags = append(flags, FlagClipping)
+	}
+	switch {
+	case a.RMSDB < QuietDB:
+		flags = append(flags, FlagTooQuiet)
+	case a.RMSDB > LoudDB:
+		flags = append(flags, FlagTooLoud)
+	}
+	return flags
+}
+
+// Analyze decodes an audio file with the given extension (".wav", ".mp3",
+// ".ogg" or ".oga"). It returns ErrUnsupported for other formats and for
+// Ogg streams that are not Vorbis.
+func Analyze(r io.ReadSeeker, ext string) (*Analysis, error) {
+	switch ext {
+	case ".wav":
+		return analyzeWAV(r)
+	case ".mp3":
+		return analyzeMP3(r)
+	case ".ogg", ".oga":
+		return analyzeOgg(r)
+	default:
+		return nil, ErrUnsupported
+	}
+}
+
+// blockFrames is how many frames each raw envelope point covers before the
+// envelope is reduced to EnvelopePoints.
+const blockFrames = 256
+
+// meter measures interleaved samples between -1 and 1 as they are decoded.
+type meter struct {
+	channels   int
+	frames     int64
+	peak       float64
+	sumSquares float64
+
+	block     float64 // peak of the current envelope block
+	inBlock   int     // frames in the current block
+	envelope  []float64
+	remainder int  // samples of an incomplete frame from the last add
+	nonFinite bool // a float sample was NaN or infinite
+}
+
+func newMeter(channels int) *meter {
+	return &meter{channels: channels}
+}
+
+func (m *meter) add(samples []float64) {
+	for _, s := range samples {
+		if math.IsNaN(s) || math.IsInf(s, 0) {
+			m.nonFinite = true
+			continue
+		}
+		a := math.Abs(s)
+		m.peak = max(m.peak, a)
+		m.block = max(m.block, a)
+		m.sumSquares += s * s
+
+		m.remainder++
+		if m.remainder < m.channels {
+			continue
+		}
+		m.remainder = 0
+		m.frames++
+		m.inBlock++
+		if m.inBlock == blockFrames {
+			m.envelope = append(m.envelope, m.block)
+			m.block, m.inBlock = 0, 0
+		}
+	}
+}
+
+func (m *meter) analysis(sampleRate, channels int) (*Analysis, error) {
+	if sampleRate <= 0 || m.channels <= 0 {
+		return nil, errors.New("invalid sample rate or channel count")
+	}
+	if m.nonFinite {
+		return nil, errors.New("audio has NaN or infinite samples")
+	}
+	if m.inBlock > 0 {
+		m.envelope = append(m.envelope, m.block)
+	}
+
+	a := &Analysis{
+		Duration:   time.Duration(m.frames) * time.Second / time.Duration(sampleRate),
+		SampleRate: sampleRate,
+		Channels:   channels,
+		PeakDB:     decibels(m.peak),
+		RMSDB:      SilenceDB,
+		Envelope:   reduce(m.envelope, EnvelopePoints),
+	}
+	if samples := m.frames * int64(m.channels); samples > 0 {
+		a.RMSDB = decibels(math.Sqrt(m.sumSquares / float64(samples)))
+	}
+	return a, nil
+}
+
+// decibels converts a level between 0 and 1 to dBFS, rounded to 0.01 dB.
+func decibels(level float64) float64 {
+	if level <= 0 {
+		return SilenceDB
+	}
+	db := math.Round(max(20*math.Log10(level), SilenceDB)*100) / 100
+	if db == 0 {
+		return 0 // rather than -0 for levels just under full scale
+	}
+	return db
+}
+
+// reduce keeps the peak of each of n even spans of levels, rounded to three
+// decimals.
+func reduce(levels []float64, n int) []float64 {
+	if len(levels) < n {
+		n = len(levels)
+	}
+	out := make([]float64, n)
+	for i := range out {
+		from, to := i*len(levels)/n, (i+1)*len(levels)/n
+		peak := 0.0
+		for _, l := range levels[from:to] {
+			peak = max(peak, l)
+		}
+		out[i] = math.Round(min(peak, 1)*1000) / 1000
+	}
+	return out
+}
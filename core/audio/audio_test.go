@@ -0,0 +1,239 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// wavFile builds a WAV file from a fmt chunk body and sample data.
+func wavFile(fmtChunk, data []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(4+8+len(fmtChunk)+8+len(data)))
+	b.WriteString("WAVE")
+	if fmtChunk != nil {
+		b.WriteString("fmt ")
+		binary.Write(&b, binary.LittleEndian, uint32(len(fmtChunk)))
+		b.Write(fmtChunk)
+		if len(fmtChunk)%2 == 1 {
+			b.WriteByte(0)
+		}
+	}
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, uint32(len(data)))
+	b.Write(data)
+	return b.Bytes()
+}
+
+// fmtChunk builds the 16-byte fmt chunk of a format.
+func fmtChunk(format, channels uint16, rate uint32, bits uint16) []byte {
+	var b bytes.Buffer
+	align := channels * bits / 8
+	binary.Write(&b, binary.LittleEndian, format)
+	binary.Write(&b, binary.LittleEndian, channels)
+	binary.Write(&b, binary.LittleEndian, rate)
+	binary.Write(&b, binary.LittleEndian, rate*uint32(align))
+	binary.Write(&b, binary.LittleEndian, align)
+	binary.Write(&b, binary.LittleEndian, bits)
+	return b.Bytes()
+}
+
+// samples encodes values little-endian, each as a T.
+func samples[T any](values ...T) []byte {
+	var b bytes.Buffer
+	for _, v := range values {
+		binary.Write(&b, binary.LittleEndian, v)
+	}
+	return b.Bytes()
+}
+
+func TestAnalyzeWAV(t *testing.T) {
+	halfScale := make([]int16, 8000)
+	for i := range halfScale {
+		halfScale[i] = 1 << 14
+	}
+
+	tests := []struct {
+		name     string
+		file     []byte
+		duration time.Duration
+		channels int
+		peakDB   float64
+	}{
+		{
+			name:     "16-bit mono",
+			file:     wavFile(fmtChunk(1, 1, 8000, 16), samples(halfScale...)),
+			duration: time.Second,
+			channels: 1,
+			peakDB:   -6.02,
+		},
+		{
+			name:     "8-bit stereo",
+			file:     wavFile(fmtChunk(1, 2, 4, 8), []byte{128, 128, 255, 0, 192, 64, 128, 128}),
+			duration: time.Second,
+			channels: 2,
+			peakDB:   0,
+		},
+		{
+			name:     "24-bit",
+			file:     wavFile(fmtChunk(1, 1, 2, 24), []byte{0, 0, 0x40, 0, 0, 0xC0}),
+			duration: time.Second,
+			channels: 1,
+			peakDB:   -6.02,
+		},
+		{
+			name:     "float",
+			file:     wavFile(fmtChunk(3, 1, 2, 32), samples[float32](0.5, -0.25)),
+			duration: time.Second,
+			channels: 1,
+			peakDB:   -6.02,
+		},
+		{
+			name: "trailing partial frame",
+			file: wavFile(fmtChunk(1, 2, 1, 16),
+				append(samples[int16](1<<14, 1<<14), 0, 1)),
+			duration: time.Second,
+			channels: 2,
+			peakDB:   -6.02,
+		},
+		{
+			name: "odd fmt chunk with extra bytes",
+			file: wavFile(append(fmtChunk(1, 1, 2, 16), 0, 0, 7),
+				samples[int16](1<<14, 0)),
+			duration: time.Second,
+			channels: 1,
+			peakDB:   -6.02,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := Analyze(bytes.NewReader(tt.file), ".wav")
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			if a.Duration != tt.duration || a.Channels != tt.channels || a.PeakDB != tt.peakDB {
+				t.Errorf("Analyze() = %v, %d channels, peak %v dB; want %v, %d, %v",
+					a.Duration, a.Channels, a.PeakDB, tt.duration, tt.channels, tt.peakDB)
+			}
+		})
+	}
+}
+
+func TestAnalyzeWAVMalformed(t *testing.T) {
+	valid := fmtChunk(1, 1, 8000, 16)
+	hugeFmt := wavFile(valid, nil)
+	// A fmt chunk claiming 4 GiB must not be allocated
+	binary.LittleEndian.PutUint32(hugeFmt[16:20], 0xFFFFFFF0)
+
+	tests := []struct {
+		name string
+		file []byte
+	}{
+		{"empty", nil},
+		{"truncated RIFF header", []byte("RIFF\x00\x00")},
+		{"not WAVE", append([]byte("RIFF\x04\x00\x00\x00AVI "), make([]byte, 8)...)},
+		{"no chunks", []byte("RIFF\x04\x00\x00\x00WAVE")},
+		{"fmt chunk too short", wavFile(valid[:12], nil)},
+		{"fmt chunk truncated", wavFile(valid, nil)[:30]},
+		{"huge fmt chunk", hugeFmt},
+		{"data before fmt", wavFile(nil, samples[int16](0, 0))},
+		{"zero channels", wavFile(fmtChunk(1, 0, 8000, 16), nil)},
+		{"zero sample rate", wavFile(fmtChunk(1, 1, 0, 16), samples[int16](0))},
+		{"misaligned blocks", wavFile(fmtChunk(1, 2, 8000, 12), nil)},
+		{"unsupported format", wavFile(fmtChunk(2, 1, 8000, 16), nil)},
+		{"unsupported sample size", wavFile(fmtChunk(1, 1, 8000, 48), nil)},
+		{"NaN sample", wavFile(fmtChunk(3, 1, 8000, 32), samples(float32(math.NaN())))},
+		{"infinite sample", wavFile(fmtChunk(3, 1, 8000, 64), samples(math.Inf(-1)))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if a, err := Analyze(bytes.NewReader(tt.file), ".wav"); err == nil {
+				t.Errorf("Analyze() = %+v, want an error", a)
+			}
+		})
+	}
+}
+
+func TestAnalyzeOtherFormats(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        []byte
+		ext         string
+		unsupported bool
+	}{
+		{"unknown extension", []byte("fLaC"), ".flac", true},
+		{"Ogg Opus", append([]byte("OggS"), []byte("\x00\x02\x01OpusHead")...), ".ogg", true},
+		{"not Ogg", []byte("RIFF"), ".oga", false},
+		{"truncated Ogg Vorbis", append([]byte("OggS\x00\x02"), []byte("\x01vorbis")...), ".ogg", false},
+		{"MP3 without frames", bytes.Repeat([]byte{0}, 64), ".mp3", false},
+		{"MP3 with a truncated ID3 tag", []byte("ID3\x03\x00\x00\x00\x00\x7f\x7f"), ".mp3", false},
+		{"empty MP3", nil, ".mp3", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Analyze(bytes.NewReader(tt.file), tt.ext)
+			if err == nil {
+				t.Fatal("Analyze() succeeded, want an error")
+			}
+			if errors.Is(err, ErrUnsupported) != tt.unsupported {
+				t.Errorf("Analyze() error = %v, unsupported %v", err, tt.unsupported)
+			}
+		})
+	}
+}
+
+func TestFlags(t *testing.T) {
+	tests := []struct {
+		name   string
+		peak   float64
+		rms    float64
+		expect []string
+	}{
+		{"silent", SilenceDB, SilenceDB, []string{FlagSilent}},
+		{"fine", -3, -18, nil},
+		{"clipping and loud", 0, -6, []string{FlagClipping, FlagTooLoud}},
+		{"quiet", -20, -35, []string{FlagTooQuiet}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&Analysis{PeakDB: tt.peak, RMSDB: tt.rms}).Flags()
+			if len(got) != len(tt.expect) {
+				t.Fatalf("Flags() = %v, want %v", got, tt.expect)
+			}
+			for i := range got {
+				if got[i] != tt.expect[i] {
+					t.Errorf("Flags() = %v, want %v", got, tt.expect)
+				}
+			}
+		})
+	}
+}
+
+func TestReduce(t *testing.T) {
+	levels := []float64{0.1, 0.5, 0.2, 0.9, 1.5, 0.3}
+	tests := []struct {
+		n    int
+		want []float64
+	}{
+		{3, []float64{0.5, 0.9, 1}},
+		{6, []float64{0.1, 0.5, 0.2, 0.9, 1, 0.3}},
+		{10, []float64{0.1, 0.5, 0.2, 0.9, 1, 0.3}},
+		{1, []float64{1}},
+	}
+	for _, tt := range tests {
+		got := reduce(levels, tt.n)
+		if len(got) != len(tt.want) {
+			t.Fatalf("reduce(%d) = %v, want %v", tt.n, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("reduce(%d) = %v, want %v", tt.n, got, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hajimehoshi/go-mp3"
+)
+
+// analyzeMP3 decodes MPEG-1 and MPEG-2 layer III audio. The decoder always
+// produces 16-bit stereo, so the channel count comes from the first frame
+// header.
+func analyzeMP3(r io.ReadSeeker) (*Analysis, error) {
+	channels, err := mp3Channels(r)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	dec, err := mp3.NewDecoder(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading MP3: %w", err)
+	}
+
+	m := newMeter(2)
+	buf := make([]byte, 4*4096)
+	samples := make([]float64, 0, 2*4096)
+	for {
+		n, err := io.ReadFull(dec, buf)
+		n -= n % 4
+		samples = samples[:0]
+		for i := 0; i < n; i += 2 {
+			samples = append(samples, float64(int16(binary.LittleEndian.Uint16(buf[i:])))/(1<<15))
+		}
+		m.add(samples)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decoding MP3: %w", err)
+		}
+	}
+	return m.analysis(dec.SampleRate(), channels)
+}
+
+// maxMP3Search bounds how far past any ID3v2 tag the first frame is looked
+// for.
+const maxMP3Search = 64 << 10
+
+// mp3Channels returns the channel count in the first MPEG audio frame
+// header: 1 for mono, else 2.
+func mp3Channels(r io.ReadSeeker) (int, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(10)
+	if err != nil {
+		return 0, fmt.Errorf("reading MP3 header: %w", err)
+	}
+	if string(head[0:3]) == "ID3" {
+		// The tag size is a 28-bit "syncsafe" integer after the header
+		size := int(head[6])<<21 | int(head[7])<<14 | int(head[8])<<7 | int(head[9])
+		if head[5]&0x10 != 0 {
+			size += 10 // footer
+		}
+		if _, err := br.Discard(10 + size); err != nil {
+			return 0, fmt.Errorf("skipping ID3 tag: %w", err)
+		}
+	}
+
+	for searched := 0; searched < maxMP3Search; searched++ {
+		h, err := br.Peek(4)
+		if err != nil {
+			break
+		}
+		if validMP3Header(h) {
+			if h[3]>>6 == 3 {
+				return 1, nil
+			}
+			return 2, nil
+		}
+		br.Discard(1)
+	}
+	return 0, errors.New("no MP3 frame found")
+}
+
+// validMP3Header reports whether four bytes start a layer III frame.
+func validMP3Header(h []byte) bool {
+	version := h[1] >> 3 & 3
+	layer := h[1] >> 1 & 3
+	bitrate := h[2] >> 4
+	rate := h[2] >> 2 & 3
+	return h[0] == 0xFF && h[1]&0xE0 == 0xE0 &&
+		version != 1 && layer == 1 && bitrate != 0 && bitrate != 15 && rate != 3
+}
@@ -0,0 +1,58 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jfreymuth/oggvorbis"
+)
+
+// analyzeOgg decodes Ogg Vorbis. Other Ogg codecs, such as Opus, are
+// reported as ErrUnsupported.
+func analyzeOgg(r io.ReadSeeker) (*Analysis, error) {
+	// The first page holds the codec's identification header
+	var first [64]byte
+	n, err := io.ReadFull(r, first[:])
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("reading Ogg header: %w", err)
+	}
+	if string(first[0:4]) != "OggS" {
+		return nil, errors.New("not an Ogg file")
+	}
+	if !bytes.Contains(first[:n], []byte("\x01vorbis")) {
+		return nil, ErrUnsupported
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	dec, err := oggvorbis.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading Ogg Vorbis: %w", err)
+	}
+	channels := dec.Channels()
+	if channels <= 0 {
+		return nil, errors.New("invalid Ogg Vorbis channel count")
+	}
+
+	m := newMeter(channels)
+	buf := make([]float32, channels*4096)
+	samples := make([]float64, 0, len(buf))
+	for {
+		n, err := dec.Read(buf)
+		samples = samples[:0]
+		for _, s := range buf[:n] {
+			samples = append(samples, float64(s))
+		}
+		m.add(samples)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decoding Ogg Vorbis: %w", err)
+		}
+	}
+	return m.analysis(dec.SampleRate(), channels)
+}
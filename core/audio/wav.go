@@ -0,0 +1,168 @@
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// analyzeWAV decodes integer PCM of 8 to 32 bits and 32 or 64-bit float
+// samples.
+func analyzeWAV(r io.ReadSeeker) (*Analysis, error) {
+	info, err := readWAVHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	if info.channels <= 0 || info.blockAlign%info.channels != 0 {
+		return nil, errors.New("invalid WAV block alignment")
+	}
+	// Samples sit in containers of whole bytes; 20-bit audio uses three
+	size := info.blockAlign / info.channels
+	decode, err := wavDecoder(info.format, size)
+	if err != nil {
+		return nil, err
+	}
+
+	m := newMeter(info.channels)
+	data := bufio.NewReader(io.LimitReader(r, info.dataSize))
+	buf := make([]byte, info.blockAlign*1024)
+	samples := make([]float64, 0, info.channels*1024)
+	for {
+		n, err := io.ReadFull(data, buf)
+		// A trailing partial frame is dropped
+		n -= n % info.blockAlign
+		samples = samples[:0]
+		for i := 0; i < n; i += size {
+			samples = append(samples, decode(buf[i:i+size]))
+		}
+		m.add(samples)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading WAV data: %w", err)
+		}
+	}
+	return m.analysis(info.sampleRate, info.channels)
+}
+
+// wavDecoder returns a function converting one little-endian sample of the
+// given byte size to a level between -1 and 1.
+func wavDecoder(format uint16, size int) (func([]byte) float64, error) {
+	switch {
+	case format == 1 && size == 1:
+		// 8-bit PCM alone is unsigned
+		return func(b []byte) float64 { return (float64(b[0]) - 128) / 128 }, nil
+	case format == 1 && size == 2:
+		return func(b []byte) float64 {
+			return float64(int16(binary.LittleEndian.Uint16(b))) / (1 << 15)
+		}, nil
+	case format == 1 && size == 3:
+		return func(b []byte) float64 {
+			v := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+			return float64(v) / (1 << 23)
+		}, nil
+	case format == 1 && size == 4:
+		return func(b []byte) float64 {
+			return float64(int32(binary.LittleEndian.Uint32(b))) / (1 << 31)
+		}, nil
+	case format == 3 && size == 4:
+		return func(b []byte) float64 {
+			return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+		}, nil
+	case format == 3 && size == 8:
+		return func(b []byte) float64 {
+			return math.Float64frombits(binary.LittleEndian.Uint64(b))
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported WAV encoding (format %d, %d-byte samples)", format, size)
+	}
+}
+
+// wavInfo is what the RIFF header of a WAV file says about its samples.
+type wavInfo struct {
+	format        uint16 // 1 PCM, 3 IEEE float, 0xFFFE extensible
+	channels      int
+	sampleRate    int
+	bitsPerSample int
+	blockAlign    int
+	dataSize      int64 // bytes of sample data
+}
+
+// maxFmtChunk is the size of the longest fmt chunk, that of
+// WAVE_FORMAT_EXTENSIBLE.
+const maxFmtChunk = 40
+
+// readWAVHeader reads chunks up to the start of the sample data, leaving r
+// positioned there.
+func readWAVHeader(r io.ReadSeeker) (wavInfo, error) {
+	var info wavInfo
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return info, fmt.Errorf("reading WAV header: %w", err)
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return info, errors.New("not a RIFF/WAVE file")
+	}
+
+	haveFormat := false
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return info, fmt.Errorf("reading WAV chunk: %w", err)
+		}
+		id := string(chunk[0:4])
+		size := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return info, errors.New("WAV fmt chunk too short")
+			}
+			// The size is the file's word; only the fields read below are kept
+			buf := make([]byte, min(size, maxFmtChunk))
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return info, fmt.Errorf("reading WAV fmt chunk: %w", err)
+			}
+			info.format = binary.LittleEndian.Uint16(buf[0:2])
+			info.channels = int(binary.LittleEndian.Uint16(buf[2:4]))
+			info.sampleRate = int(binary.LittleEndian.Uint32(buf[4:8]))
+			info.blockAlign = int(binary.LittleEndian.Uint16(buf[12:14]))
+			info.bitsPerSample = int(binary.LittleEndian.Uint16(buf[14:16]))
+			if info.format == 0xFFFE && size >= 26 {
+				// The real format is the first two bytes of the sub-format GUID
+				info.format = binary.LittleEndian.Uint16(buf[24:26])
+			}
+			haveFormat = true
+			if _, err := r.Seek(size-int64(len(buf))+size%2, io.SeekCurrent); err != nil {
+				return info, err
+			}
+		case "data":
+			if !haveFormat {
+				return info, errors.New("WAV data chunk before fmt chunk")
+			}
+			// Streaming writers leave the size unset; trust the file instead
+			pos, err := r.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return info, err
+			}
+			end, err := r.Seek(0, io.SeekEnd)
+			if err != nil {
+				return info, err
+			}
+			if _, err := r.Seek(pos, io.SeekStart); err != nil {
+				return info, err
+			}
+			info.dataSize = min(size, end-pos)
+			return info, nil
+		default:
+			// Chunks are padded to an even size
+			if _, err := r.Seek(size+size%2, io.SeekCurrent); err != nil {
+				return info, err
+			}
+		}
+	}
+}
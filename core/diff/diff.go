@@ -0,0 +1,64 @@
+// Package diff compares JSON-shaped snapshots field by field.
+package diff
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Change is one field whose value differs between two snapshots. From is nil
+// when the field was added and To is nil when it was removed.
+type Change struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// Fields compares two JSON objects and returns the changed fields sorted by
+// name. Nested objects are compared key by key and reported with dotted
+// paths (e.g. "metadata.icon"); lists are compared as a whole. Top-level
+// fields named in ignore are skipped.
+func Fields(from, to map[string]any, ignore ...string) []Change {
+	skip := make(map[string]bool, len(ignore))
+	for _, f := range ignore {
+		skip[f] = true
+	}
+
+	var changes []Change
+	walk("", from, to, skip, &changes)
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
+
+func walk(prefix string, from, to map[string]any, skip map[string]bool, changes *[]Change) {
+	keys := map[string]bool{}
+	for k := range from {
+		keys[k] = true
+	}
+	for k := range to {
+		keys[k] = true
+	}
+
+	for k := range keys {
+		if prefix == "" && skip[k] {
+			continue
+		}
+		field := k
+		if prefix != "" {
+			field = prefix + "." + k
+		}
+
+		a, b := from[k], to[k]
+		subA, okA := a.(map[string]any)
+		subB, okB := b.(map[string]any)
+		if okA && okB {
+			walk(field, subA, subB, skip, changes)
+			continue
+		}
+		if !reflect.DeepEqual(a, b) {
+			*changes = append(*changes, Change{Field: field, From: a, To: b})
+		}
+	}
+}
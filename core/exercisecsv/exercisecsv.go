@@ -0,0 +1,400 @@
+// Package exercisecsv reads exercises for bulk import from a CSV file, as
+// exported from a spreadsheet.
+//
+// The first line names the columns. Names are matched ignoring case, spaces,
+// dashes and underscores, so "Objective Tag", "objective_tag" and
+// "ObjectiveTag" are the same column:
+//
+//	title             defaults to the prompt
+//	type              exercise type, e.g. multiple_choice or typing
+//	prompt
+//	points, grade     whole numbers, empty for 0
+//	syllabus
+//	objective_tag
+//	matching_type     matching exercises only
+//	media_url
+//	accepted_answers  typing answers separated by "|"
+//	metadata          a JSON object for any other metadata
+//	option_N          label of option N (N from 1)
+//	option_N_value    defaults to the label
+//	option_N_correct  true/false, yes/no, 1/0 or x for true
+//	option_N_media_url
+//
+// Options are taken in the order of N; an option with neither label, value
+// nor media is left out.
+package exercisecsv
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/validation"
+)
+
+// MaxRows caps the exercises read from one file.
+const MaxRows = 1000
+
+// maxOptions is the highest option column number; matching exercises take
+// two options per pair.
+const maxOptions = 2 * validation.MaxMatchingPairs
+
+// Row is one exercise read from a CSV line.
+type Row struct {
+	Line     int
+	Exercise *model.Exercise
+	Options  []model.ExerciseOption
+}
+
+// RowError is one problem found on a line of the file; line 1 is the header.
+type RowError struct {
+	Line    int    `json:"line"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// Errors collects the problems of every line of a file.
+type Errors []RowError
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, re := range e {
+		if re.Field == "" {
+			msgs = append(msgs, fmt.Sprintf("line %d: %s", re.Line, re.Message))
+		} else {
+			msgs = append(msgs, fmt.Sprintf("line %d: %s: %s", re.Line, re.Field, re.Message))
+		}
+	}
+	return "invalid CSV: " + strings.Join(msgs, "; ")
+}
+
+func (e *Errors) add(line int, field, format string, args ...any) {
+	*e = append(*e, RowError{Line: line, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// column is what a header cell maps to. option is 0 for exercise fields.
+type column struct {
+	field  string
+	option int
+}
+
+var exerciseColumns = map[string]string{
+	"title":           "title",
+	"type":            "type",
+	"prompt":          "prompt",
+	"points":          "points",
+	"grade":           "grade",
+	"syllabus":        "syllabus",
+	"objectivetag":    "objective_tag",
+	"objective":       "objective_tag",
+	"matchingtype":    "matching_type",
+	"mediaurl":        "media_url",
+	"acceptedanswers": "accepted_answers",
+	"metadata":        "metadata",
+}
+
+var optionColumn = regexp.MustCompile(`^option(\d+)(value|correct|mediaurl)?$`)
+
+// Parse reads and validates every row of a CSV file. It returns Errors
+// listing each problem by line when any row is invalid, so that either all
+// rows are imported or none.
+func Parse(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, Errors{{Line: 1, Message: "file is empty"}}
+	}
+	if err != nil {
+		return nil, csvError(err)
+	}
+	columns, errs := parseHeader(header)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	var rows []Row
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, append(errs, csvError(err)...)
+		}
+		line, _ := reader.FieldPos(0)
+		if blank(record) {
+			continue
+		}
+		if len(rows) == MaxRows {
+			errs.add(line, "", "too many rows, at most %d exercises can be imported at once", MaxRows)
+			break
+		}
+		if len(record) > len(columns) {
+			errs.add(line, "", "has %d cells but the header has %d columns", len(record), len(columns))
+			continue
+		}
+
+		row, rowErrs := parseRow(line, columns, record)
+		errs = append(errs, rowErrs...)
+		rows = append(rows, row)
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if len(rows) == 0 {
+		return nil, Errors{{Line: 2, Message: "file has no exercises"}}
+	}
+	return rows, nil
+}
+
+func csvError(err error) Errors {
+	var perr *csv.ParseError
+	if errors.As(err, &perr) {
+		return Errors{{Line: perr.Line, Message: perr.Err.Error()}}
+	}
+	return Errors{{Line: 1, Message: err.Error()}}
+}
+
+func parseHeader(header []string) ([]column, Errors) {
+	var errs Errors
+	columns := make([]column, len(header))
+	seen := map[column]bool{}
+	for i, name := range header {
+		key := normalize(name)
+		var col column
+		if field, ok := exerciseColumns[key]; ok {
+			col = column{field: field}
+		} else if m := optionColumn.FindStringSubmatch(key); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			if n < 1 || n > maxOptions {
+				errs.add(1, name, "option number must be between 1 and %d", maxOptions)
+				continue
+			}
+			col = column{field: m[2], option: n}
+			if col.field == "" {
+				col.field = "label"
+			}
+		} else if key == "" {
+			// Spreadsheets often add unnamed trailing columns
+			continue
+		} else {
+			errs.add(1, name, "unknown column")
+			continue
+		}
+
+		if seen[col] {
+			errs.add(1, name, "column is given more than once")
+		}
+		seen[col] = true
+		columns[i] = col
+	}
+
+	if !seen[column{field: "type"}] {
+		errs.add(1, "type", "column is required")
+	}
+	if !seen[column{field: "prompt"}] {
+		errs.add(1, "prompt", "column is required")
+	}
+	return columns, errs
+}
+
+func parseRow(line int, columns []column, record []string) (Row, Errors) {
+	var errs Errors
+	e := &model.Exercise{Metadata: model.JSONB{}}
+	type option struct {
+		label, value, mediaURL string
+		correct                bool
+	}
+	options := map[int]*option{}
+	var acceptedAnswers []string
+
+	for i, raw := range record {
+		col := columns[i]
+		cell := strings.TrimSpace(raw)
+		if col.option > 0 {
+			o := options[col.option]
+			if o == nil {
+				o = &option{}
+				options[col.option] = o
+			}
+			field := fmt.Sprintf("option_%d", col.option)
+			switch col.field {
+			case "label":
+				o.label = cell
+			case "value":
+				o.value = cell
+			case "mediaurl":
+				o.mediaURL = cell
+			case "correct":
+				correct, ok := parseBool(cell)
+				if !ok {
+					errs.add(line, field+"_correct", "must be true or false, got %q", cell)
+				}
+				o.correct = correct
+			}
+			continue
+		}
+
+		switch col.field {
+		case "title":
+			e.Title = cell
+		case "type":
+			e.Type = model.ExerciseType(cell)
+		case "prompt":
+			e.Prompt = cell
+		case "points":
+			e.Points = parseInt(&errs, line, col.field, cell)
+		case "grade":
+			e.Grade = parseInt(&errs, line, col.field, cell)
+		case "syllabus":
+			e.Syllabus = cell
+		case "objective_tag":
+			e.ObjectiveTag = cell
+		case "matching_type":
+			if cell != "" {
+				mt := model.MatchingType(cell)
+				e.MatchingType = &mt
+			}
+		case "media_url":
+			if cell != "" {
+				e.MediaURL = &cell
+			}
+		case "accepted_answers":
+			for _, answer := range strings.Split(cell, "|") {
+				if answer = strings.TrimSpace(answer); answer != "" {
+					acceptedAnswers = append(acceptedAnswers, answer)
+				}
+			}
+		case "metadata":
+			if cell != "" {
+				var meta map[string]any
+				if err := json.Unmarshal([]byte(cell), &meta); err != nil {
+					errs.add(line, col.field, "must be a JSON object")
+				}
+				for k, v := range meta {
+					e.Metadata[k] = v
+				}
+			}
+		}
+	}
+
+	if e.Title == "" {
+		e.Title = e.Prompt
+	}
+	if acceptedAnswers != nil {
+		answers := make([]any, 0, len(acceptedAnswers))
+		for _, a := range acceptedAnswers {
+			answers = append(answers, a)
+		}
+		e.Metadata["accepted_answers"] = answers
+	}
+
+	var opts []model.ExerciseOption
+	numbers := make([]int, 0, len(options))
+	for n := range options {
+		numbers = append(numbers, n)
+	}
+	slices.Sort(numbers)
+	for _, n := range numbers {
+		o := options[n]
+		if o.label == "" && o.value == "" && o.mediaURL == "" {
+			if o.correct {
+				errs.add(line, fmt.Sprintf("option_%d", n), "is marked correct but empty")
+			}
+			continue
+		}
+		opt := model.ExerciseOption{
+			Label:      o.label,
+			Value:      o.value,
+			IsCorrect:  o.correct,
+			OrderIndex: len(opts),
+		}
+		if opt.Value == "" {
+			opt.Value = opt.Label
+		}
+		if o.mediaURL != "" {
+			opt.MediaURL = &o.mediaURL
+		}
+		opts = append(opts, opt)
+	}
+
+	// Report validation problems under the CSV column names
+	if err := validation.Validate(e, opts); err != nil {
+		for _, fe := range err.(validation.Errors) {
+			errs.add(line, csvField(fe.Field), "%s", fe.Message)
+		}
+	}
+	return Row{Line: line, Exercise: e, Options: opts}, errs
+}
+
+var optionField = regexp.MustCompile(`^options\[(\d+)\]\.?`)
+
+// csvField turns a validation field such as options[0].value into the
+// column it came from, option_1_value.
+func csvField(field string) string {
+	if field == "metadata.accepted_answers" {
+		return "accepted_answers"
+	}
+	m := optionField.FindStringSubmatch(field)
+	if m == nil {
+		return field
+	}
+	i, _ := strconv.Atoi(m[1])
+	rest := field[len(m[0]):]
+	if rest == "" || rest == "label" {
+		return fmt.Sprintf("option_%d", i+1)
+	}
+	return fmt.Sprintf("option_%d_%s", i+1, rest)
+}
+
+func parseInt(errs *Errors, line int, field, cell string) int {
+	if cell == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(cell)
+	if err != nil {
+		errs.add(line, field, "must be a whole number, got %q", cell)
+	}
+	return n
+}
+
+func parseBool(cell string) (bool, bool) {
+	switch strings.ToLower(cell) {
+	case "", "false", "no", "n", "0":
+		return false, true
+	case "true", "yes", "y", "1", "x":
+		return true, true
+	}
+	return false, false
+}
+
+func normalize(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func blank(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
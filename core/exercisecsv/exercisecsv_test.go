@@ -0,0 +1,157 @@
+package exercisecsv
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+)
+
+func TestParse(t *testing.T) {
+	file := strings.Join([]string{
+		"Type, Prompt, Points, Objective Tag, Option 1, Option_1_Correct, option1value, Option 2, Option 2 Value, Accepted Answers, Metadata,",
+		`multiple_choice, "Which note is
+the root of C major?", 10, roots, C, x, , G, g5, , ,`,
+		"",
+		`typing, Name this interval, , intervals, , , , , , Perfect 5th | P5 |, "{""case_sensitive"": true}",`,
+		" , , ",
+	}, "\n")
+
+	rows, err := Parse(strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+
+	choice := rows[0]
+	if choice.Line != 2 || choice.Exercise.Type != model.ExerciseMultipleChoice ||
+		choice.Exercise.Title != "Which note is\nthe root of C major?" ||
+		choice.Exercise.Points != 10 || choice.Exercise.ObjectiveTag != "roots" {
+		t.Errorf("first row = line %d, %+v", choice.Line, choice.Exercise)
+	}
+	wantOpts := []struct {
+		label, value string
+		correct      bool
+	}{{"C", "C", true}, {"G", "g5", false}}
+	if len(choice.Options) != len(wantOpts) {
+		t.Fatalf("got %d options, want %d", len(choice.Options), len(wantOpts))
+	}
+	for i, want := range wantOpts {
+		o := choice.Options[i]
+		if o.Label != want.label || o.Value != want.value || o.IsCorrect != want.correct || o.OrderIndex != i {
+			t.Errorf("option %d = %+v, want %+v", i+1, o, want)
+		}
+	}
+
+	typing := rows[1]
+	if typing.Line != 5 || len(typing.Options) != 0 {
+		t.Errorf("second row = line %d with %d options", typing.Line, len(typing.Options))
+	}
+	answers, _ := typing.Exercise.Metadata["accepted_answers"].([]any)
+	if !slices.Equal(answers, []any{"Perfect 5th", "P5"}) {
+		t.Errorf("accepted_answers = %v", typing.Exercise.Metadata["accepted_answers"])
+	}
+	if typing.Exercise.Metadata["case_sensitive"] != true {
+		t.Errorf("metadata = %v", typing.Exercise.Metadata)
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	const header = "type,prompt,option_1,option_1_correct,option_2,points,metadata\n"
+	tooMany := header + strings.Repeat("multiple_choice,Q,A,x,B,,\n", MaxRows+1)
+
+	tests := []struct {
+		name string
+		file string
+		want []RowError // Message is matched as a substring
+	}{
+		{"empty", "", []RowError{{Line: 1, Message: "file is empty"}}},
+		{"header only", header, []RowError{{Line: 2, Message: "no exercises"}}},
+		{"only blank rows", header + ",,\n  \n", []RowError{{Line: 2, Message: "no exercises"}}},
+		{"unknown column", "type,prompt,colour\n", []RowError{{Line: 1, Field: "colour", Message: "unknown column"}}},
+		{"repeated column", "type,Prompt,prompt\n", []RowError{{Line: 1, Field: "prompt", Message: "more than once"}}},
+		{"missing required columns", "title\n", []RowError{
+			{Line: 1, Field: "type", Message: "required"},
+			{Line: 1, Field: "prompt", Message: "required"},
+		}},
+		{"option number 0", "type,prompt,option_0\n", []RowError{{Line: 1, Field: "option_0", Message: "between 1 and"}}},
+		{"option number too high", "type,prompt,option_99_value\n", []RowError{{Line: 1, Field: "option_99_value", Message: "between 1 and"}}},
+		{"unterminated quote", header + "multiple_choice,\"Q\n", []RowError{{Line: 2, Message: "quote"}}},
+		{"too many cells", header + "multiple_choice,Q,A,x,B,,,extra\n", []RowError{{Line: 2, Message: "has 8 cells"}}},
+		{"points not a number", header + "multiple_choice,Q,A,x,B,ten,\n", []RowError{{Line: 2, Field: "points", Message: "whole number"}}},
+		{"correct not a boolean", header + "multiple_choice,Q,A,maybe,B,,\n", []RowError{{Line: 2, Field: "option_1_correct", Message: "true or false"}}},
+		{"metadata not JSON", header + "multiple_choice,Q,A,x,B,,[1]\n", []RowError{{Line: 2, Field: "metadata", Message: "JSON object"}}},
+		{"correct but empty option", header + "multiple_choice,Q,,x,B,,\n", []RowError{{Line: 2, Field: "option_1", Message: "correct but empty"}}},
+		{"invalid exercise", header + "multiple_choice,Q,A,,B,,\n", []RowError{{Line: 2, Field: "options", Message: "correct"}}},
+		{"unknown type", header + "dictation,Q,,,,,\n", []RowError{{Line: 2, Field: "type"}}},
+		{"typing without answers", "type,prompt,accepted_answers\ntyping,Q,| |\n", []RowError{{Line: 2, Field: "accepted_answers"}}},
+		{"errors on several lines", header + "multiple_choice,,A,x,B,,\nmultiple_choice,Q,A,x,B,-1,\n", []RowError{
+			{Line: 2, Field: "prompt"},
+			{Line: 3, Field: "points"},
+		}},
+		{"too many rows", tooMany, []RowError{{Line: MaxRows + 2, Message: "too many rows"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := Parse(strings.NewReader(tt.file))
+			var errs Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Parse() = %d rows, %v; want Errors", len(rows), err)
+			}
+			for _, want := range tt.want {
+				found := slices.ContainsFunc(errs, func(got RowError) bool {
+					return got.Line == want.Line && got.Field == want.Field &&
+						strings.Contains(got.Message, want.Message)
+				})
+				if !found {
+					t.Errorf("Parse() errors = %v, want one like %+v", errs, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCSVField(t *testing.T) {
+	tests := []struct {
+		field, want string
+	}{
+		{"prompt", "prompt"},
+		{"options", "options"},
+		{"options[0]", "option_1"},
+		{"options[0].label", "option_1"},
+		{"options[2].value", "option_3_value"},
+		{"options[1].media_url", "option_2_media_url"},
+		{"metadata.accepted_answers", "accepted_answers"},
+		{"metadata.max_plays", "metadata.max_plays"},
+	}
+	for _, tt := range tests {
+		if got := csvField(tt.field); got != tt.want {
+			t.Errorf("csvField(%q) = %q, want %q", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestParseBool(t *testing.T) {
+	tests := []struct {
+		cell      string
+		value, ok bool
+	}{
+		{"", false, true},
+		{"No", false, true},
+		{"0", false, true},
+		{"TRUE", true, true},
+		{"x", true, true},
+		{"y", true, true},
+		{"maybe", false, false},
+		{"2", false, false},
+	}
+	for _, tt := range tests {
+		if value, ok := parseBool(tt.cell); value != tt.value || ok != tt.ok {
+			t.Errorf("parseBool(%q) = %v, %v; want %v, %v", tt.cell, value, ok, tt.value, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package grading
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Normalize prepares free text for comparison: it trims, collapses runs of
+// whitespace, drops trailing sentence punctuation and, unless caseSensitive is
+// set, lowercases the result.
+func Normalize(s string, caseSensitive bool) string {
+	s = strings.Join(strings.Fields(s), " ")
+	s = strings.TrimRightFunc(s, func(r rune) bool {
+		return r == '.' || r == '!' || r == '?' || r == ','
+	})
+	if !caseSensitive {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+// MaxTypos is the number of edits tolerated for an expected answer of the
+// given length: none for very short answers, where one letter changes the
+// meaning (e.g. "Am" vs "Em"), and more as the answer grows. Note names,
+// accidentals and numbers are never typos, see matchText.
+func MaxTypos(expected string) int {
+	n := len([]rune(expected))
+	switch {
+	case n <= 3:
+		return 0
+	case n <= 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Levenshtein returns the edit distance between two strings.
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// textMatch is the outcome of comparing a typed answer with accepted answers.
+type textMatch struct {
+	Matched bool
+	Exact   bool
+	Closest string
+}
+
+// matchText compares a response with a list of accepted answers, allowing a
+// small number of typos proportional to the answer's length. Words that carry
+// the musical meaning, i.e. note names, accidentals and anything with a digit,
+// must match exactly, so "D major" is not a typo of "C major" nor "Perfect
+// 4th" of "Perfect 5th"; the typo allowance only covers the other words.
+func matchText(response string, accepted []string, caseSensitive bool) textMatch {
+	got := Normalize(response, caseSensitive)
+	gotStrict, _ := splitStrict(got)
+	best := textMatch{}
+	bestDist := -1
+
+	for _, answer := range accepted {
+		want := Normalize(answer, caseSensitive)
+		if got == want {
+			return textMatch{Matched: true, Exact: true, Closest: answer}
+		}
+
+		wantStrict, rest := splitStrict(want)
+		dist := Levenshtein(got, want)
+		if bestDist < 0 || dist < bestDist {
+			bestDist = dist
+			matched := slices.Equal(gotStrict, wantStrict) && dist <= MaxTypos(rest)
+			best = textMatch{Matched: matched, Closest: answer}
+		}
+	}
+
+	return best
+}
+
+// accidentalWords are spelled-out accidentals, which are matched exactly.
+var accidentalWords = map[string]bool{
+	"sharp": true, "flat": true, "natural": true,
+	"double-sharp": true, "double-flat": true,
+}
+
+// splitStrict splits normalized text into the words that must match exactly
+// and the rest, rejoined by spaces.
+func splitStrict(s string) (strict []string, rest string) {
+	var loose []string
+	for _, word := range strings.Fields(s) {
+		if isStrictWord(word) {
+			strict = append(strict, word)
+		} else {
+			loose = append(loose, word)
+		}
+	}
+	return strict, strings.Join(loose, " ")
+}
+
+// isStrictWord reports whether a word is a note name such as "C", "Bb" or
+// "F#", an accidental, or contains a digit.
+func isStrictWord(word string) bool {
+	w := strings.ToLower(word)
+	if accidentalWords[w] || strings.ContainsAny(w, "0123456789♯♭♮𝄪𝄫#") {
+		return true
+	}
+	r := []rune(w)
+	if r[0] < 'a' || r[0] > 'g' {
+		return false
+	}
+	// A letter alone or followed by flats only: "c", "bb", "ebb"
+	return strings.Trim(string(r[1:]), "b") == ""
+}
+
+// hasContent reports whether a string contains any letters or digits.
+func hasContent(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}) >= 0
+}
@@ -0,0 +1,104 @@
+package grading
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name          string
+		in            string
+		caseSensitive bool
+		want          string
+	}{
+		{"collapses whitespace", "  C   major \t", false, "c major"},
+		{"drops trailing punctuation", "C major.!?", false, "c major"},
+		{"keeps inner punctuation", "C, E, G.", false, "c, e, g"},
+		{"keeps case when sensitive", "Bb Major", true, "Bb Major"},
+		{"empty", "   ", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.in, tt.caseSensitive); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"major", "major", 0},
+		{"major", "majr", 1},
+		{"major", "minor", 2},
+		{"♯", "♭", 1},
+	}
+	for _, tt := range tests {
+		if got := Levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMatchText(t *testing.T) {
+	tests := []struct {
+		name          string
+		response      string
+		accepted      []string
+		caseSensitive bool
+		matched       bool
+		exact         bool
+	}{
+		{"exact", "C major", []string{"C major"}, false, true, true},
+		{"exact ignoring case", "c MAJOR", []string{"C major"}, false, true, true},
+		{"case sensitive", "c major", []string{"C major"}, true, false, false},
+		{"typo in a word", "C majr", []string{"C major"}, false, true, false},
+		{"different note", "D major", []string{"C major"}, false, false, false},
+		{"added accidental", "C# major", []string{"C major"}, false, false, false},
+		{"flat note", "Eb major", []string{"E major"}, false, false, false},
+		{"spelled accidental", "C sharp major", []string{"C flat major"}, false, false, false},
+		{"different number", "Perfect 4th", []string{"Perfect 5th"}, false, false, false},
+		{"typo beside a number", "Perfct 5th", []string{"Perfect 5th"}, false, true, false},
+		{"major for minor", "A minor", []string{"A major"}, false, false, false},
+		{"short answer", "Em", []string{"Am"}, false, false, false},
+		{"too many typos", "Dimnshd", []string{"Diminished"}, false, false, false},
+		{"any accepted answer", "fifth", []string{"5th", "fifth"}, false, true, true},
+		{"no accepted answers", "C", nil, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := matchText(tt.response, tt.accepted, tt.caseSensitive)
+			if m.Matched != tt.matched || m.Exact != tt.exact {
+				t.Errorf("matchText(%q, %q) = matched %v exact %v, want %v %v",
+					tt.response, tt.accepted, m.Matched, m.Exact, tt.matched, tt.exact)
+			}
+		})
+	}
+}
+
+func TestIsStrictWord(t *testing.T) {
+	tests := []struct {
+		word string
+		want bool
+	}{
+		{"C", true},
+		{"bb", true},
+		{"F#", true},
+		{"E♭", true},
+		{"sharp", true},
+		{"7th", true},
+		{"Cmaj7", true},
+		{"major", false},
+		{"be", false},
+		{"perfect", false},
+	}
+	for _, tt := range tests {
+		if got := isStrictWord(tt.word); got != tt.want {
+			t.Errorf("isStrictWord(%q) = %v, want %v", tt.word, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package grading
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/validation"
+)
+
+func init() {
+	Register(model.ExerciseMultipleChoice, GraderFunc(gradeChoice))
+	Register(model.ExerciseAudioRecognition, GraderFunc(gradeChoice))
+	Register(model.ExercisePlayback, GraderFunc(gradePlayback))
+	Register(model.ExerciseFillInTheBlank, GraderFunc(gradeFillInTheBlank))
+	Register(model.ExerciseMatching, GraderFunc(gradeMatching))
+	Register(model.ExerciseTyping, GraderFunc(gradeTyping))
+}
+
+// gradeChoice handles multiple_choice and audio_recognition.
+//
+// Response: {"selected": "<option id or value>"} or {"selected": [...]}.
+// With several correct options, each correct pick earns a share of the score
+// and each wrong pick cancels one correct pick.
+func gradeChoice(
+	e *model.Exercise,
+	opts []model.ExerciseOption,
+	response model.JSONB,
+) (*Result, error) {
+	selected, err := selections(response["selected"])
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]model.ExerciseOption, len(opts)*2)
+	totalCorrect := 0
+	for _, o := range opts {
+		byKey[o.ID.String()] = o
+		byKey[o.Value] = o
+		if o.IsCorrect {
+			totalCorrect++
+		}
+	}
+	if totalCorrect == 0 {
+		return nil, noAnswerKey("exercise has no correct options")
+	}
+
+	res := &Result{}
+	picked := map[string]bool{}
+	right, wrong := 0, 0
+	for _, key := range selected {
+		o, ok := byKey[key]
+		if !ok {
+			return nil, invalid("unknown option %q", key)
+		}
+		if picked[o.ID.String()] {
+			continue
+		}
+		picked[o.ID.String()] = true
+
+		if o.IsCorrect {
+			right++
+		} else {
+			wrong++
+			res.Feedback = append(res.Feedback, fmt.Sprintf("%q is not correct", optionText(o)))
+		}
+	}
+
+	if !validation.AllowsMultiple(e) && len(picked) > 1 {
+		return nil, invalid("only one option may be selected")
+	}
+
+	res.Score = float64(right-wrong) / float64(totalCorrect)
+	res.IsCorrect = right == totalCorrect && wrong == 0
+	if !res.IsCorrect && right < totalCorrect {
+		for _, o := range opts {
+			if o.IsCorrect && !picked[o.ID.String()] {
+				res.Feedback = append(res.Feedback,
+					fmt.Sprintf("Correct answer: %q", optionText(o)))
+			}
+		}
+	}
+	return res, nil
+}
+
+// gradeTyping compares free text with metadata.accepted_answers, tolerating
+// small typos.
+//
+// Response: {"answer": "<text>"}.
+func gradeTyping(
+	e *model.Exercise,
+	_ []model.ExerciseOption,
+	response model.JSONB,
+) (*Result, error) {
+	answer, ok := response["answer"].(string)
+	if !ok {
+		return nil, invalid("answer must be a string")
+	}
+
+	accepted, _ := validation.StringList(e.Metadata["accepted_answers"])
+	if len(accepted) == 0 {
+		return nil, noAnswerKey("exercise has no accepted answers")
+	}
+	caseSensitive, _ := e.Metadata["case_sensitive"].(bool)
+
+	res := &Result{}
+	if !hasContent(answer) {
+		res.Feedback = append(res.Feedback, "No answer given")
+		res.Feedback = append(res.Feedback, fmt.Sprintf("Correct answer: %q", accepted[0]))
+		return res, nil
+	}
+
+	m := matchText(answer, accepted, caseSensitive)
+	switch {
+	case m.Exact:
+		res.IsCorrect, res.Score = true, 1
+	case m.Matched:
+		res.IsCorrect, res.Score = true, 1
+		res.Feedback = append(res.Feedback, fmt.Sprintf("Almost! Watch the spelling: %q", m.Closest))
+	default:
+		res.Feedback = append(res.Feedback, fmt.Sprintf("Correct answer: %q", accepted[0]))
+	}
+	return res, nil
+}
+
+// gradeFillInTheBlank checks each blank against its accepted alternatives.
+//
+// Response: {"answers": ["<blank 1>", "<blank 2>", ...]}.
+func gradeFillInTheBlank(
+	e *model.Exercise,
+	_ []model.ExerciseOption,
+	response model.JSONB,
+) (*Result, error) {
+	given, ok := validation.StringList(response["answers"])
+	if !ok {
+		return nil, invalid("answers must be a list of strings")
+	}
+
+	expected, _ := validation.BlankAnswers(e.Metadata["answers"])
+	if len(expected) == 0 {
+		return nil, noAnswerKey("exercise has no blank answers")
+	}
+	if len(given) != len(expected) {
+		return nil, invalid("expected %d answers, got %d", len(expected), len(given))
+	}
+	caseSensitive, _ := e.Metadata["case_sensitive"].(bool)
+
+	res := &Result{}
+	right := 0
+	for i, alts := range expected {
+		m := matchText(given[i], alts, caseSensitive)
+		switch {
+		case m.Exact:
+			right++
+		case m.Matched:
+			right++
+			res.Feedback = append(res.Feedback,
+				fmt.Sprintf("Blank %d: watch the spelling: %q", i+1, m.Closest))
+		default:
+			res.Feedback = append(res.Feedback,
+				fmt.Sprintf("Blank %d: correct answer is %q", i+1, alts[0]))
+		}
+	}
+
+	res.Score = float64(right) / float64(len(expected))
+	res.IsCorrect = right == len(expected)
+	return res, nil
+}
+
+// gradeMatching checks that each left option was matched with its partner.
+//
+// Response: {"pairs": {"<left option id>": "<right option id>", ...}}.
+func gradeMatching(
+	_ *model.Exercise,
+	opts []model.ExerciseOption,
+	response model.JSONB,
+) (*Result, error) {
+	raw, ok := response["pairs"].(map[string]any)
+	if !ok {
+		return nil, invalid("pairs must be an object mapping left option IDs to right option IDs")
+	}
+
+	pairs, errs := validation.PairOptions(opts)
+	if len(errs) > 0 || len(pairs) == 0 {
+		return nil, noAnswerKey("exercise options do not form valid pairs")
+	}
+
+	res := &Result{}
+	right := 0
+	for _, p := range pairs {
+		leftID := p.Left.ID.String()
+		picked, ok := raw[leftID].(string)
+		switch {
+		case !ok:
+			res.Feedback = append(res.Feedback,
+				fmt.Sprintf("%q was not matched", optionText(p.Left)))
+		case picked == p.Right.ID.String():
+			right++
+		default:
+			res.Feedback = append(res.Feedback,
+				fmt.Sprintf("%q should match %q", optionText(p.Left), optionText(p.Right)))
+		}
+	}
+
+	res.Score = float64(right) / float64(len(pairs))
+	res.IsCorrect = right == len(pairs)
+	return res, nil
+}
+
+// gradePlayback compares the played sequence with metadata.expected_sequence
+// note by note.
+//
+// Response: {"sequence": ["C4", "E4", ...]}.
+func gradePlayback(
+	e *model.Exercise,
+	_ []model.ExerciseOption,
+	response model.JSONB,
+) (*Result, error) {
+	played, ok := validation.StringList(response["sequence"])
+	if !ok {
+		return nil, invalid("sequence must be a list of strings")
+	}
+
+	expected, _ := validation.StringList(e.Metadata["expected_sequence"])
+	if len(expected) == 0 {
+		return nil, noAnswerKey("exercise has no expected sequence")
+	}
+
+	res := &Result{}
+	right := 0
+	for i, want := range expected {
+		if i >= len(played) {
+			res.Feedback = append(res.Feedback,
+				fmt.Sprintf("Missing %d of %d notes", len(expected)-i, len(expected)))
+			break
+		}
+		if strings.EqualFold(strings.TrimSpace(played[i]), strings.TrimSpace(want)) {
+			right++
+		} else {
+			res.Feedback = append(res.Feedback,
+				fmt.Sprintf("Note %d: expected %s, played %s", i+1, want, played[i]))
+		}
+	}
+	if extra := len(played) - len(expected); extra > 0 {
+		res.Feedback = append(res.Feedback, fmt.Sprintf("%d extra notes played", extra))
+		right = max(0, right-extra)
+	}
+
+	res.Score = float64(right) / float64(len(expected))
+	res.IsCorrect = right == len(expected) && len(played) == len(expected)
+	return res, nil
+}
+
+// selections reads a single choice or a list of choices from a response.
+func selections(v any) ([]string, error) {
+	if s, ok := v.(string); ok {
+		return []string{s}, nil
+	}
+	list, ok := validation.StringList(v)
+	if !ok || len(list) == 0 {
+		return nil, invalid("selected must be an option ID/value or a list of them")
+	}
+	return list, nil
+}
+
+func optionText(o model.ExerciseOption) string {
+	if o.Label != "" {
+		return o.Label
+	}
+	return o.Value
+}
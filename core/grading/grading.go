@@ -0,0 +1,84 @@
+package grading
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+)
+
+var (
+	// ErrInvalidResponse is returned when a learner response does not have
+	// the shape the exercise type expects.
+	ErrInvalidResponse = errors.New("invalid response")
+	// ErrNoAnswerKey is returned when the exercise lacks what its grader
+	// needs, e.g. an exercise whose options are not marked correct yet.
+	ErrNoAnswerKey = errors.New("exercise cannot be graded")
+)
+
+// Result is the outcome of grading one learner response.
+type Result struct {
+	IsCorrect bool     `json:"is_correct"`
+	Score     float64  `json:"score"`      // fraction of the answer that was right, 0–1
+	Points    int      `json:"points"`     // Score applied to Exercise.Points
+	MaxPoints int      `json:"max_points"` // Exercise.Points
+	Feedback  []string `json:"feedback,omitempty"`
+}
+
+// Grader scores responses for one ExerciseType.
+type Grader interface {
+	Grade(e *model.Exercise, opts []model.ExerciseOption, response model.JSONB) (*Result, error)
+}
+
+// GraderFunc adapts a plain function to the Grader interface.
+type GraderFunc func(e *model.Exercise, opts []model.ExerciseOption, response model.JSONB) (*Result, error)
+
+func (f GraderFunc) Grade(
+	e *model.Exercise,
+	opts []model.ExerciseOption,
+	response model.JSONB,
+) (*Result, error) {
+	return f(e, opts, response)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[model.ExerciseType]Grader{}
+)
+
+// Register installs the grader for an exercise type, replacing any existing one.
+func Register(t model.ExerciseType, g Grader) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[t] = g
+}
+
+// Grade scores a response using the grader registered for the exercise type.
+func Grade(e *model.Exercise, opts []model.ExerciseOption, response model.JSONB) (*Result, error) {
+	registryMu.RLock()
+	g, ok := registry[e.Type]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, noAnswerKey("no grader for exercise type %q", e.Type)
+	}
+
+	res, err := g.Grade(e, opts, response)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Score = math.Max(0, math.Min(1, res.Score))
+	res.MaxPoints = e.Points
+	res.Points = int(math.Round(res.Score * float64(e.Points)))
+	return res, nil
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidResponse, fmt.Sprintf(format, args...))
+}
+
+func noAnswerKey(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrNoAnswerKey, fmt.Sprintf(format, args...))
+}
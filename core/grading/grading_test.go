@@ -0,0 +1,214 @@
+package grading
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/google/uuid"
+)
+
+func option(value string, correct bool, order int) model.ExerciseOption {
+	return model.ExerciseOption{
+		ID:         uuid.New(),
+		Label:      value,
+		Value:      value,
+		IsCorrect:  correct,
+		OrderIndex: order,
+	}
+}
+
+func TestGrade(t *testing.T) {
+	c, e, g := option("C", true, 0), option("E", true, 1), option("F", false, 2)
+	left, right := option("pair", false, 0), option("pair", false, 1)
+
+	tests := []struct {
+		name      string
+		exercise  model.Exercise
+		opts      []model.ExerciseOption
+		response  model.JSONB
+		wantErr   error
+		isCorrect bool
+		score     float64
+		points    int
+	}{
+		{
+			name:      "choice by value",
+			exercise:  model.Exercise{Type: model.ExerciseMultipleChoice, Points: 10},
+			opts:      []model.ExerciseOption{c, g},
+			response:  model.JSONB{"selected": "C"},
+			isCorrect: true, score: 1, points: 10,
+		},
+		{
+			name:     "choice by ID, wrong",
+			exercise: model.Exercise{Type: model.ExerciseMultipleChoice, Points: 10},
+			opts:     []model.ExerciseOption{c, g},
+			response: model.JSONB{"selected": g.ID.String()},
+			score:    0, points: 0,
+		},
+		{
+			name: "multiple picks cancel out",
+			exercise: model.Exercise{Type: model.ExerciseMultipleChoice, Points: 4,
+				Metadata: model.JSONB{"allow_multiple": true}},
+			opts:     []model.ExerciseOption{c, e, g},
+			response: model.JSONB{"selected": []any{"C", "F"}},
+			score:    0, points: 0,
+		},
+		{
+			name: "half of multiple answers",
+			exercise: model.Exercise{Type: model.ExerciseMultipleChoice, Points: 4,
+				Metadata: model.JSONB{"allow_multiple": true}},
+			opts:     []model.ExerciseOption{c, e, g},
+			response: model.JSONB{"selected": []any{"E"}},
+			score:    0.5, points: 2,
+		},
+		{
+			name:     "several picks when one is allowed",
+			exercise: model.Exercise{Type: model.ExerciseMultipleChoice},
+			opts:     []model.ExerciseOption{c, e, g},
+			response: model.JSONB{"selected": []any{"C", "E"}},
+			wantErr:  ErrInvalidResponse,
+		},
+		{
+			name:     "unknown option",
+			exercise: model.Exercise{Type: model.ExerciseAudioRecognition},
+			opts:     []model.ExerciseOption{c, g},
+			response: model.JSONB{"selected": "G"},
+			wantErr:  ErrInvalidResponse,
+		},
+		{
+			name:     "empty selection",
+			exercise: model.Exercise{Type: model.ExerciseMultipleChoice},
+			opts:     []model.ExerciseOption{c, g},
+			response: model.JSONB{"selected": []any{}},
+			wantErr:  ErrInvalidResponse,
+		},
+		{
+			name:     "no correct options",
+			exercise: model.Exercise{Type: model.ExerciseMultipleChoice},
+			opts:     []model.ExerciseOption{g},
+			response: model.JSONB{"selected": "F"},
+			wantErr:  ErrNoAnswerKey,
+		},
+		{
+			name: "typing with a typo",
+			exercise: model.Exercise{Type: model.ExerciseTyping, Points: 5,
+				Metadata: model.JSONB{"accepted_answers": []any{"Perfect 5th"}}},
+			response:  model.JSONB{"answer": "perfct 5th"},
+			isCorrect: true, score: 1, points: 5,
+		},
+		{
+			name: "typing a different interval",
+			exercise: model.Exercise{Type: model.ExerciseTyping, Points: 5,
+				Metadata: model.JSONB{"accepted_answers": []any{"Perfect 5th"}}},
+			response: model.JSONB{"answer": "Perfect 4th"},
+		},
+		{
+			name: "typing a different key",
+			exercise: model.Exercise{Type: model.ExerciseTyping, Points: 5,
+				Metadata: model.JSONB{"accepted_answers": []any{"C major"}}},
+			response: model.JSONB{"answer": "D major"},
+		},
+		{
+			name: "typing nothing",
+			exercise: model.Exercise{Type: model.ExerciseTyping,
+				Metadata: model.JSONB{"accepted_answers": []any{"C"}}},
+			response: model.JSONB{"answer": " ?! "},
+		},
+		{
+			name: "typing a number",
+			exercise: model.Exercise{Type: model.ExerciseTyping,
+				Metadata: model.JSONB{"accepted_answers": []any{"C"}}},
+			response: model.JSONB{"answer": 3.0},
+			wantErr:  ErrInvalidResponse,
+		},
+		{
+			name:     "typing without answers",
+			exercise: model.Exercise{Type: model.ExerciseTyping, Metadata: model.JSONB{}},
+			response: model.JSONB{"answer": "C"},
+			wantErr:  ErrNoAnswerKey,
+		},
+		{
+			name: "blanks with alternatives",
+			exercise: model.Exercise{Type: model.ExerciseFillInTheBlank, Points: 2,
+				Metadata: model.JSONB{"answers": []any{"G", []any{"fifth", "5th"}}}},
+			response: model.JSONB{"answers": []any{"G", "6th"}},
+			score:    0.5, points: 1,
+		},
+		{
+			name: "blanks of the wrong count",
+			exercise: model.Exercise{Type: model.ExerciseFillInTheBlank,
+				Metadata: model.JSONB{"answers": []any{"G", "D"}}},
+			response: model.JSONB{"answers": []any{"G"}},
+			wantErr:  ErrInvalidResponse,
+		},
+		{
+			name:      "matching pair",
+			exercise:  model.Exercise{Type: model.ExerciseMatching, Points: 3},
+			opts:      []model.ExerciseOption{left, right},
+			response:  model.JSONB{"pairs": map[string]any{left.ID.String(): right.ID.String()}},
+			isCorrect: true, score: 1, points: 3,
+		},
+		{
+			name:     "matching left unmatched",
+			exercise: model.Exercise{Type: model.ExerciseMatching},
+			opts:     []model.ExerciseOption{left, right},
+			response: model.JSONB{"pairs": map[string]any{}},
+		},
+		{
+			name:     "matching without pairs",
+			exercise: model.Exercise{Type: model.ExerciseMatching},
+			opts:     []model.ExerciseOption{c},
+			response: model.JSONB{"pairs": map[string]any{}},
+			wantErr:  ErrNoAnswerKey,
+		},
+		{
+			name: "playback in order",
+			exercise: model.Exercise{Type: model.ExercisePlayback, Points: 3,
+				Metadata: model.JSONB{"expected_sequence": []any{"C4", "E4", "G4"}}},
+			response:  model.JSONB{"sequence": []any{"c4", " E4", "G4"}},
+			isCorrect: true, score: 1, points: 3,
+		},
+		{
+			name: "playback with extra notes",
+			exercise: model.Exercise{Type: model.ExercisePlayback, Points: 3,
+				Metadata: model.JSONB{"expected_sequence": []any{"C4", "E4", "G4"}}},
+			response: model.JSONB{"sequence": []any{"C4", "E4", "G4", "C5"}},
+			score:    2.0 / 3, points: 2,
+		},
+		{
+			name: "playback cut short",
+			exercise: model.Exercise{Type: model.ExercisePlayback, Points: 3,
+				Metadata: model.JSONB{"expected_sequence": []any{"C4", "E4", "G4"}}},
+			response: model.JSONB{"sequence": []any{"C4"}},
+			score:    1.0 / 3, points: 1,
+		},
+		{
+			name:     "unknown type",
+			exercise: model.Exercise{Type: "dictation"},
+			response: model.JSONB{},
+			wantErr:  ErrNoAnswerKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Grade(&tt.exercise, tt.opts, tt.response)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Grade() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Grade() error = %v", err)
+			}
+			if res.IsCorrect != tt.isCorrect || res.Score != tt.score || res.Points != tt.points {
+				t.Errorf("Grade() = correct %v score %v points %d, want %v %v %d",
+					res.IsCorrect, res.Score, res.Points, tt.isCorrect, tt.score, tt.points)
+			}
+			if res.MaxPoints != tt.exercise.Points {
+				t.Errorf("MaxPoints = %d, want %d", res.MaxPoints, tt.exercise.Points)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// Package imaging makes the resized variants of uploaded images that small
+// screens are served instead of the original. Variants are re-encoded from
+// decoded pixels, so EXIF and other metadata are dropped; the EXIF
+// orientation of JPEGs is applied first so nothing ends up sideways. Strip
+// removes the same metadata from the original file without re-encoding it.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // register decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register decoder
+)
+
+// ErrUnsupported is returned for image formats the package cannot decode,
+// such as SVG.
+var ErrUnsupported = errors.New("unsupported image format")
+
+// MaxPixels caps the size of images that are decoded, so a small file
+// cannot claim huge dimensions and exhaust memory. It allows 24-megapixel
+// photos, which decode to at most about 100 MB.
+const MaxPixels = 25_000_000
+
+// Size is a variant size: images are scaled down, never up, to fit within
+// MaxEdge pixels on their longest side.
+type Size struct {
+	Name    string
+	MaxEdge int
+}
+
+// Sizes are the variants made of every image, smallest first.
+var Sizes = []Size{
+	{Name: "thumbnail", MaxEdge: 256},
+	{Name: "mobile", MaxEdge: 1080},
+	{Name: "full", MaxEdge: 2048},
+}
+
+// Variant formats. Every size is made as PNG for PNG, GIF and transparent
+// images and as JPEG for the rest.
+const (
+	FormatPNG  = "png"
+	FormatJPEG = "jpeg"
+)
+
+// jpegQuality is the quality of JPEG variants.
+const jpegQuality = 85
+
+// Variant is one encoded size and format of an image.
+type Variant struct {
+	Size   string
+	Format string
+	Width  int
+	Height int
+	Data   []byte
+}
+
+// ContentType returns the MIME type of the variant's format.
+func (v Variant) ContentType() string {
+	return "image/" + v.Format
+}
+
+// Ext returns the file extension of the variant's format.
+func (v Variant) Ext() string {
+	if v.Format == FormatJPEG {
+		return ".jpg"
+	}
+	return "." + v.Format
+}
+
+// Result is an image's upright dimensions and its variants.
+type Result struct {
+	Width    int
+	Height   int
+	Variants []Variant
+}
+
+// Process decodes an image with the given extension (".jpg", ".jpeg",
+// ".png", ".gif" or ".webp"; GIFs use their first frame) and makes its
+// variants. It returns ErrUnsupported for other formats.
+func Process(r io.ReadSeeker, ext string) (*Result, error) {
+	switch ext {
+	case ".jpg", ".jpeg", ".png", ".gif", ".webp":
+	default:
+		return nil, ErrUnsupported
+	}
+
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, errors.New("image has no pixels")
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxPixels {
+		return nil, fmt.Errorf("image is larger than %d megapixels", MaxPixels/1_000_000)
+	}
+
+	orientation := 1
+	if ext == ".jpg" || ext == ".jpeg" {
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		orientation = jpegOrientation(r)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	src, format, err := image.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// The largest size is scaled straight from the decoded image and only
+	// then turned upright, so no full-size copy is made; fitting within a
+	// square does not depend on which way the image is turned. Each smaller
+	// size is scaled from the next larger one, which is much faster than
+	// scaling a large original three times.
+	scaled := make([]*image.NRGBA, len(Sizes))
+	last := len(Sizes) - 1
+	scaled[last] = orient(fit(src, Sizes[last].MaxEdge), orientation)
+	for i := last - 1; i >= 0; i-- {
+		scaled[i] = fit(scaled[i+1], Sizes[i].MaxEdge)
+	}
+
+	f := FormatJPEG
+	if format == "png" || format == "gif" || !scaled[last].Opaque() {
+		f = FormatPNG
+	}
+
+	res := &Result{Width: cfg.Width, Height: cfg.Height}
+	if orientation >= 5 {
+		res.Width, res.Height = cfg.Height, cfg.Width
+	}
+	for i, size := range Sizes {
+		sized := scaled[i]
+		data, err := encode(sized, f)
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s %s: %w", size.Name, f, err)
+		}
+		res.Variants = append(res.Variants, Variant{
+			Size:   size.Name,
+			Format: f,
+			Width:  sized.Bounds().Dx(),
+			Height: sized.Bounds().Dy(),
+			Data:   data,
+		})
+	}
+	return res, nil
+}
+
+// fit returns img as NRGBA, scaled down to fit within maxEdge pixels on its
+// longest side.
+func fit(img image.Image, maxEdge int) *image.NRGBA {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxEdge && h <= maxEdge {
+		if n, ok := img.(*image.NRGBA); ok && b.Min == (image.Point{}) {
+			return n
+		}
+		dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+		draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+		return dst
+	}
+	if w >= h {
+		w, h = maxEdge, max(1, h*maxEdge/w)
+	} else {
+		w, h = max(1, w*maxEdge/h), maxEdge
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+func encode(img *image.NRGBA, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case FormatPNG:
+		err = png.Encode(&buf, img)
+	case FormatJPEG:
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+	return buf.Bytes(), err
+}
@@ -0,0 +1,419 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// testImage returns a w×h image, opaque unless alpha is below 255.
+func testImage(w, h int, alpha uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: alpha})
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	if err := jpeg.Encode(&b, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func encodeGIF(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	if err := gif.Encode(&b, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+// jpegSegment returns a JPEG marker segment with the given body.
+func jpegSegment(marker byte, body []byte) []byte {
+	seg := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(body)+2))
+	return append(seg, body...)
+}
+
+// withJPEGSegments inserts segments right after the SOI marker.
+func withJPEGSegments(data []byte, segments ...[]byte) []byte {
+	out := append([]byte{}, data[:2]...)
+	for _, s := range segments {
+		out = append(out, s...)
+	}
+	return append(out, data[2:]...)
+}
+
+// exifWithOrientation returns the body of an APP1 EXIF segment with an
+// orientation and some extra data after the IFD, e.g. GPS tags.
+func exifWithOrientation(orientation int, extra string) []byte {
+	seg := orientationSegment(orientation)
+	return append(seg[4:], extra...)
+}
+
+// pngChunk returns a PNG chunk.
+func pngChunk(kind string, data []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, kind...)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+// withPNGChunks inserts chunks after the IHDR chunk.
+func withPNGChunks(data []byte, chunks ...[]byte) []byte {
+	const afterIHDR = 8 + 25
+	out := append([]byte{}, data[:afterIHDR]...)
+	for _, c := range chunks {
+		out = append(out, c...)
+	}
+	return append(out, data[afterIHDR:]...)
+}
+
+func TestProcess(t *testing.T) {
+	rotated := withJPEGSegments(encodeJPEG(t, testImage(300, 100, 255)),
+		jpegSegment(0xE1, exifWithOrientation(6, "")))
+
+	tests := []struct {
+		name   string
+		file   []byte
+		ext    string
+		width  int
+		height int
+		format string
+		sizes  [][2]int
+	}{
+		{
+			name: "large JPEG", file: encodeJPEG(t, testImage(3000, 1500, 255)), ext: ".jpg",
+			width: 3000, height: 1500, format: FormatJPEG,
+			sizes: [][2]int{{256, 128}, {1080, 540}, {2048, 1024}},
+		},
+		{
+			name: "small PNG is not scaled up", file: encodePNG(t, testImage(200, 100, 255)), ext: ".png",
+			width: 200, height: 100, format: FormatPNG,
+			sizes: [][2]int{{200, 100}, {200, 100}, {200, 100}},
+		},
+		{
+			name: "JPEG turned by EXIF", file: rotated, ext: ".jpeg",
+			width: 100, height: 300, format: FormatJPEG,
+			sizes: [][2]int{{85, 256}, {100, 300}, {100, 300}},
+		},
+		{
+			name: "GIF", file: encodeGIF(t, testImage(400, 300, 255)), ext: ".gif",
+			width: 400, height: 300, format: FormatPNG,
+			sizes: [][2]int{{256, 192}, {400, 300}, {400, 300}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Process(bytes.NewReader(tt.file), tt.ext)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			if res.Width != tt.width || res.Height != tt.height {
+				t.Errorf("size = %dx%d, want %dx%d", res.Width, res.Height, tt.width, tt.height)
+			}
+			if len(res.Variants) != len(Sizes) {
+				t.Fatalf("got %d variants, want %d", len(res.Variants), len(Sizes))
+			}
+			for i, v := range res.Variants {
+				if v.Size != Sizes[i].Name || v.Format != tt.format {
+					t.Errorf("variant %d is %s %s, want %s %s", i, v.Size, v.Format, Sizes[i].Name, tt.format)
+				}
+				if v.Width != tt.sizes[i][0] || v.Height != tt.sizes[i][1] {
+					t.Errorf("%s is %dx%d, want %dx%d", v.Size, v.Width, v.Height, tt.sizes[i][0], tt.sizes[i][1])
+				}
+				cfg, format, err := image.DecodeConfig(bytes.NewReader(v.Data))
+				if err != nil || format != v.Format || cfg.Width != v.Width || cfg.Height != v.Height {
+					t.Errorf("%s decodes as %s %dx%d (%v)", v.Size, format, cfg.Width, cfg.Height, err)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessTransparentJPEGFallback(t *testing.T) {
+	res, err := Process(bytes.NewReader(encodePNG(t, testImage(10, 10, 100))), ".png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Variants[0].Format != FormatPNG || res.Variants[0].Ext() != ".png" {
+		t.Errorf("transparent image variant is %s", res.Variants[0].Format)
+	}
+}
+
+func TestProcessMalformed(t *testing.T) {
+	valid := encodePNG(t, testImage(10, 10, 255))
+	// The IHDR claims 100000×100000 pixels
+	huge := bytes.Clone(valid)
+	binary.BigEndian.PutUint32(huge[16:], 100000)
+	binary.BigEndian.PutUint32(huge[20:], 100000)
+	copy(huge[29:33], binary.BigEndian.AppendUint32(nil, crc32.ChecksumIEEE(huge[12:29])))
+
+	tests := []struct {
+		name        string
+		file        []byte
+		ext         string
+		unsupported bool
+	}{
+		{"SVG", []byte("<svg/>"), ".svg", true},
+		{"empty", nil, ".png", false},
+		{"garbage", []byte("not an image at all"), ".jpg", false},
+		{"truncated PNG", valid[:len(valid)/2], ".png", false},
+		{"truncated JPEG", encodeJPEG(t, testImage(64, 64, 255))[:200], ".jpg", false},
+		{"too many pixels", huge, ".png", false},
+		{"WebP header only", []byte("RIFF\x04\x00\x00\x00WEBP"), ".webp", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Process(bytes.NewReader(tt.file), tt.ext)
+			if err == nil {
+				t.Fatal("Process() succeeded, want an error")
+			}
+			if errors.Is(err, ErrUnsupported) != tt.unsupported {
+				t.Errorf("Process() error = %v, unsupported %v", err, tt.unsupported)
+			}
+		})
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	plain := encodeJPEG(t, testImage(8, 8, 255))
+	littleEndian := []byte("Exif\x00\x00II\x2A\x00\x08\x00\x00\x00\x01\x00\x12\x01\x03\x00\x01\x00\x00\x00\x03\x00\x00\x00\x00\x00\x00\x00")
+
+	tests := []struct {
+		name string
+		file []byte
+		want int
+	}{
+		{"none", plain, 1},
+		{"big-endian", withJPEGSegments(plain, jpegSegment(0xE1, exifWithOrientation(8, ""))), 8},
+		{"little-endian", withJPEGSegments(plain, jpegSegment(0xE1, littleEndian)), 3},
+		{"out of range", withJPEGSegments(plain, jpegSegment(0xE1, exifWithOrientation(9, ""))), 1},
+		{"truncated EXIF", withJPEGSegments(plain, jpegSegment(0xE1, []byte("Exif\x00\x00MM"))), 1},
+		{"IFD past the end", withJPEGSegments(plain,
+			jpegSegment(0xE1, []byte("Exif\x00\x00MM\x00\x2A\x00\x00\xFF\xFF"))), 1},
+		{"not a JPEG", []byte("GIF89a"), 1},
+		{"bad segment length", []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x01}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(bytes.NewReader(tt.file)); got != tt.want {
+				t.Errorf("jpegOrientation() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// A 2×1 image: red, then blue
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	red, blue := color.NRGBA{R: 255, A: 255}, color.NRGBA{B: 255, A: 255}
+	img.SetNRGBA(0, 0, red)
+	img.SetNRGBA(1, 0, blue)
+
+	tests := []struct {
+		orientation int
+		w, h        int
+		first       color.NRGBA // top left pixel
+	}{
+		{1, 2, 1, red},
+		{2, 2, 1, blue},
+		{3, 2, 1, blue},
+		{6, 1, 2, red},
+		{8, 1, 2, blue},
+	}
+	for _, tt := range tests {
+		got := orient(img, tt.orientation)
+		b := got.Bounds()
+		if b.Dx() != tt.w || b.Dy() != tt.h || got.NRGBAAt(0, 0) != tt.first {
+			t.Errorf("orient(%d) = %dx%d starting %v, want %dx%d starting %v",
+				tt.orientation, b.Dx(), b.Dy(), got.NRGBAAt(0, 0), tt.w, tt.h, tt.first)
+		}
+	}
+}
+
+func TestStrip(t *testing.T) {
+	jpg := encodeJPEG(t, testImage(40, 30, 255))
+	pngFile := encodePNG(t, testImage(40, 30, 255))
+	gifFile := encodeGIF(t, testImage(40, 30, 255))
+	comment := append([]byte{0x21, 0xFE, 6}, "secret\x00"...)
+	xmpGIF := append(append([]byte{0x21, 0xFF, 11}, "XMP DataXMP"...), 6)
+	xmpGIF = append(xmpGIF, "secret\x00"...)
+	loop := append(append([]byte{0x21, 0xFF, 11}, "NETSCAPE2.0"...), 3, 1, 0, 0, 0)
+	gifTail := 13 + 3<<(gifFile[10]&0x07+1)
+
+	tests := []struct {
+		name        string
+		file        []byte
+		ext         string
+		orientation int    // JPEG orientation kept, if any
+		kept        string // metadata that must survive
+	}{
+		{
+			name: "JPEG EXIF, XMP, IPTC and comments",
+			file: withJPEGSegments(jpg,
+				jpegSegment(0xE1, exifWithOrientation(6, "GPS secret")),
+				jpegSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00secret")),
+				jpegSegment(0xED, []byte("Photoshop 3.0\x00secret")),
+				jpegSegment(0xFE, []byte("secret"))),
+			ext:         ".jpg",
+			orientation: 6,
+		},
+		{
+			name: "JPEG ICC profile",
+			file: withJPEGSegments(jpg, jpegSegment(0xE2, []byte("ICC_PROFILE\x00\x01\x01keep"))),
+			ext:  ".jpeg",
+			kept: "ICC_PROFILE",
+		},
+		{
+			name: "JPEG trailer",
+			file: append(bytes.Clone(jpg), "secret"...),
+			ext:  ".jpg",
+		},
+		{
+			name: "PNG text and EXIF",
+			file: withPNGChunks(pngFile,
+				pngChunk("tEXt", []byte("Comment\x00secret")),
+				pngChunk("iTXt", []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00secret")),
+				pngChunk("eXIf", []byte("MM\x00\x2Asecret")),
+				pngChunk("tIME", []byte{7, 234, 1, 1, 0, 0, 0}),
+				pngChunk("gAMA", []byte{0, 0, 177, 143})),
+			ext:  ".png",
+			kept: "gAMA",
+		},
+		{
+			name: "GIF comment and XMP",
+			file: append(append(append(append(bytes.Clone(gifFile[:gifTail]), loop...), comment...),
+				xmpGIF...), gifFile[gifTail:]...),
+			ext:  ".gif",
+			kept: "NETSCAPE2.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Strip(bytes.NewReader(tt.file), tt.ext)
+			if err != nil {
+				t.Fatalf("Strip() error = %v", err)
+			}
+			if bytes.Contains(out, []byte("secret")) {
+				t.Error("metadata was not stripped")
+			}
+			if tt.kept != "" && !bytes.Contains(out, []byte(tt.kept)) {
+				t.Errorf("%s was stripped", tt.kept)
+			}
+			if tt.orientation != 0 {
+				if got := jpegOrientation(bytes.NewReader(out)); got != tt.orientation {
+					t.Errorf("orientation = %d, want %d", got, tt.orientation)
+				}
+			}
+
+			want, _, err := image.Decode(bytes.NewReader(tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _, err := image.Decode(bytes.NewReader(out))
+			if err != nil {
+				t.Fatalf("stripped image does not decode: %v", err)
+			}
+			if got.Bounds() != want.Bounds() || got.At(7, 5) != want.At(7, 5) {
+				t.Error("stripped image has different pixels")
+			}
+		})
+	}
+}
+
+func TestStripWebP(t *testing.T) {
+	chunk := func(kind string, data []byte) []byte {
+		c := append([]byte(kind), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+		c = append(c, data...)
+		if len(data)%2 == 1 {
+			c = append(c, 0)
+		}
+		return c
+	}
+	var body []byte
+	body = append(body, chunk("VP8X", []byte{0x08 | 0x04 | 0x10, 0, 0, 0, 9, 0, 0, 9, 0, 0})...)
+	body = append(body, chunk("ALPH", []byte("alpha"))...)
+	body = append(body, chunk("EXIF", []byte("secret"))...)
+	body = append(body, chunk("XMP ", []byte("secret!"))...)
+	file := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(4+len(body)))...)
+	file = append(append(file, "WEBP"...), body...)
+
+	out, err := Strip(bytes.NewReader(file), ".webp")
+	if err != nil {
+		t.Fatalf("Strip() error = %v", err)
+	}
+	if bytes.Contains(out, []byte("secret")) {
+		t.Error("metadata was not stripped")
+	}
+	if !bytes.Contains(out, []byte("alpha")) {
+		t.Error("image chunks were dropped")
+	}
+	if got := binary.LittleEndian.Uint32(out[4:8]); int(got) != len(out)-8 {
+		t.Errorf("RIFF size = %d, want %d", got, len(out)-8)
+	}
+	if flags := out[20]; flags != 0x10 {
+		t.Errorf("VP8X flags = %#x, want 0x10", flags)
+	}
+}
+
+func TestStripMalformed(t *testing.T) {
+	jpg := encodeJPEG(t, testImage(16, 16, 255))
+	pngFile := encodePNG(t, testImage(16, 16, 255))
+	gifFile := encodeGIF(t, testImage(16, 16, 255))
+
+	tests := []struct {
+		name        string
+		file        []byte
+		ext         string
+		unsupported bool
+	}{
+		{"SVG", []byte("<svg/>"), ".svg", true},
+		{"empty JPEG", nil, ".jpg", false},
+		{"JPEG without EOI", jpg[:len(jpg)-2], ".jpg", false},
+		{"JPEG segment past the end", []byte{0xFF, 0xD8, 0xFF, 0xE1, 0xFF, 0xFF, 0}, ".jpg", false},
+		{"JPEG segment length too small", []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x01}, ".jpg", false},
+		{"PNG signature only", pngFile[:8], ".png", false},
+		{"PNG without IEND", pngFile[:len(pngFile)-12], ".png", false},
+		{"PNG chunk past the end", append(bytes.Clone(pngFile[:8]), 0x7F, 0xFF, 0xFF, 0xFF, 'I', 'D', 'A', 'T'), ".png", false},
+		{"GIF header only", gifFile[:6], ".gif", false},
+		{"GIF without trailer", gifFile[:len(gifFile)-1], ".gif", false},
+		{"GIF unknown block", append(bytes.Clone(gifFile[:len(gifFile)-1]), 0x99), ".gif", false},
+		{"not WebP", []byte("RIFF\x04\x00\x00\x00WAVE"), ".webp", false},
+		{"WebP chunk past the end", []byte("RIFF\x10\x00\x00\x00WEBPVP8 \xFF\x00\x00\x00"), ".webp", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Strip(bytes.NewReader(tt.file), tt.ext)
+			if err == nil {
+				t.Fatal("Strip() succeeded, want an error")
+			}
+			if errors.Is(err, ErrUnsupported) != tt.unsupported {
+				t.Errorf("Strip() error = %v, unsupported %v", err, tt.unsupported)
+			}
+		})
+	}
+}
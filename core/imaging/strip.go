@@ -0,0 +1,302 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Strip returns an image file with the same pixels but without EXIF, XMP,
+// IPTC, comments and other text metadata, which can give away where and
+// when a photo was taken. The image data is copied, not re-encoded. A JPEG
+// keeps its EXIF orientation, as the only field of a new EXIF block, so it
+// still shows upright. ext is as for Process; other formats return
+// ErrUnsupported.
+func Strip(r io.Reader, ext string) ([]byte, error) {
+	var strip func([]byte) ([]byte, error)
+	switch ext {
+	case ".jpg", ".jpeg":
+		strip = stripJPEG
+	case ".png":
+		strip = stripPNG
+	case ".gif":
+		strip = stripGIF
+	case ".webp":
+		strip = stripWebP
+	default:
+		return nil, ErrUnsupported
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return strip(data)
+}
+
+func malformed(format string) error {
+	return fmt.Errorf("malformed %s file", format)
+}
+
+// stripJPEG keeps the segments a decoder needs, the JFIF header, ICC color
+// profile and Adobe color transform, and drops every other application
+// segment, comments and anything after the end of the image.
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, malformed("JPEG")
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2])
+
+	orientation := jpegOrientation(bytes.NewReader(data))
+	wroteOrientation := orientation <= 1
+	i := 2
+	for {
+		if i+2 > len(data) || data[i] != 0xFF {
+			return nil, malformed("JPEG")
+		}
+		// Any number of 0xFF may pad a marker
+		if data[i+1] == 0xFF {
+			i++
+			continue
+		}
+		marker := data[i+1]
+		if marker == 0xD9 {
+			out.Write(data[i : i+2])
+			return out.Bytes(), nil
+		}
+		if marker >= 0xD0 && marker <= 0xD7 || marker == 0x01 {
+			// Markers without a segment
+			out.Write(data[i : i+2])
+			i += 2
+			continue
+		}
+
+		if i+4 > len(data) {
+			return nil, malformed("JPEG")
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:i+4]))
+		if end < i+4 || end > len(data) {
+			return nil, malformed("JPEG")
+		}
+		segment := data[i:end]
+		i = end
+
+		// EXIF goes right after the JFIF header, if there is one
+		isJFIF := marker == 0xE0 && bytes.HasPrefix(segment[4:], []byte("JFIF\x00"))
+		if !wroteOrientation && !isJFIF {
+			out.Write(orientationSegment(orientation))
+			wroteOrientation = true
+		}
+		if keepJPEGSegment(marker, segment[4:]) {
+			out.Write(segment)
+		}
+
+		if marker == 0xDA {
+			// Entropy-coded data runs up to the next marker that is not a
+			// restart marker; 0xFF in the data is followed by 0x00
+			start := i
+			for ; i+1 < len(data); i++ {
+				if data[i] == 0xFF && data[i+1] != 0x00 && (data[i+1] < 0xD0 || data[i+1] > 0xD7) {
+					break
+				}
+			}
+			if i+1 >= len(data) {
+				return nil, malformed("JPEG")
+			}
+			out.Write(data[start:i])
+		}
+	}
+}
+
+// keepJPEGSegment reports whether a segment with the given marker and body
+// is kept by stripJPEG.
+func keepJPEGSegment(marker byte, body []byte) bool {
+	switch {
+	case marker == 0xE0:
+		return bytes.HasPrefix(body, []byte("JFIF\x00")) || bytes.HasPrefix(body, []byte("JFXX\x00"))
+	case marker == 0xE2:
+		return bytes.HasPrefix(body, []byte("ICC_PROFILE\x00"))
+	case marker == 0xEE:
+		return bytes.HasPrefix(body, []byte("Adobe"))
+	case marker >= 0xE1 && marker <= 0xEF, marker == 0xFE:
+		return false
+	default:
+		return true
+	}
+}
+
+// orientationSegment returns an APP1 segment holding an EXIF block with
+// only the orientation tag.
+func orientationSegment(orientation int) []byte {
+	var b bytes.Buffer
+	b.Write([]byte{0xFF, 0xE1, 0, 34})
+	b.WriteString("Exif\x00\x00")
+	// Big-endian TIFF header pointing at the IFD right after it
+	b.WriteString("MM\x00\x2A")
+	binary.Write(&b, binary.BigEndian, uint32(8))
+	// One entry: tag, type SHORT, count 1, value padded to four bytes
+	binary.Write(&b, binary.BigEndian, uint16(1))
+	binary.Write(&b, binary.BigEndian, uint16(orientationTag))
+	binary.Write(&b, binary.BigEndian, uint16(3))
+	binary.Write(&b, binary.BigEndian, uint32(1))
+	binary.Write(&b, binary.BigEndian, uint16(orientation))
+	binary.Write(&b, binary.BigEndian, uint16(0))
+	// No further IFD
+	binary.Write(&b, binary.BigEndian, uint32(0))
+	return b.Bytes()
+}
+
+// pngMetadata are the PNG chunks stripPNG drops.
+var pngMetadata = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+func stripPNG(data []byte) ([]byte, error) {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return nil, malformed("PNG")
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.WriteString(signature)
+
+	for i := len(signature); ; {
+		// Length, type, data and CRC
+		if i+12 > len(data) {
+			return nil, malformed("PNG")
+		}
+		size := int64(binary.BigEndian.Uint32(data[i : i+4]))
+		if size > int64(len(data)-i-12) {
+			return nil, malformed("PNG")
+		}
+		end := i + 12 + int(size)
+		kind := string(data[i+4 : i+8])
+		if !pngMetadata[kind] {
+			out.Write(data[i:end])
+		}
+		i = end
+		if kind == "IEND" {
+			return out.Bytes(), nil
+		}
+	}
+}
+
+// stripGIF drops comments and application extensions other than the ones
+// that make animations loop.
+func stripGIF(data []byte) ([]byte, error) {
+	if len(data) < 13 || !bytes.HasPrefix(data, []byte("GIF8")) {
+		return nil, malformed("GIF")
+	}
+	i := 13
+	if data[10]&0x80 != 0 {
+		i += 3 << (data[10]&0x07 + 1) // global color table
+	}
+	if i > len(data) {
+		return nil, malformed("GIF")
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:i])
+
+	for i < len(data) {
+		start := i
+		keep := true
+		switch data[i] {
+		case 0x3B: // trailer
+			out.WriteByte(0x3B)
+			return out.Bytes(), nil
+		case 0x2C: // image descriptor
+			if i+11 > len(data) {
+				return nil, malformed("GIF")
+			}
+			packed := data[i+9]
+			i += 10
+			if packed&0x80 != 0 {
+				i += 3 << (packed&0x07 + 1) // local color table
+			}
+			i++ // LZW minimum code size
+		case 0x21: // extension
+			if i+2 > len(data) {
+				return nil, malformed("GIF")
+			}
+			label := data[i+1]
+			i += 2
+			switch label {
+			case 0xFE: // comment
+				keep = false
+			case 0xFF: // application
+				keep = i+12 <= len(data) && data[i] == 11 &&
+					(string(data[i+1:i+12]) == "NETSCAPE2.0" || string(data[i+1:i+12]) == "ANIMEXTS1.0")
+			}
+		default:
+			return nil, malformed("GIF")
+		}
+
+		// Data sub-blocks, each prefixed by its size, end with an empty one
+		for {
+			if i >= len(data) {
+				return nil, malformed("GIF")
+			}
+			n := int(data[i])
+			i += 1 + n
+			if n == 0 {
+				break
+			}
+		}
+		if i > len(data) {
+			return nil, malformed("GIF")
+		}
+		if keep {
+			out.Write(data[start:i])
+		}
+	}
+	return nil, malformed("GIF")
+}
+
+// stripWebP drops the EXIF and XMP chunks and clears their flags in the
+// extended header.
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, malformed("WebP")
+	}
+	riffEnd := min(len(data), 8+int(binary.LittleEndian.Uint32(data[4:8])))
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:12])
+
+	for i := 12; i < riffEnd; {
+		if i+8 > riffEnd {
+			return nil, malformed("WebP")
+		}
+		size := int64(binary.LittleEndian.Uint32(data[i+4 : i+8]))
+		// Chunks are padded to an even size
+		end := int64(i) + 8 + size + size%2
+		if end > int64(riffEnd) {
+			if end-size%2 != int64(riffEnd) {
+				return nil, malformed("WebP")
+			}
+			end = int64(riffEnd)
+		}
+		chunk := data[i:end]
+		i = int(end)
+
+		switch string(chunk[0:4]) {
+		case "EXIF", "XMP ":
+			continue
+		case "VP8X":
+			if len(chunk) < 9 {
+				return nil, malformed("WebP")
+			}
+			chunk = bytes.Clone(chunk)
+			chunk[8] &^= 0x08 | 0x04 // EXIF and XMP flags
+		}
+		out.Write(chunk)
+	}
+
+	b := out.Bytes()
+	binary.LittleEndian.PutUint32(b[4:8], uint32(len(b)-8))
+	return b, nil
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"strings"
+	"time"
 
 	"github.com/bytebeatz/bandroom-cms/core/model"
 	"github.com/bytebeatz/bandroom-cms/core/repository"
@@ -22,6 +23,10 @@ func NewCoursePG(db *sql.DB) repository.CourseRepository {
 }
 
 func (r *coursePG) Create(ctx context.Context, c *model.Course) error {
+	return insertCourse(ctx, conn(ctx, r.db), c)
+}
+
+func insertCourse(ctx context.Context, db execer, c *model.Course) error {
 	tags := "{" + strings.Join(c.Tags, ",") + "}"
 	meta, _ := json.Marshal(c.Metadata)
 
@@ -34,13 +39,15 @@ func (r *coursePG) Create(ctx context.Context, c *model.Course) error {
 		$8, $9, $10, $11, $12, $13, $14
 	)
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := db.ExecContext(ctx, query,
 		c.ID, c.Slug, c.Title, c.Description, c.Language, c.Difficulty, c.IsPublished,
 		tags, meta, c.Version, c.DeletedAt, c.CreatedAt, c.UpdatedAt, c.CreatorID,
 	)
 	return err
 }
 
+// Update writes c only if the stored version still equals c.Version, then
+// bumps c.Version to the new stored version.
 func (r *coursePG) Update(ctx context.Context, c *model.Course) error {
 	tags := "{" + strings.Join(c.Tags, ",") + "}"
 	meta, _ := json.Marshal(c.Metadata)
@@ -49,41 +56,108 @@ func (r *coursePG) Update(ctx context.Context, c *model.Course) error {
 	UPDATE courses SET
 		slug = $2, title = $3, description = $4, language = $5,
 		difficulty = $6, is_published = $7, tags = $8, metadata = $9,
-		version = $10, deleted_at = $11, updated_at = $12, creator_id = $13
-	WHERE id = $1
+		version = version + 1, updated_at = $11, status = $12
+	WHERE id = $1 AND version = $10 AND deleted_at IS NULL
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
 		c.ID, c.Slug, c.Title, c.Description, c.Language,
 		c.Difficulty, c.IsPublished, tags, meta,
-		c.Version, c.DeletedAt, c.UpdatedAt, c.CreatorID,
+		c.Version, c.UpdatedAt, c.Status,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if err := checkVersionedUpdate(ctx, conn(ctx, r.db), "courses", c.ID, res); err != nil {
+		return err
+	}
+	c.Version++
+	return nil
 }
 
+// Delete soft-deletes the course together with its units, skills, lessons and exercises.
 func (r *coursePG) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM courses WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	return softDelete(ctx, r.db, "courses", id, courseCascade)
+}
+
+// Restore undoes Delete, bringing back the content that was deleted with the course.
+func (r *coursePG) Restore(ctx context.Context, id uuid.UUID) error {
+	return restore(ctx, r.db, "courses", id, "", courseCascade)
 }
 
-func (r *coursePG) GetByID(ctx context.Context, id uuid.UUID) (*model.Course, error) {
-	query := `SELECT id, slug, title, description, language, difficulty, is_published, tags, metadata, version, deleted_at, created_at, updated_at, creator_id FROM courses WHERE id = $1`
-	row := r.db.QueryRowContext(ctx, query, id)
+func (r *coursePG) GetByID(
+	ctx context.Context,
+	id uuid.UUID,
+	includeDeleted bool,
+) (*model.Course, error) {
+	query := `SELECT id, slug, title, description, language, difficulty, is_published, tags, metadata, version, status, deleted_at, created_at, updated_at, creator_id, publish_at, unpublish_at, publish_scheduled_by, unpublish_scheduled_by FROM courses WHERE id = $1 AND ($2 OR deleted_at IS NULL)`
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, id, includeDeleted)
 	return scanCourse(row)
 }
 
 func (r *coursePG) GetBySlug(ctx context.Context, slug string) (*model.Course, error) {
-	query := `SELECT id, slug, title, description, language, difficulty, is_published, tags, metadata, version, deleted_at, created_at, updated_at, creator_id FROM courses WHERE slug = $1`
-	row := r.db.QueryRowContext(ctx, query, slug)
+	query := `SELECT id, slug, title, description, language, difficulty, is_published, tags, metadata, version, status, deleted_at, created_at, updated_at, creator_id, publish_at, unpublish_at, publish_scheduled_by, unpublish_scheduled_by FROM courses WHERE slug = $1 AND deleted_at IS NULL`
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, slug)
 	return scanCourse(row)
 }
 
-func (r *coursePG) List(ctx context.Context, publishedOnly bool) ([]*model.Course, error) {
-	query := `SELECT id, slug, title, description, language, difficulty, is_published, tags, metadata, version, deleted_at, created_at, updated_at, creator_id FROM courses`
-	if publishedOnly {
-		query += ` WHERE is_published = TRUE`
+var courseListSpec = listSpec{
+	table:       "courses",
+	columns:     "id, slug, title, description, language, difficulty, is_published, tags, metadata, version, status, deleted_at, created_at, updated_at, creator_id, publish_at, unpublish_at, publish_scheduled_by, unpublish_scheduled_by",
+	defaultSort: "created_at",
+	sorts:       []string{"title", "created_at", "updated_at"},
+	filters:     []string{filterLanguage, filterDifficulty, filterIsPublished, filterTags, filterCreator, filterStatus},
+}
+
+func (r *coursePG) List(
+	ctx context.Context,
+	q repository.ListQuery,
+) (*repository.Page[*model.Course], error) {
+	return queryPage(ctx, conn(ctx, r.db), courseListSpec, q, scanCourse,
+		func(c *model.Course) (uuid.UUID, sortKeys) {
+			return c.ID, sortKeys{title: c.Title, createdAt: c.CreatedAt, updatedAt: c.UpdatedAt}
+		})
+}
+
+// ExistsByTitle checks if a course with the same title already exists (case-insensitive).
+func (r *coursePG) ExistsByTitle(ctx context.Context, title string) (bool, error) {
+	query := `SELECT EXISTS (
+		SELECT 1 FROM courses WHERE LOWER(title) = LOWER($1)
+	)`
+	var exists bool
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, title).Scan(&exists)
+	return exists, err
+}
+
+// SetSchedule writes the schedule fields only; schedules are not content, so
+// the version is left alone.
+func (r *coursePG) SetSchedule(ctx context.Context, c *model.Course) error {
+	query := `
+		UPDATE courses SET
+			publish_at = $2, unpublish_at = $3,
+			publish_scheduled_by = $4, unpublish_scheduled_by = $5
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
+		c.ID, c.PublishAt, c.UnpublishAt, c.PublishScheduledBy, c.UnpublishScheduledBy,
+	)
+	if err != nil {
+		return err
 	}
-	rows, err := r.db.QueryContext(ctx, query)
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *coursePG) ListDueSchedules(ctx context.Context, now time.Time) ([]*model.Course, error) {
+	query := `SELECT ` + courseListSpec.columns + ` FROM courses
+		WHERE deleted_at IS NULL AND (publish_at <= $1 OR unpublish_at <= $1)
+		ORDER BY LEAST(publish_at, unpublish_at), id`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, now)
 	if err != nil {
 		return nil, err
 	}
@@ -97,17 +171,23 @@ func (r *coursePG) List(ctx context.Context, publishedOnly bool) ([]*model.Cours
 		}
 		courses = append(courses, c)
 	}
-	return courses, nil
+	return courses, rows.Err()
 }
 
-// ExistsByTitle checks if a course with the same title already exists (case-insensitive).
-func (r *coursePG) ExistsByTitle(ctx context.Context, title string) (bool, error) {
-	query := `SELECT EXISTS (
-		SELECT 1 FROM courses WHERE LOWER(title) = LOWER($1)
-	)`
-	var exists bool
-	err := r.db.QueryRowContext(ctx, query, title).Scan(&exists)
-	return exists, err
+func (r *coursePG) ClearPublishAt(ctx context.Context, id uuid.UUID, at time.Time) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE courses SET publish_at = NULL, publish_scheduled_by = NULL
+		WHERE id = $1 AND publish_at = $2
+	`, id, at)
+	return err
+}
+
+func (r *coursePG) ClearUnpublishAt(ctx context.Context, id uuid.UUID, at time.Time) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE courses SET unpublish_at = NULL, unpublish_scheduled_by = NULL
+		WHERE id = $1 AND unpublish_at = $2
+	`, id, at)
+	return err
 }
 
 // scanCourse extracts a Course from a DB row.
@@ -129,10 +209,15 @@ func scanCourse(scanner interface {
 		pq.Array(&tags),
 		&metaRaw,
 		&c.Version,
+		&c.Status,
 		&c.DeletedAt,
 		&c.CreatedAt,
 		&c.UpdatedAt,
 		&c.CreatorID,
+		&c.PublishAt,
+		&c.UnpublishAt,
+		&c.PublishScheduledBy,
+		&c.UnpublishScheduledBy,
 	)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,154 @@
+package _interface
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/google/uuid"
+)
+
+type exerciseOptionPG struct {
+	db *sql.DB
+}
+
+// NewExerciseOptionPG returns a PostgreSQL-backed ExerciseOptionRepository.
+func NewExerciseOptionPG(db *sql.DB) repository.ExerciseOptionRepository {
+	return &exerciseOptionPG{db: db}
+}
+
+const insertExerciseOptionQuery = `
+	INSERT INTO exercise_options (
+		id, exercise_id, label, value, is_correct, media_url, order_index,
+		created_at, updated_at
+	) VALUES (
+		$1, $2, $3, $4, $5, $6, $7,
+		$8, $9
+	)
+`
+
+func (r *exerciseOptionPG) GetByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (*model.ExerciseOption, error) {
+	query := `
+		SELECT id, exercise_id, label, value, is_correct, media_url, order_index,
+		       created_at, updated_at
+		FROM exercise_options WHERE id = $1
+	`
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, id)
+	return scanExerciseOption(row)
+}
+
+func (r *exerciseOptionPG) ListByExerciseID(
+	ctx context.Context,
+	exerciseID uuid.UUID,
+) ([]*model.ExerciseOption, error) {
+	query := `
+		SELECT id, exercise_id, label, value, is_correct, media_url, order_index,
+		       created_at, updated_at
+		FROM exercise_options WHERE exercise_id = $1 ORDER BY order_index
+	`
+	return r.list(ctx, query, exerciseID)
+}
+
+func (r *exerciseOptionPG) ListForUpdate(
+	ctx context.Context,
+	exerciseID uuid.UUID,
+) ([]*model.ExerciseOption, error) {
+	if _, err := conn(ctx, r.db).ExecContext(ctx,
+		`SELECT id FROM exercises WHERE id = $1 FOR UPDATE`, exerciseID,
+	); err != nil {
+		return nil, err
+	}
+	return r.ListByExerciseID(ctx, exerciseID)
+}
+
+// ListByCourseID returns the options of every live exercise of a course,
+// ordered by exercise and position.
+func (r *exerciseOptionPG) ListByCourseID(
+	ctx context.Context,
+	courseID uuid.UUID,
+) ([]*model.ExerciseOption, error) {
+	query := `
+		SELECT o.id, o.exercise_id, o.label, o.value, o.is_correct, o.media_url, o.order_index,
+		       o.created_at, o.updated_at
+		FROM exercise_options o
+		JOIN exercises e ON e.id = o.exercise_id
+		WHERE e.skill_id IN (SELECT id FROM skills WHERE course_id = $1) AND e.deleted_at IS NULL
+		ORDER BY o.exercise_id, o.order_index
+	`
+	return r.list(ctx, query, courseID)
+}
+
+func (r *exerciseOptionPG) list(ctx context.Context, query string, args ...any) ([]*model.ExerciseOption, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var options []*model.ExerciseOption
+	for rows.Next() {
+		o, err := scanExerciseOption(rows)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, o)
+	}
+	return options, rows.Err()
+}
+
+func (r *exerciseOptionPG) ReplaceAll(
+	ctx context.Context,
+	exerciseID uuid.UUID,
+	options []*model.ExerciseOption,
+) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		// Lock the parent row so concurrent replaces for the same exercise serialize
+		if _, err := tx.ExecContext(ctx,
+			`SELECT id FROM exercises WHERE id = $1 FOR UPDATE`, exerciseID,
+		); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM exercise_options WHERE exercise_id = $1`, exerciseID,
+		); err != nil {
+			return err
+		}
+
+		for _, o := range options {
+			if _, err := tx.ExecContext(ctx, insertExerciseOptionQuery,
+				o.ID, exerciseID, o.Label, o.Value, o.IsCorrect, o.MediaURL, o.OrderIndex,
+				o.CreatedAt, o.UpdatedAt,
+			); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func scanExerciseOption(scanner interface {
+	Scan(dest ...any) error
+}) (*model.ExerciseOption, error) {
+	var o model.ExerciseOption
+	err := scanner.Scan(
+		&o.ID,
+		&o.ExerciseID,
+		&o.Label,
+		&o.Value,
+		&o.IsCorrect,
+		&o.MediaURL,
+		&o.OrderIndex,
+		&o.CreatedAt,
+		&o.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
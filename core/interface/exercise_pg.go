@@ -0,0 +1,285 @@
+package _interface
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/google/uuid"
+)
+
+type exercisePG struct {
+	db *sql.DB
+}
+
+// NewExercisePG returns a PostgreSQL-backed ExerciseRepository.
+func NewExercisePG(db *sql.DB) repository.ExerciseRepository {
+	return &exercisePG{db: db}
+}
+
+func (r *exercisePG) Create(ctx context.Context, e *model.Exercise) error {
+	return insertExercise(ctx, conn(ctx, r.db), e)
+}
+
+func (r *exercisePG) CreateMany(
+	ctx context.Context,
+	exercises []*model.Exercise,
+	options map[uuid.UUID][]*model.ExerciseOption,
+) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		for _, e := range exercises {
+			if err := insertExercise(ctx, tx, e); err != nil {
+				return err
+			}
+			for _, o := range options[e.ID] {
+				if _, err := tx.ExecContext(ctx, insertExerciseOptionQuery,
+					o.ID, e.ID, o.Label, o.Value, o.IsCorrect, o.MediaURL, o.OrderIndex,
+					o.CreatedAt, o.UpdatedAt,
+				); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func insertExercise(ctx context.Context, db execer, e *model.Exercise) error {
+	metadataJSON, err := json.Marshal(e.Metadata)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO exercises (
+			id, skill_id, lesson_id, title, type, matching_type, prompt, media_url,
+			order_index, points, grade, syllabus, objective_tag, metadata, version,
+			deleted_at, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8,
+			$9, $10, $11, $12, $13, $14, $15,
+			$16, $17, $18
+		)
+	`
+
+	_, err = db.ExecContext(ctx, query,
+		e.ID, e.SkillID, e.LessonID, e.Title, e.Type, e.MatchingType, e.Prompt, e.MediaURL,
+		e.OrderIndex, e.Points, e.Grade, e.Syllabus, e.ObjectiveTag, metadataJSON, e.Version,
+		e.DeletedAt, e.CreatedAt, e.UpdatedAt,
+	)
+	return err
+}
+
+// Update writes e only if the stored version still equals e.Version, then
+// bumps e.Version to the new stored version. The lesson, skill and position
+// are left alone; UpdateOrder moves exercises.
+func (r *exercisePG) Update(ctx context.Context, e *model.Exercise) error {
+	metadataJSON, err := json.Marshal(e.Metadata)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE exercises SET
+			title = $2, type = $3, matching_type = $4, prompt = $5, media_url = $6,
+			points = $7, grade = $8, syllabus = $9, objective_tag = $10, metadata = $11,
+			version = version + 1, updated_at = $12, status = $14
+		WHERE id = $1 AND version = $13 AND deleted_at IS NULL
+	`
+
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
+		e.ID, e.Title, e.Type, e.MatchingType, e.Prompt, e.MediaURL,
+		e.Points, e.Grade, e.Syllabus, e.ObjectiveTag, metadataJSON,
+		e.UpdatedAt, e.Version, e.Status,
+	)
+	if err != nil {
+		return err
+	}
+	if err := checkVersionedUpdate(ctx, conn(ctx, r.db), "exercises", e.ID, res); err != nil {
+		return err
+	}
+	e.Version++
+	return nil
+}
+
+// UpdateOrder writes the lesson_id, skill_id and order_index of each exercise in one transaction,
+// with the same version check as Update.
+func (r *exercisePG) UpdateOrder(ctx context.Context, exercises []*model.Exercise) error {
+	query := `
+		UPDATE exercises SET
+			lesson_id = $2, skill_id = $3, order_index = $4, version = version + 1, updated_at = $6
+		WHERE id = $1 AND version = $5 AND deleted_at IS NULL
+	`
+	return updateOrder(ctx, r.db, "exercises", query, exercises,
+		func(e *model.Exercise) (uuid.UUID, []any) {
+			return e.ID, []any{e.ID, e.LessonID, e.SkillID, e.OrderIndex, e.Version, e.UpdatedAt}
+		},
+		func(e *model.Exercise) { e.Version++ },
+	)
+}
+
+// Delete soft-deletes the exercise; its options are kept for a later restore.
+func (r *exercisePG) Delete(ctx context.Context, id uuid.UUID) error {
+	return softDelete(ctx, r.db, "exercises", id, nil)
+}
+
+// Restore undoes Delete as long as the owning lesson is not deleted.
+func (r *exercisePG) Restore(ctx context.Context, id uuid.UUID) error {
+	parentCheck := `
+		SELECT l.deleted_at IS NULL FROM exercises e
+		JOIN lessons l ON l.id = e.lesson_id
+		WHERE e.id = $1
+	`
+	return restore(ctx, r.db, "exercises", id, parentCheck, nil)
+}
+
+func (r *exercisePG) GetByID(
+	ctx context.Context,
+	id uuid.UUID,
+	includeDeleted bool,
+) (*model.Exercise, error) {
+	query := `
+		SELECT id, skill_id, lesson_id, title, type, matching_type, prompt, media_url,
+		       order_index, points, grade, syllabus, objective_tag, metadata, version, status,
+		       deleted_at, created_at, updated_at
+		FROM exercises WHERE id = $1 AND ($2 OR deleted_at IS NULL)
+	`
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, id, includeDeleted)
+	return scanExercise(row)
+}
+
+func (r *exercisePG) ListByLessonID(
+	ctx context.Context,
+	lessonID uuid.UUID,
+	includeDeleted bool,
+) ([]*model.Exercise, error) {
+	query := `
+		SELECT id, skill_id, lesson_id, title, type, matching_type, prompt, media_url,
+		       order_index, points, grade, syllabus, objective_tag, metadata, version, status,
+		       deleted_at, created_at, updated_at
+		FROM exercises WHERE lesson_id = $1 AND ($2 OR deleted_at IS NULL) ORDER BY order_index
+	`
+	return r.list(ctx, query, lessonID, includeDeleted)
+}
+
+func (r *exercisePG) ListBySkillID(
+	ctx context.Context,
+	skillID uuid.UUID,
+	includeDeleted bool,
+) ([]*model.Exercise, error) {
+	query := `
+		SELECT id, skill_id, lesson_id, title, type, matching_type, prompt, media_url,
+		       order_index, points, grade, syllabus, objective_tag, metadata, version, status,
+		       deleted_at, created_at, updated_at
+		FROM exercises WHERE skill_id = $1 AND ($2 OR deleted_at IS NULL) ORDER BY order_index
+	`
+	return r.list(ctx, query, skillID, includeDeleted)
+}
+
+func (r *exercisePG) ListByCourseID(
+	ctx context.Context,
+	courseID uuid.UUID,
+) ([]*model.Exercise, error) {
+	query := `
+		SELECT id, skill_id, lesson_id, title, type, matching_type, prompt, media_url,
+		       order_index, points, grade, syllabus, objective_tag, metadata, version, status,
+		       deleted_at, created_at, updated_at
+		FROM exercises
+		WHERE skill_id IN (SELECT id FROM skills WHERE course_id = $1) AND deleted_at IS NULL
+		ORDER BY order_index
+	`
+	return r.list(ctx, query, courseID)
+}
+
+func (r *exercisePG) CountByLessonForCourse(
+	ctx context.Context,
+	courseID uuid.UUID,
+) (map[uuid.UUID]int, error) {
+	query := `
+		SELECT lesson_id, COUNT(*) FROM exercises
+		WHERE skill_id IN (SELECT id FROM skills WHERE course_id = $1) AND deleted_at IS NULL
+		GROUP BY lesson_id
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[uuid.UUID]int{}
+	for rows.Next() {
+		var lessonID uuid.UUID
+		var n int
+		if err := rows.Scan(&lessonID, &n); err != nil {
+			return nil, err
+		}
+		counts[lessonID] = n
+	}
+	return counts, rows.Err()
+}
+
+func (r *exercisePG) list(ctx context.Context, query string, args ...any) ([]*model.Exercise, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exercises []*model.Exercise
+	for rows.Next() {
+		e, err := scanExercise(rows)
+		if err != nil {
+			return nil, err
+		}
+		exercises = append(exercises, e)
+	}
+	return exercises, rows.Err()
+}
+
+func scanExercise(scanner interface {
+	Scan(dest ...any) error
+}) (*model.Exercise, error) {
+	var e model.Exercise
+	var matchingType sql.NullString
+	var metadataBytes []byte
+
+	err := scanner.Scan(
+		&e.ID,
+		&e.SkillID,
+		&e.LessonID,
+		&e.Title,
+		&e.Type,
+		&matchingType,
+		&e.Prompt,
+		&e.MediaURL,
+		&e.OrderIndex,
+		&e.Points,
+		&e.Grade,
+		&e.Syllabus,
+		&e.ObjectiveTag,
+		&metadataBytes,
+		&e.Version,
+		&e.Status,
+		&e.DeletedAt,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if matchingType.Valid {
+		mt := model.MatchingType(matchingType.String)
+		e.MatchingType = &mt
+	}
+
+	if len(metadataBytes) > 0 {
+		if err := json.Unmarshal(metadataBytes, &e.Metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	return &e, nil
+}
@@ -0,0 +1,263 @@
+package _interface
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/bytebeatz/bandroom-cms/utils"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type importPG struct {
+	db *sql.DB
+}
+
+// NewImportPG returns a PostgreSQL-backed ImportRepository.
+func NewImportPG(db *sql.DB) repository.ImportRepository {
+	return &importPG{db: db}
+}
+
+// deleteRules says how each kind of entity is soft-deleted.
+var deleteRules = map[model.EntityType]struct {
+	table    string
+	cascades []cascadeRule
+}{
+	model.EntityUnit:     {"units", unitCascade},
+	model.EntitySkill:    {"skills", skillCascade},
+	model.EntityLesson:   {"lessons", lessonCascade},
+	model.EntityExercise: {"exercises", nil},
+}
+
+func (r *importPG) Apply(ctx context.Context, plan *model.ImportPlan) error {
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		var err error
+
+		// Parents first, so new children have something to point at and moved
+		// content has left its old parent before that is deleted
+		if c := plan.Course; c != nil {
+			if plan.Created[c.ID] {
+				err = insertCourse(ctx, tx, c)
+			} else {
+				err = importCourse(ctx, tx, c)
+			}
+			if err != nil {
+				return fmt.Errorf("course %s: %w", c.Slug, err)
+			}
+		}
+		for _, u := range plan.Units {
+			if plan.Created[u.ID] {
+				err = insertUnit(ctx, tx, u)
+			} else {
+				err = importUnit(ctx, tx, u)
+			}
+			if err != nil {
+				return fmt.Errorf("unit %s: %w", u.Title, err)
+			}
+		}
+		for _, s := range plan.Skills {
+			if plan.Created[s.ID] {
+				err = insertSkill(ctx, tx, s)
+			} else {
+				err = importSkill(ctx, tx, s)
+			}
+			if err != nil {
+				return fmt.Errorf("skill %s: %w", s.Slug, err)
+			}
+		}
+		for _, l := range plan.Lessons {
+			if plan.Created[l.ID] {
+				err = insertLesson(ctx, tx, l)
+			} else {
+				err = importLesson(ctx, tx, l)
+			}
+			if err != nil {
+				return fmt.Errorf("lesson %s: %w", l.Slug, err)
+			}
+		}
+		for _, e := range plan.Exercises {
+			if plan.Created[e.ID] {
+				err = insertExercise(ctx, tx, e)
+			} else {
+				err = importExercise(ctx, tx, e)
+			}
+			if err != nil {
+				return fmt.Errorf("exercise %s: %w", e.Title, err)
+			}
+		}
+
+		for exerciseID, options := range plan.Options {
+			if _, err := tx.ExecContext(ctx,
+				`DELETE FROM exercise_options WHERE exercise_id = $1`, exerciseID,
+			); err != nil {
+				return err
+			}
+			for _, o := range options {
+				if _, err := tx.ExecContext(ctx, insertExerciseOptionQuery,
+					o.ID, exerciseID, o.Label, o.Value, o.IsCorrect, o.MediaURL, o.OrderIndex,
+					o.CreatedAt, o.UpdatedAt,
+				); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Deepest first: an exercise deleted on its own may still point at a
+		// skill that is deleted with its unit
+		now := time.Now().UTC()
+		for i := len(plan.Deletes) - 1; i >= 0; i-- {
+			ref := plan.Deletes[i]
+			rule := deleteRules[ref.EntityType]
+			if err := markDeleted(ctx, tx, rule.table, ref.EntityID, rule.cascades, &now, nil); err != nil {
+				return fmt.Errorf("deleting %s %s: %w", ref.EntityType, ref.EntityID, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	bump := func(id uuid.UUID, version *int) {
+		if !plan.Created[id] {
+			*version++
+		}
+	}
+	if c := plan.Course; c != nil {
+		bump(c.ID, &c.Version)
+	}
+	for _, u := range plan.Units {
+		bump(u.ID, &u.Version)
+	}
+	for _, s := range plan.Skills {
+		bump(s.ID, &s.Version)
+	}
+	for _, l := range plan.Lessons {
+		bump(l.ID, &l.Version)
+	}
+	for _, e := range plan.Exercises {
+		bump(e.ID, &e.Version)
+	}
+	return nil
+}
+
+func importCourse(ctx context.Context, tx *sql.Tx, c *model.Course) error {
+	meta, err := json.Marshal(c.Metadata)
+	if err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `
+		UPDATE courses SET
+			slug = $2, title = $3, description = $4, language = $5,
+			difficulty = $6, tags = $7, metadata = $8,
+			version = version + 1, updated_at = $10, status = $11
+		WHERE id = $1 AND version = $9 AND deleted_at IS NULL
+	`,
+		c.ID, c.Slug, c.Title, c.Description, c.Language,
+		c.Difficulty, pq.StringArray(c.Tags), meta,
+		c.Version, c.UpdatedAt, c.Status,
+	)
+	if err != nil {
+		return err
+	}
+	return checkVersionedUpdate(ctx, tx, "courses", c.ID, res)
+}
+
+func importUnit(ctx context.Context, tx *sql.Tx, u *model.Unit) error {
+	res, err := tx.ExecContext(ctx, `
+		UPDATE units SET
+			title = $2, description = $3, "order_index" = $4,
+			version = version + 1, updated_at = $6, status = $7
+		WHERE id = $1 AND version = $5 AND deleted_at IS NULL
+	`,
+		u.ID, u.Title, u.Description, u.OrderIndex, u.Version,
+		u.UpdatedAt, u.Status,
+	)
+	if err != nil {
+		return err
+	}
+	return checkVersionedUpdate(ctx, tx, "units", u.ID, res)
+}
+
+func importSkill(ctx context.Context, tx *sql.Tx, s *model.Skill) error {
+	metadataJSON, err := json.Marshal(s.Metadata)
+	if err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `
+		UPDATE skills SET
+			unit_id = $2, slug = $3, title = $4, icon = $5, order_index = $6,
+			difficulty = $7, max_crowns = $8, base_xp_reward = $9, xp_per_crown = $10,
+			prerequisite_skill_ids = $11, tags = $12, metadata = $13,
+			version = version + 1, updated_at = $15, status = $16
+		WHERE id = $1 AND version = $14 AND deleted_at IS NULL
+	`,
+		s.ID, s.UnitID, s.Slug, s.Title, s.Icon, s.OrderIndex,
+		s.Difficulty, s.MaxCrowns, s.BaseXPReward, s.XPPerCrown,
+		pq.StringArray(utils.StringifyUUIDs(s.PrerequisiteSkillIDs)), pq.StringArray(s.Tags), metadataJSON,
+		s.Version, s.UpdatedAt, s.Status,
+	)
+	if err != nil {
+		return err
+	}
+	return checkVersionedUpdate(ctx, tx, "skills", s.ID, res)
+}
+
+func importLesson(ctx context.Context, tx *sql.Tx, l *model.Lesson) error {
+	metadataJSON, err := json.Marshal(l.Metadata)
+	if err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `
+		UPDATE lessons SET
+			skill_id = $2, slug = $3, title = $4, description = $5, order_index = $6,
+			total_exercises = $7, base_xp = $8, bonus_xp = $9, reward_gems = $10,
+			reward_hearts = $11, reward_condition = $12, estimated_duration = $13,
+			difficulty_rating = $14, is_testable = $15, tags = $16, metadata = $17,
+			version = version + 1, updated_at = $19, status = $20
+		WHERE id = $1 AND version = $18 AND deleted_at IS NULL
+	`,
+		l.ID, l.SkillID, l.Slug, l.Title, l.Description, l.OrderIndex,
+		l.TotalExercises, l.BaseXP, l.BonusXP, l.RewardGems,
+		l.RewardHearts, l.RewardCondition, l.EstimatedDuration,
+		l.DifficultyRating, l.IsTestable, pq.StringArray(l.Tags), metadataJSON,
+		l.Version, l.UpdatedAt, l.Status,
+	)
+	if err != nil {
+		return err
+	}
+	if err := checkVersionedUpdate(ctx, tx, "lessons", l.ID, res); err != nil {
+		return err
+	}
+	return moveLessonExercises(ctx, tx, l.ID, l.SkillID)
+}
+
+func importExercise(ctx context.Context, tx *sql.Tx, e *model.Exercise) error {
+	metadataJSON, err := json.Marshal(e.Metadata)
+	if err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `
+		UPDATE exercises SET
+			skill_id = $2, lesson_id = $3, title = $4, type = $5, matching_type = $6,
+			prompt = $7, media_url = $8, order_index = $9, points = $10, grade = $11,
+			syllabus = $12, objective_tag = $13, metadata = $14,
+			version = version + 1, updated_at = $15, status = $17
+		WHERE id = $1 AND version = $16 AND deleted_at IS NULL
+	`,
+		e.ID, e.SkillID, e.LessonID, e.Title, e.Type, e.MatchingType,
+		e.Prompt, e.MediaURL, e.OrderIndex, e.Points, e.Grade,
+		e.Syllabus, e.ObjectiveTag, metadataJSON,
+		e.UpdatedAt, e.Version, e.Status,
+	)
+	if err != nil {
+		return err
+	}
+	return checkVersionedUpdate(ctx, tx, "exercises", e.ID, res)
+}
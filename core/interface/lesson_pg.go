@@ -20,6 +20,10 @@ func NewLessonPG(db *sql.DB) repository.LessonRepository {
 }
 
 func (r *lessonPG) Create(ctx context.Context, l *model.Lesson) error {
+	return insertLesson(ctx, conn(ctx, r.db), l)
+}
+
+func insertLesson(ctx context.Context, db execer, l *model.Lesson) error {
 	metadataJSON, err := json.Marshal(l.Metadata)
 	if err != nil {
 		return err
@@ -41,7 +45,7 @@ func (r *lessonPG) Create(ctx context.Context, l *model.Lesson) error {
 		)
 	`
 
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = db.ExecContext(ctx, query,
 		l.ID, l.SkillID, l.Slug, l.Title, l.Description, l.OrderIndex, l.TotalExercises, l.BaseXP,
 		l.BonusXP, l.RewardGems, l.RewardHearts, l.RewardCondition,
 		l.EstimatedDuration, l.DifficultyRating, l.IsTestable,
@@ -51,6 +55,8 @@ func (r *lessonPG) Create(ctx context.Context, l *model.Lesson) error {
 	return err
 }
 
+// Update writes l only if the stored version still equals l.Version, then
+// bumps l.Version to the new stored version.
 func (r *lessonPG) Update(ctx context.Context, l *model.Lesson) error {
 	metadataJSON, err := json.Marshal(l.Metadata)
 	if err != nil {
@@ -62,49 +68,111 @@ func (r *lessonPG) Update(ctx context.Context, l *model.Lesson) error {
 			slug = $2, title = $3, description = $4, order_index = $5, total_exercises = $6,
 			base_xp = $7, bonus_xp = $8, reward_gems = $9, reward_hearts = $10,
 			reward_condition = $11, estimated_duration = $12, difficulty_rating = $13,
-			is_testable = $14, tags = $15, metadata = $16, version = $17,
-			deleted_at = $18, updated_at = $19
-		WHERE id = $1
+			is_testable = $14, tags = $15, metadata = $16, version = version + 1,
+			updated_at = $18, status = $19
+		WHERE id = $1 AND version = $17 AND deleted_at IS NULL
 	`
 
-	_, err = r.db.ExecContext(ctx, query,
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
 		l.ID, l.Slug, l.Title, l.Description, l.OrderIndex, l.TotalExercises,
 		l.BaseXP, l.BonusXP, l.RewardGems, l.RewardHearts,
 		l.RewardCondition, l.EstimatedDuration, l.DifficultyRating,
 		l.IsTestable, pq.StringArray(l.Tags), metadataJSON, l.Version,
-		l.DeletedAt, l.UpdatedAt,
+		l.UpdatedAt, l.Status,
 	)
+	if err != nil {
+		return err
+	}
+	if err := checkVersionedUpdate(ctx, conn(ctx, r.db), "lessons", l.ID, res); err != nil {
+		return err
+	}
+	l.Version++
+	return nil
+}
+
+// UpdateOrder writes the skill_id and order_index of each lesson in one transaction,
+// with the same version check as Update. The exercises of a lesson that
+// moves to another skill move with it.
+func (r *lessonPG) UpdateOrder(ctx context.Context, lessons []*model.Lesson) error {
+	query := `
+		UPDATE lessons SET skill_id = $2, order_index = $3, version = version + 1, updated_at = $5
+		WHERE id = $1 AND version = $4 AND deleted_at IS NULL
+	`
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		ctx := context.WithValue(ctx, txKey{}, tx)
+		err := updateOrder(ctx, r.db, "lessons", query, lessons,
+			func(l *model.Lesson) (uuid.UUID, []any) {
+				return l.ID, []any{l.ID, l.SkillID, l.OrderIndex, l.Version, l.UpdatedAt}
+			},
+			func(l *model.Lesson) { l.Version++ },
+		)
+		if err != nil {
+			return err
+		}
+		for _, l := range lessons {
+			if err := moveLessonExercises(ctx, tx, l.ID, l.SkillID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// moveLessonExercises sets the skill_id exercises copy from their lesson,
+// including deleted exercises that may be restored.
+func moveLessonExercises(ctx context.Context, db execer, lessonID, skillID uuid.UUID) error {
+	_, err := db.ExecContext(ctx,
+		`UPDATE exercises SET skill_id = $2 WHERE lesson_id = $1 AND skill_id <> $2`,
+		lessonID, skillID)
 	return err
 }
 
+// Delete soft-deletes the lesson together with its exercises.
 func (r *lessonPG) Delete(ctx context.Context, id uuid.UUID) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM lessons WHERE id = $1`, id)
-	return err
+	return softDelete(ctx, r.db, "lessons", id, lessonCascade)
 }
 
-func (r *lessonPG) GetByID(ctx context.Context, id uuid.UUID) (*model.Lesson, error) {
+// Restore undoes Delete as long as the owning skill is not deleted.
+func (r *lessonPG) Restore(ctx context.Context, id uuid.UUID) error {
+	parentCheck := `
+		SELECT s.deleted_at IS NULL FROM lessons l
+		JOIN skills s ON s.id = l.skill_id
+		WHERE l.id = $1
+	`
+	return restore(ctx, r.db, "lessons", id, parentCheck, lessonCascade)
+}
+
+func (r *lessonPG) GetByID(
+	ctx context.Context,
+	id uuid.UUID,
+	includeDeleted bool,
+) (*model.Lesson, error) {
 	query := `
 		SELECT id, skill_id, slug, title, description, order_index, total_exercises, base_xp,
 		       bonus_xp, reward_gems, reward_hearts, reward_condition,
 		       estimated_duration, difficulty_rating, is_testable,
-		       creator_id, tags, metadata, version,
+		       creator_id, tags, metadata, version, status,
 		       deleted_at, created_at, updated_at
-		FROM lessons WHERE id = $1
+		FROM lessons WHERE id = $1 AND ($2 OR deleted_at IS NULL)
 	`
-	row := r.db.QueryRowContext(ctx, query, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, id, includeDeleted)
 	return scanLesson(row)
 }
 
-func (r *lessonPG) ListBySkillID(ctx context.Context, skillID uuid.UUID) ([]*model.Lesson, error) {
+func (r *lessonPG) ListBySkillID(
+	ctx context.Context,
+	skillID uuid.UUID,
+	includeDeleted bool,
+) ([]*model.Lesson, error) {
 	query := `
 		SELECT id, skill_id, slug, title, description, order_index, total_exercises, base_xp,
 		       bonus_xp, reward_gems, reward_hearts, reward_condition,
 		       estimated_duration, difficulty_rating, is_testable,
-		       creator_id, tags, metadata, version,
+		       creator_id, tags, metadata, version, status,
 		       deleted_at, created_at, updated_at
-		FROM lessons WHERE skill_id = $1 ORDER BY order_index
+		FROM lessons WHERE skill_id = $1 AND ($2 OR deleted_at IS NULL) ORDER BY order_index
 	`
-	rows, err := r.db.QueryContext(ctx, query, skillID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, skillID, includeDeleted)
 	if err != nil {
 		return nil, err
 	}
@@ -121,6 +189,12 @@ func (r *lessonPG) ListBySkillID(ctx context.Context, skillID uuid.UUID) ([]*mod
 	return lessons, nil
 }
 
+func (r *lessonPG) Lock(ctx context.Context, id uuid.UUID) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`SELECT id FROM lessons WHERE id = $1 FOR UPDATE`, id)
+	return err
+}
+
 func (r *lessonPG) ExistsByTitleInSkill(
 	ctx context.Context,
 	skillID uuid.UUID,
@@ -128,10 +202,59 @@ func (r *lessonPG) ExistsByTitleInSkill(
 ) (bool, error) {
 	query := `SELECT EXISTS (SELECT 1 FROM lessons WHERE skill_id = $1 AND LOWER(title) = LOWER($2))`
 	var exists bool
-	err := r.db.QueryRowContext(ctx, query, skillID, title).Scan(&exists)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, skillID, title).Scan(&exists)
 	return exists, err
 }
 
+func (r *lessonPG) ListByCourseID(ctx context.Context, courseID uuid.UUID) ([]*model.Lesson, error) {
+	query := `SELECT ` + lessonListSpec.columns + `
+		FROM lessons
+		WHERE skill_id IN (SELECT id FROM skills WHERE course_id = $1) AND deleted_at IS NULL
+		ORDER BY order_index`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lessons []*model.Lesson
+	for rows.Next() {
+		l, err := scanLesson(rows)
+		if err != nil {
+			return nil, err
+		}
+		lessons = append(lessons, l)
+	}
+	return lessons, rows.Err()
+}
+
+var lessonListSpec = listSpec{
+	table: "lessons",
+	columns: `id, skill_id, slug, title, description, order_index, total_exercises, base_xp,
+		bonus_xp, reward_gems, reward_hearts, reward_condition,
+		estimated_duration, difficulty_rating, is_testable,
+		creator_id, tags, metadata, version, status, deleted_at, created_at, updated_at`,
+	parent:      "skill_id",
+	defaultSort: "order_index",
+	sorts:       []string{"title", "created_at", "updated_at", "order_index"},
+	filters:     []string{filterTags, filterCreator, filterStatus},
+}
+
+func (r *lessonPG) List(
+	ctx context.Context,
+	q repository.ListQuery,
+) (*repository.Page[*model.Lesson], error) {
+	return queryPage(ctx, conn(ctx, r.db), lessonListSpec, q, scanLesson,
+		func(l *model.Lesson) (uuid.UUID, sortKeys) {
+			return l.ID, sortKeys{
+				title:      l.Title,
+				createdAt:  l.CreatedAt,
+				updatedAt:  l.UpdatedAt,
+				orderIndex: l.OrderIndex,
+			}
+		})
+}
+
 func scanLesson(scanner interface {
 	Scan(dest ...any) error
 }) (*model.Lesson, error) {
@@ -158,6 +281,7 @@ func scanLesson(scanner interface {
 		pq.Array(&l.Tags),
 		&metadataBytes,
 		&l.Version,
+		&l.Status,
 		&l.DeletedAt,
 		&l.CreatedAt,
 		&l.UpdatedAt,
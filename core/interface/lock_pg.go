@@ -0,0 +1,44 @@
+package _interface
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+)
+
+type lockPG struct {
+	db *sql.DB
+}
+
+// NewLockPG returns a Locker backed by PostgreSQL advisory locks.
+func NewLockPG(db *sql.DB) repository.Locker {
+	return &lockPG{db: db}
+}
+
+// TryLock holds a session-level advisory lock, so it pins one connection of
+// the pool until released.
+func (l *lockPG) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var ok bool
+	err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, name).Scan(&ok)
+	if err != nil || !ok {
+		conn.Close()
+		return nil, false, err
+	}
+
+	release := func() {
+		// Unlock even if the caller's context is already cancelled
+		if _, err := conn.ExecContext(context.Background(),
+			`SELECT pg_advisory_unlock(hashtext($1))`, name); err != nil {
+			log.Printf("Failed to release lock %q: %v", name, err)
+		}
+		conn.Close()
+	}
+	return release, true, nil
+}
@@ -0,0 +1,179 @@
+package _interface
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type mediaPG struct {
+	db *sql.DB
+}
+
+// NewMediaPG returns a PostgreSQL-backed MediaRepository.
+func NewMediaPG(db *sql.DB) repository.MediaRepository {
+	return &mediaPG{db: db}
+}
+
+const mediaColumns = `
+	id, file_name, content_type, size_bytes, checksum, object_path, url,
+	duration_ms, uploaded_by, created_at,
+	sample_rate, channels, peak_db, rms_db, waveform, loudness_flags,
+	width, height, variants`
+
+func (r *mediaPG) Create(ctx context.Context, a *model.MediaAsset) error {
+	query := `
+		INSERT INTO media_assets (` + mediaColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16,
+			$17, $18, $19)
+	`
+	// Assets without an analysis leave its columns NULL
+	var (
+		sampleRate, channels *int
+		peakDB, rmsDB        *float64
+		waveform             pq.Float64Array
+		flags                pq.StringArray
+	)
+	if au := a.Audio; au != nil {
+		sampleRate, channels = &au.SampleRate, &au.Channels
+		peakDB, rmsDB = &au.PeakDB, &au.RMSDB
+		waveform = pq.Float64Array(au.Waveform)
+		flags = pq.StringArray(au.LoudnessFlags)
+		if flags == nil {
+			flags = pq.StringArray{}
+		}
+	}
+	variants := a.Variants
+	if variants == nil {
+		variants = []model.MediaVariant{}
+	}
+	variantsJSON, err := json.Marshal(variants)
+	if err != nil {
+		return err
+	}
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
+		a.ID, a.FileName, a.ContentType, a.Size, a.Checksum, a.ObjectPath, a.URL,
+		a.DurationMS, a.UploadedBy, a.CreatedAt,
+		sampleRate, channels, peakDB, rmsDB, waveform, flags,
+		a.Width, a.Height, variantsJSON,
+	)
+	return err
+}
+
+func (r *mediaPG) GetByID(ctx context.Context, id uuid.UUID) (*model.MediaAsset, error) {
+	row := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT `+mediaColumns+` FROM media_assets WHERE id = $1`, id)
+	return scanMedia(row)
+}
+
+func (r *mediaPG) GetByURLs(ctx context.Context, urls []string) ([]*model.MediaAsset, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT `+mediaColumns+` FROM media_assets WHERE url = ANY($1)`, pq.StringArray(urls))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var assets []*model.MediaAsset
+	for rows.Next() {
+		a, err := scanMedia(rows)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, a)
+	}
+	return assets, rows.Err()
+}
+
+// Delete checks for users of the asset and removes it in one statement.
+// Deleted exercises and options count as users, since they can be restored,
+// and so does every release, whose content stays served. The check cannot see
+// content saved by a transaction that has not committed yet, so a save racing
+// the delete can still end up pointing at a deleted file.
+func (r *mediaPG) Delete(ctx context.Context, id uuid.UUID) error {
+	var deleted, inUse bool
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		WITH asset AS (
+			SELECT id, url FROM media_assets WHERE id = $1
+		), used AS (
+			SELECT EXISTS (
+				SELECT 1 FROM exercises e, asset WHERE e.media_url = asset.url
+			) OR EXISTS (
+				SELECT 1 FROM exercise_options o, asset WHERE o.media_url = asset.url
+			) OR EXISTS (
+				SELECT 1 FROM releases r, asset
+				WHERE jsonb_path_exists(r.content, '$.** ? (@ == $url)',
+					jsonb_build_object('url', asset.url))
+			) AS in_use
+		), removed AS (
+			DELETE FROM media_assets
+			WHERE id = (SELECT id FROM asset) AND NOT (SELECT in_use FROM used)
+			RETURNING id
+		)
+		SELECT EXISTS (SELECT 1 FROM removed), (SELECT in_use FROM used)
+	`, id).Scan(&deleted, &inUse)
+	if err != nil {
+		return err
+	}
+	switch {
+	case deleted:
+		return nil
+	case inUse:
+		return repository.ErrMediaInUse
+	default:
+		return sql.ErrNoRows
+	}
+}
+
+func scanMedia(scanner interface {
+	Scan(dest ...any) error
+}) (*model.MediaAsset, error) {
+	var a model.MediaAsset
+	var duration sql.NullInt64
+	var uploadedBy uuid.NullUUID
+	var sampleRate, channels sql.NullInt64
+	var peakDB, rmsDB sql.NullFloat64
+	var waveform pq.Float64Array
+	var flags pq.StringArray
+	var width, height sql.NullInt64
+	var variantsJSON []byte
+	err := scanner.Scan(
+		&a.ID, &a.FileName, &a.ContentType, &a.Size, &a.Checksum, &a.ObjectPath, &a.URL,
+		&duration, &uploadedBy, &a.CreatedAt,
+		&sampleRate, &channels, &peakDB, &rmsDB, &waveform, &flags,
+		&width, &height, &variantsJSON,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(variantsJSON, &a.Variants); err != nil {
+		return nil, err
+	}
+	if width.Valid && height.Valid {
+		w, h := int(width.Int64), int(height.Int64)
+		a.Width, a.Height = &w, &h
+	}
+	if duration.Valid {
+		ms := int(duration.Int64)
+		a.DurationMS = &ms
+	}
+	if uploadedBy.Valid {
+		a.UploadedBy = &uploadedBy.UUID
+	}
+	if sampleRate.Valid {
+		a.Audio = &model.AudioAnalysis{
+			SampleRate:    int(sampleRate.Int64),
+			Channels:      int(channels.Int64),
+			PeakDB:        peakDB.Float64,
+			RMSDB:         rmsDB.Float64,
+			Waveform:      waveform,
+			LoudnessFlags: flags,
+		}
+	}
+	return &a, nil
+}
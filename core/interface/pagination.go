@@ -0,0 +1,226 @@
+package _interface
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Filters a table may support in a ListQuery.
+const (
+	filterLanguage    = "language"
+	filterDifficulty  = "difficulty"
+	filterIsPublished = "is_published"
+	filterTags        = "tags"
+	filterCreator     = "creator"
+	filterStatus      = "status"
+)
+
+// sortCasts maps each sort field to the SQL type its cursor value is cast to.
+var sortCasts = map[string]string{
+	"title":       "text",
+	"created_at":  "timestamptz",
+	"updated_at":  "timestamptz",
+	"order_index": "int",
+}
+
+// listSpec describes how a table is listed page by page.
+type listSpec struct {
+	table       string
+	columns     string // SELECT list matching the table's scan function
+	parent      string // column matched by ListQuery.ParentID
+	defaultSort string
+	sorts       []string
+	filters     []string
+}
+
+// sortKeys holds the values a row can be sorted by.
+type sortKeys struct {
+	title      string
+	createdAt  time.Time
+	updatedAt  time.Time
+	orderIndex int
+}
+
+func (k sortKeys) value(field string) any {
+	switch field {
+	case "title":
+		return k.title
+	case "created_at":
+		return k.createdAt
+	case "updated_at":
+		return k.updatedAt
+	default:
+		return k.orderIndex
+	}
+}
+
+// queryPage runs a keyset-paginated list query described by spec and q.
+func queryPage[T any](
+	ctx context.Context,
+	db dbConn,
+	spec listSpec,
+	q repository.ListQuery,
+	scan func(scanner interface{ Scan(dest ...any) error }) (T, error),
+	keys func(T) (uuid.UUID, sortKeys),
+) (*repository.Page[T], error) {
+	query, args, err := buildPageQuery(spec, &q)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &repository.Page[T]{}
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Items = append(page.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// One row past the limit is fetched to know whether another page exists
+	if len(page.Items) > q.Limit {
+		page.Items = page.Items[:q.Limit]
+		id, k := keys(page.Items[q.Limit-1])
+		page.NextCursor = repository.Cursor{
+			Sort:  q.Sort,
+			Desc:  q.Desc,
+			Value: k.value(q.Sort),
+			ID:    id,
+		}.Encode()
+	}
+	return page, nil
+}
+
+// buildPageQuery validates q against spec, fills in defaults and returns the
+// SQL with its arguments.
+func buildPageQuery(spec listSpec, q *repository.ListQuery) (string, []any, error) {
+	if q.Sort == "" {
+		q.Sort = spec.defaultSort
+	}
+	if !slices.Contains(spec.sorts, q.Sort) {
+		return "", nil, fmt.Errorf("%w: cannot sort %s by %q", repository.ErrInvalidQuery, spec.table, q.Sort)
+	}
+	if q.Limit <= 0 {
+		q.Limit = repository.DefaultPageSize
+	}
+	q.Limit = min(q.Limit, repository.MaxPageSize)
+
+	where := []string{"($1 OR deleted_at IS NULL)"}
+	args := []any{q.IncludeDeleted}
+	add := func(cond string, values ...any) {
+		placeholders := make([]any, len(values))
+		for i, v := range values {
+			args = append(args, v)
+			placeholders[i] = len(args)
+		}
+		where = append(where, fmt.Sprintf(cond, placeholders...))
+	}
+
+	unsupported := func(filter string) error {
+		return fmt.Errorf("%w: %s cannot be filtered by %s", repository.ErrInvalidQuery, spec.table, filter)
+	}
+
+	if q.ParentID != nil {
+		if spec.parent == "" {
+			return "", nil, unsupported("parent")
+		}
+		add(spec.parent+" = $%d", *q.ParentID)
+	}
+	if q.Language != "" {
+		if !slices.Contains(spec.filters, filterLanguage) {
+			return "", nil, unsupported(filterLanguage)
+		}
+		add("language = $%d", q.Language)
+	}
+	if q.Difficulty != nil {
+		if !slices.Contains(spec.filters, filterDifficulty) {
+			return "", nil, unsupported(filterDifficulty)
+		}
+		add("difficulty = $%d", *q.Difficulty)
+	}
+	if q.IsPublished != nil {
+		if !slices.Contains(spec.filters, filterIsPublished) {
+			return "", nil, unsupported(filterIsPublished)
+		}
+		add("is_published = $%d", *q.IsPublished)
+	}
+	if len(q.Tags) > 0 {
+		if !slices.Contains(spec.filters, filterTags) {
+			return "", nil, unsupported(filterTags)
+		}
+		add("tags @> $%d", pq.Array(q.Tags))
+	}
+	if q.CreatorID != nil {
+		if !slices.Contains(spec.filters, filterCreator) {
+			return "", nil, unsupported(filterCreator)
+		}
+		add("creator_id = $%d", *q.CreatorID)
+	}
+	if q.Status != "" {
+		if !slices.Contains(spec.filters, filterStatus) {
+			return "", nil, unsupported(filterStatus)
+		}
+		add("status = $%d", q.Status)
+	}
+
+	column := q.Sort
+	direction, compare := "ASC", ">"
+	if q.Desc {
+		direction, compare = "DESC", "<"
+	}
+
+	if q.After != nil {
+		if q.After.Sort != q.Sort || q.After.Desc != q.Desc {
+			return "", nil, fmt.Errorf("%w: cursor was issued for a different sort", repository.ErrInvalidQuery)
+		}
+		value, err := cursorValue(q.Sort, q.After.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		add(fmt.Sprintf("(%s, id) %s ($%%d::%s, $%%d)", column, compare, sortCasts[q.Sort]),
+			value, q.After.ID)
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE %s ORDER BY %s %s, id %s LIMIT %d",
+		spec.columns, spec.table, strings.Join(where, " AND "),
+		column, direction, direction, q.Limit+1,
+	)
+	return query, args, nil
+}
+
+// cursorValue converts a sort value decoded from JSON back into a type the
+// database driver can bind.
+func cursorValue(sort string, v any) (any, error) {
+	invalid := fmt.Errorf("%w: malformed cursor", repository.ErrInvalidQuery)
+	switch sort {
+	case "order_index":
+		f, ok := v.(float64)
+		if !ok {
+			return nil, invalid
+		}
+		return int(f), nil
+	default:
+		s, ok := v.(string)
+		if !ok {
+			return nil, invalid
+		}
+		return s, nil
+	}
+}
@@ -0,0 +1,163 @@
+package _interface
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type releasePG struct {
+	db *sql.DB
+}
+
+// NewReleasePG returns a PostgreSQL-backed ReleaseRepository.
+func NewReleasePG(db *sql.DB) repository.ReleaseRepository {
+	return &releasePG{db: db}
+}
+
+// releaseColumns are the columns read for a release, content last; the
+// channels currently pointing at it come along as an array.
+const releaseColumns = `
+	r.id, r.course_id, r.version, r.content_hash, r.notes, r.created_by, r.created_at,
+	ARRAY(SELECT ch.channel FROM release_channels ch WHERE ch.release_id = r.id ORDER BY ch.channel)`
+
+// Create numbers the release while holding the course row, so concurrent
+// releases of one course get consecutive versions.
+func (r *releasePG) Create(ctx context.Context, rel *model.Release) error {
+	contentJSON, err := json.Marshal(rel.Content)
+	if err != nil {
+		return err
+	}
+
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			`SELECT id FROM courses WHERE id = $1 FOR UPDATE`, rel.CourseID); err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO releases (
+				id, course_id, version, content_hash, content, notes, created_by, created_at
+			)
+			SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, $4, $5, $6, $7
+			FROM releases WHERE course_id = $2
+			RETURNING version
+		`
+		err = tx.QueryRowContext(ctx, query,
+			rel.ID, rel.CourseID, rel.ContentHash, contentJSON, rel.Notes,
+			rel.CreatedBy, rel.CreatedAt,
+		).Scan(&rel.Version)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func (r *releasePG) GetByVersion(
+	ctx context.Context,
+	courseID uuid.UUID,
+	version int,
+) (*model.Release, error) {
+	query := `SELECT ` + releaseColumns + `, r.content
+		FROM releases r WHERE r.course_id = $1 AND r.version = $2`
+	return scanReleaseContent(conn(ctx, r.db).QueryRowContext(ctx, query, courseID, version))
+}
+
+func (r *releasePG) GetByChannel(
+	ctx context.Context,
+	courseID uuid.UUID,
+	channel model.ReleaseChannel,
+) (*model.Release, error) {
+	query := `SELECT ` + releaseColumns + `, r.content
+		FROM release_channels c JOIN releases r ON r.id = c.release_id
+		WHERE c.course_id = $1 AND c.channel = $2`
+	return scanReleaseContent(conn(ctx, r.db).QueryRowContext(ctx, query, courseID, channel))
+}
+
+func (r *releasePG) ListByCourse(
+	ctx context.Context,
+	courseID uuid.UUID,
+) ([]*model.Release, error) {
+	query := `SELECT ` + releaseColumns + `
+		FROM releases r WHERE r.course_id = $1 ORDER BY r.version DESC`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var releases []*model.Release
+	for rows.Next() {
+		rel, err := scanRelease(rows)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, rel)
+	}
+	return releases, rows.Err()
+}
+
+func (r *releasePG) Promote(
+	ctx context.Context,
+	courseID uuid.UUID,
+	channel model.ReleaseChannel,
+	releaseID uuid.UUID,
+	by *uuid.UUID,
+	at time.Time,
+) error {
+	query := `
+		INSERT INTO release_channels (course_id, channel, release_id, promoted_by, promoted_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (course_id, channel) DO UPDATE SET
+			release_id = EXCLUDED.release_id,
+			promoted_by = EXCLUDED.promoted_by,
+			promoted_at = EXCLUDED.promoted_at
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, courseID, channel, releaseID, by, at)
+	return err
+}
+
+func scanRelease(scanner interface {
+	Scan(dest ...any) error
+}, extra ...any) (*model.Release, error) {
+	var rel model.Release
+	var channels []string
+	dest := append([]any{
+		&rel.ID,
+		&rel.CourseID,
+		&rel.Version,
+		&rel.ContentHash,
+		&rel.Notes,
+		&rel.CreatedBy,
+		&rel.CreatedAt,
+		pq.Array(&channels),
+	}, extra...)
+	if err := scanner.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	rel.Channels = make([]model.ReleaseChannel, 0, len(channels))
+	for _, ch := range channels {
+		rel.Channels = append(rel.Channels, model.ReleaseChannel(ch))
+	}
+	return &rel, nil
+}
+
+func scanReleaseContent(row *sql.Row) (*model.Release, error) {
+	var contentBytes []byte
+	rel, err := scanRelease(row, &contentBytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(contentBytes, &rel.Content); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
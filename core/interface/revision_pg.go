@@ -0,0 +1,106 @@
+package _interface
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/google/uuid"
+)
+
+type revisionPG struct {
+	db *sql.DB
+}
+
+// NewRevisionPG returns a PostgreSQL-backed RevisionRepository.
+func NewRevisionPG(db *sql.DB) repository.RevisionRepository {
+	return &revisionPG{db: db}
+}
+
+func (r *revisionPG) Create(ctx context.Context, rev *model.Revision) error {
+	snapshotJSON, err := json.Marshal(rev.Snapshot)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO revisions (
+			id, entity_type, entity_id, version, snapshot, author_id, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (entity_type, entity_id, version) DO NOTHING
+	`
+
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
+		rev.ID, rev.EntityType, rev.EntityID, rev.Version, snapshotJSON,
+		rev.AuthorID, rev.CreatedAt,
+	)
+	return err
+}
+
+func (r *revisionPG) GetByVersion(
+	ctx context.Context,
+	entityType model.EntityType,
+	entityID uuid.UUID,
+	version int,
+) (*model.Revision, error) {
+	query := `
+		SELECT id, entity_type, entity_id, version, snapshot, author_id, created_at
+		FROM revisions WHERE entity_type = $1 AND entity_id = $2 AND version = $3
+	`
+
+	var rev model.Revision
+	var snapshotBytes []byte
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, entityType, entityID, version).Scan(
+		&rev.ID,
+		&rev.EntityType,
+		&rev.EntityID,
+		&rev.Version,
+		&snapshotBytes,
+		&rev.AuthorID,
+		&rev.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(snapshotBytes, &rev.Snapshot); err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+func (r *revisionPG) ListByEntity(
+	ctx context.Context,
+	entityType model.EntityType,
+	entityID uuid.UUID,
+) ([]*model.Revision, error) {
+	query := `
+		SELECT id, entity_type, entity_id, version, author_id, created_at
+		FROM revisions WHERE entity_type = $1 AND entity_id = $2
+		ORDER BY version DESC
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []*model.Revision
+	for rows.Next() {
+		var rev model.Revision
+		if err := rows.Scan(
+			&rev.ID,
+			&rev.EntityType,
+			&rev.EntityID,
+			&rev.Version,
+			&rev.AuthorID,
+			&rev.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, &rev)
+	}
+	return revisions, rows.Err()
+}
@@ -0,0 +1,176 @@
+package _interface
+
+import (
+	"context"
+	"database/sql"
+	"html"
+	"strings"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type searchPG struct {
+	db *sql.DB
+}
+
+// NewSearchPG returns a PostgreSQL full-text SearchRepository.
+func NewSearchPG(db *sql.DB) repository.SearchRepository {
+	return &searchPG{db: db}
+}
+
+// highlightStart and highlightStop mark the matches in ts_headline output so
+// that the text around them can be HTML-escaped before they become <mark>
+// tags. They are Unicode noncharacters, which content has no use for.
+const (
+	highlightStart = "\uFDD0"
+	highlightStop  = "\uFDD1"
+)
+
+var highlightTags = strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>")
+
+// searchQuery matches the stored search vectors of every live entity against
+// the query text, parsed once per text search configuration (course
+// language) in use so each table's GIN index can serve the match, and ranks
+// the hits with their breadcrumb paths. See migration 0015 for the vectors.
+const searchQuery = `
+	WITH queries AS (
+		SELECT cfg, websearch_to_tsquery(cfg, $1) AS query
+		FROM (SELECT DISTINCT search_config(language) AS cfg FROM courses) k
+	), docs AS (
+		SELECT 'course' AS entity_type, c.id, c.title, c.description AS body,
+		       c.search_vector AS vector, q.cfg, q.query,
+		       c.id AS course_id, c.title AS course_title,
+		       NULL::uuid AS unit_id, NULL::text AS unit_title,
+		       NULL::uuid AS skill_id, NULL::text AS skill_title,
+		       NULL::uuid AS lesson_id, NULL::text AS lesson_title
+		FROM courses c
+		JOIN queries q ON q.cfg = search_config(c.language) AND c.search_vector @@ q.query
+		WHERE c.deleted_at IS NULL
+
+		UNION ALL
+		SELECT 'unit', u.id, u.title, u.description,
+		       u.search_vector, q.cfg, q.query,
+		       c.id, c.title, NULL, NULL, NULL, NULL, NULL, NULL
+		FROM units u
+		JOIN queries q ON q.cfg = search_config(u.course_language) AND u.search_vector @@ q.query
+		JOIN courses c ON c.id = u.course_id
+		WHERE u.deleted_at IS NULL
+
+		UNION ALL
+		SELECT 'skill', s.id, s.title, '',
+		       s.search_vector, q.cfg, q.query,
+		       c.id, c.title, u.id, u.title, NULL, NULL, NULL, NULL
+		FROM skills s
+		JOIN queries q ON q.cfg = search_config(s.course_language) AND s.search_vector @@ q.query
+		JOIN units u ON u.id = s.unit_id
+		JOIN courses c ON c.id = u.course_id
+		WHERE s.deleted_at IS NULL
+
+		UNION ALL
+		SELECT 'lesson', l.id, l.title, l.description,
+		       l.search_vector, q.cfg, q.query,
+		       c.id, c.title, u.id, u.title, s.id, s.title, NULL, NULL
+		FROM lessons l
+		JOIN queries q ON q.cfg = search_config(l.course_language) AND l.search_vector @@ q.query
+		JOIN skills s ON s.id = l.skill_id
+		JOIN units u ON u.id = s.unit_id
+		JOIN courses c ON c.id = u.course_id
+		WHERE l.deleted_at IS NULL
+
+		UNION ALL
+		SELECT 'exercise', e.id, e.title, e.prompt,
+		       e.search_vector, q.cfg, q.query,
+		       c.id, c.title, u.id, u.title, s.id, s.title, l.id, l.title
+		FROM exercises e
+		JOIN queries q ON q.cfg = search_config(e.course_language) AND e.search_vector @@ q.query
+		JOIN lessons l ON l.id = e.lesson_id
+		JOIN skills s ON s.id = l.skill_id
+		JOIN units u ON u.id = s.unit_id
+		JOIN courses c ON c.id = u.course_id
+		WHERE e.deleted_at IS NULL
+	)
+	SELECT d.entity_type, d.id, d.title,
+	       ts_rank(d.vector, d.query) AS rank,
+	       ts_headline(d.cfg, concat_ws(' — ', d.title, NULLIF(d.body, '')), d.query,
+	                   'StartSel="` + highlightStart + `", StopSel="` + highlightStop + `", ' ||
+	                   'MaxWords=30, MinWords=10, MaxFragments=2'),
+	       d.course_id, d.course_title, d.unit_id, d.unit_title,
+	       d.skill_id, d.skill_title, d.lesson_id, d.lesson_title
+	FROM docs d
+	WHERE ($2::text[] IS NULL OR d.entity_type = ANY($2))
+	  AND ($3::uuid IS NULL OR d.course_id = $3)
+	ORDER BY rank DESC, d.title
+	LIMIT $4
+`
+
+func (r *searchPG) Search(
+	ctx context.Context,
+	q repository.SearchQuery,
+) ([]*model.SearchHit, error) {
+	var types pq.StringArray
+	for _, t := range q.Types {
+		types = append(types, string(t))
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, searchQuery, q.Text, types, q.CourseID, q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []*model.SearchHit
+	for rows.Next() {
+		var hit model.SearchHit
+		var courseID, unitID, skillID, lessonID sql.NullString
+		var courseTitle, unitTitle, skillTitle, lessonTitle sql.NullString
+
+		if err := rows.Scan(
+			&hit.EntityType,
+			&hit.ID,
+			&hit.Title,
+			&hit.Rank,
+			&hit.Highlight,
+			&courseID, &courseTitle,
+			&unitID, &unitTitle,
+			&skillID, &skillTitle,
+			&lessonID, &lessonTitle,
+		); err != nil {
+			return nil, err
+		}
+
+		// Author text is shown as HTML, so only the match marks may be tags
+		hit.Highlight = highlightTags.Replace(html.EscapeString(hit.Highlight))
+
+		// Ancestors only: a course is not its own breadcrumb
+		crumbs := []struct {
+			entityType model.EntityType
+			id, title  sql.NullString
+		}{
+			{model.EntityCourse, courseID, courseTitle},
+			{model.EntityUnit, unitID, unitTitle},
+			{model.EntitySkill, skillID, skillTitle},
+			{model.EntityLesson, lessonID, lessonTitle},
+		}
+		hit.Breadcrumbs = []model.Breadcrumb{}
+		for _, c := range crumbs {
+			if !c.id.Valid || c.entityType == hit.EntityType {
+				continue
+			}
+			id, err := uuid.Parse(c.id.String)
+			if err != nil {
+				return nil, err
+			}
+			hit.Breadcrumbs = append(hit.Breadcrumbs, model.Breadcrumb{
+				EntityType: c.entityType,
+				ID:         id,
+				Title:      c.title.String,
+			})
+		}
+
+		hits = append(hits, &hit)
+	}
+	return hits, rows.Err()
+}
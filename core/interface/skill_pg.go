@@ -21,6 +21,10 @@ func NewSkillPG(db *sql.DB) repository.SkillRepository {
 }
 
 func (r *skillPG) Create(ctx context.Context, s *model.Skill) error {
+	return insertSkill(ctx, conn(ctx, r.db), s)
+}
+
+func insertSkill(ctx context.Context, db execer, s *model.Skill) error {
 	query := `
 		INSERT INTO skills (
 			id, course_id, unit_id, slug, title, icon, order_index, difficulty,
@@ -43,7 +47,7 @@ func (r *skillPG) Create(ctx context.Context, s *model.Skill) error {
 		return err
 	}
 
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = db.ExecContext(ctx, query,
 		s.ID, s.CourseID, s.UnitID, s.Slug, s.Title, s.Icon, s.OrderIndex, s.Difficulty,
 		s.MaxCrowns, s.BaseXPReward, s.XPPerCrown, prereqs,
 		s.CreatorID, tags, jsonMetadata, s.Version,
@@ -52,14 +56,16 @@ func (r *skillPG) Create(ctx context.Context, s *model.Skill) error {
 	return err
 }
 
+// Update writes s only if the stored version still equals s.Version, then
+// bumps s.Version to the new stored version.
 func (r *skillPG) Update(ctx context.Context, s *model.Skill) error {
 	query := `
 		UPDATE skills SET
 			slug = $2, title = $3, icon = $4, order_index = $5, difficulty = $6,
 			max_crowns = $7, base_xp_reward = $8, xp_per_crown = $9,
 			prerequisite_skill_ids = $10, tags = $11, metadata = $12,
-			version = $13, deleted_at = $14, updated_at = $15
-		WHERE id = $1
+			version = version + 1, updated_at = $14, status = $15
+		WHERE id = $1 AND version = $13 AND deleted_at IS NULL
 	`
 
 	prereqIDs := pq.StringArray(utils.StringifyUUIDs(s.PrerequisiteSkillIDs))
@@ -70,41 +76,81 @@ func (r *skillPG) Update(ctx context.Context, s *model.Skill) error {
 		return err
 	}
 
-	_, err = r.db.ExecContext(ctx, query,
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
 		s.ID, s.Slug, s.Title, s.Icon, s.OrderIndex, s.Difficulty,
 		s.MaxCrowns, s.BaseXPReward, s.XPPerCrown,
 		prereqIDs, tags, metadataJSON,
-		s.Version, s.DeletedAt, s.UpdatedAt,
+		s.Version, s.UpdatedAt, s.Status,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if err := checkVersionedUpdate(ctx, conn(ctx, r.db), "skills", s.ID, res); err != nil {
+		return err
+	}
+	s.Version++
+	return nil
 }
 
+// UpdateOrder writes the unit_id and order_index of each skill in one transaction,
+// with the same version check as Update.
+func (r *skillPG) UpdateOrder(ctx context.Context, skills []*model.Skill) error {
+	query := `
+		UPDATE skills SET unit_id = $2, order_index = $3, version = version + 1, updated_at = $5
+		WHERE id = $1 AND version = $4 AND deleted_at IS NULL
+	`
+	return updateOrder(ctx, r.db, "skills", query, skills,
+		func(s *model.Skill) (uuid.UUID, []any) {
+			return s.ID, []any{s.ID, s.UnitID, s.OrderIndex, s.Version, s.UpdatedAt}
+		},
+		func(s *model.Skill) { s.Version++ },
+	)
+}
+
+// Delete soft-deletes the skill together with its lessons and exercises.
 func (r *skillPG) Delete(ctx context.Context, id uuid.UUID) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM skills WHERE id = $1`, id)
-	return err
+	return softDelete(ctx, r.db, "skills", id, skillCascade)
+}
+
+// Restore undoes Delete as long as the owning unit is not deleted.
+func (r *skillPG) Restore(ctx context.Context, id uuid.UUID) error {
+	parentCheck := `
+		SELECT u.deleted_at IS NULL FROM skills s
+		JOIN units u ON u.id = s.unit_id
+		WHERE s.id = $1
+	`
+	return restore(ctx, r.db, "skills", id, parentCheck, skillCascade)
 }
 
-func (r *skillPG) GetByID(ctx context.Context, id uuid.UUID) (*model.Skill, error) {
+func (r *skillPG) GetByID(
+	ctx context.Context,
+	id uuid.UUID,
+	includeDeleted bool,
+) (*model.Skill, error) {
 	query := `
 		SELECT id, course_id, unit_id, slug, title, icon, order_index, difficulty,
 		       max_crowns, base_xp_reward, xp_per_crown, prerequisite_skill_ids,
-		       creator_id, tags, metadata, version,
+		       creator_id, tags, metadata, version, status,
 		       deleted_at, created_at, updated_at
-		FROM skills WHERE id = $1
+		FROM skills WHERE id = $1 AND ($2 OR deleted_at IS NULL)
 	`
-	row := r.db.QueryRowContext(ctx, query, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, id, includeDeleted)
 	return scanSkill(row)
 }
 
-func (r *skillPG) ListByUnitID(ctx context.Context, unitID uuid.UUID) ([]*model.Skill, error) {
+func (r *skillPG) ListByUnitID(
+	ctx context.Context,
+	unitID uuid.UUID,
+	includeDeleted bool,
+) ([]*model.Skill, error) {
 	query := `
 		SELECT id, course_id, unit_id, slug, title, icon, order_index, difficulty,
 		       max_crowns, base_xp_reward, xp_per_crown, prerequisite_skill_ids,
-		       creator_id, tags, metadata, version,
+		       creator_id, tags, metadata, version, status,
 		       deleted_at, created_at, updated_at
-		FROM skills WHERE unit_id = $1 ORDER BY order_index
+		FROM skills WHERE unit_id = $1 AND ($2 OR deleted_at IS NULL) ORDER BY order_index
 	`
-	rows, err := r.db.QueryContext(ctx, query, unitID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, unitID, includeDeleted)
 	if err != nil {
 		return nil, err
 	}
@@ -121,6 +167,32 @@ func (r *skillPG) ListByUnitID(ctx context.Context, unitID uuid.UUID) ([]*model.
 	return skills, nil
 }
 
+func (r *skillPG) ListByCourseID(ctx context.Context, courseID uuid.UUID) ([]*model.Skill, error) {
+	query := `SELECT ` + skillListSpec.columns + `
+		FROM skills WHERE course_id = $1 AND deleted_at IS NULL ORDER BY order_index`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var skills []*model.Skill
+	for rows.Next() {
+		s, err := scanSkill(rows)
+		if err != nil {
+			return nil, err
+		}
+		skills = append(skills, s)
+	}
+	return skills, rows.Err()
+}
+
+func (r *skillPG) LockCourse(ctx context.Context, courseID uuid.UUID) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`SELECT id FROM courses WHERE id = $1 FOR UPDATE`, courseID)
+	return err
+}
+
 func (r *skillPG) ExistsByTitleInCourse(
 	ctx context.Context,
 	courseID uuid.UUID,
@@ -134,10 +206,36 @@ func (r *skillPG) ExistsByTitleInCourse(
 		)
 	`
 	var exists bool
-	err := r.db.QueryRowContext(ctx, query, courseID, title).Scan(&exists)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, courseID, title).Scan(&exists)
 	return exists, err
 }
 
+var skillListSpec = listSpec{
+	table: "skills",
+	columns: `id, course_id, unit_id, slug, title, icon, order_index, difficulty,
+		max_crowns, base_xp_reward, xp_per_crown, prerequisite_skill_ids,
+		creator_id, tags, metadata, version, status, deleted_at, created_at, updated_at`,
+	parent:      "unit_id",
+	defaultSort: "order_index",
+	sorts:       []string{"title", "created_at", "updated_at", "order_index"},
+	filters:     []string{filterDifficulty, filterTags, filterCreator, filterStatus},
+}
+
+func (r *skillPG) List(
+	ctx context.Context,
+	q repository.ListQuery,
+) (*repository.Page[*model.Skill], error) {
+	return queryPage(ctx, conn(ctx, r.db), skillListSpec, q, scanSkill,
+		func(s *model.Skill) (uuid.UUID, sortKeys) {
+			return s.ID, sortKeys{
+				title:      s.Title,
+				createdAt:  s.CreatedAt,
+				updatedAt:  s.UpdatedAt,
+				orderIndex: s.OrderIndex,
+			}
+		})
+}
+
 func scanSkill(scanner interface {
 	Scan(dest ...any) error
 }) (*model.Skill, error) {
@@ -149,7 +247,7 @@ func scanSkill(scanner interface {
 	err := scanner.Scan(
 		&s.ID, &s.CourseID, &s.UnitID, &s.Slug, &s.Title, &s.Icon, &s.OrderIndex, &s.Difficulty,
 		&s.MaxCrowns, &s.BaseXPReward, &s.XPPerCrown, &prereqIDs,
-		&s.CreatorID, &tags, &metadataBytes, &s.Version,
+		&s.CreatorID, &tags, &metadataBytes, &s.Version, &s.Status,
 		&s.DeletedAt, &s.CreatedAt, &s.UpdatedAt,
 	)
 	if err != nil {
@@ -0,0 +1,136 @@
+package _interface
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/google/uuid"
+)
+
+// cascadeRule describes child rows that are soft-deleted together with a
+// parent. where is a condition on the parent ID bound to $1.
+type cascadeRule struct {
+	table string
+	where string
+}
+
+var (
+	lessonCascade = []cascadeRule{
+		{"exercises", "lesson_id = $1"},
+	}
+	// Exercises are matched through their lesson rather than their own copy
+	// of its skill_id
+	skillCascade = []cascadeRule{
+		{"lessons", "skill_id = $1"},
+		{"exercises", "lesson_id IN (SELECT id FROM lessons WHERE skill_id = $1)"},
+	}
+	unitCascade = []cascadeRule{
+		{"skills", "unit_id = $1"},
+		{"lessons", "skill_id IN (SELECT id FROM skills WHERE unit_id = $1)"},
+		{"exercises", `lesson_id IN (SELECT l.id FROM lessons l
+			JOIN skills s ON s.id = l.skill_id WHERE s.unit_id = $1)`},
+	}
+	courseCascade = []cascadeRule{
+		{"units", "course_id = $1"},
+		{"skills", "course_id = $1"},
+		{"lessons", "skill_id IN (SELECT id FROM skills WHERE course_id = $1)"},
+		{"exercises", `lesson_id IN (SELECT l.id FROM lessons l
+			JOIN skills s ON s.id = l.skill_id WHERE s.course_id = $1)`},
+	}
+)
+
+// softDelete stamps deleted_at on a row and on every live descendant, all with
+// the same timestamp so restore can tell them apart from rows that were
+// deleted on their own earlier.
+func softDelete(
+	ctx context.Context,
+	db *sql.DB,
+	table string,
+	id uuid.UUID,
+	cascades []cascadeRule,
+) error {
+	now := time.Now().UTC()
+	return setDeletedAt(ctx, db, table, id, cascades, &now, nil)
+}
+
+// restore clears deleted_at on a row and on the descendants that were deleted
+// with it. parentCheck, when set, must return TRUE for the parent of $1 to be live.
+func restore(
+	ctx context.Context,
+	db *sql.DB,
+	table string,
+	id uuid.UUID,
+	parentCheck string,
+	cascades []cascadeRule,
+) error {
+	q := conn(ctx, db)
+	var deletedAt *time.Time
+	err := q.QueryRowContext(ctx, `SELECT deleted_at FROM `+table+` WHERE id = $1`, id).
+		Scan(&deletedAt)
+	if err != nil {
+		return err
+	}
+	if deletedAt == nil {
+		return nil
+	}
+
+	if parentCheck != "" {
+		var parentLive bool
+		if err := q.QueryRowContext(ctx, parentCheck, id).Scan(&parentLive); err != nil {
+			return err
+		}
+		if !parentLive {
+			return repository.ErrParentDeleted
+		}
+	}
+
+	return setDeletedAt(ctx, db, table, id, cascades, nil, deletedAt)
+}
+
+func setDeletedAt(
+	ctx context.Context,
+	db *sql.DB,
+	table string,
+	id uuid.UUID,
+	cascades []cascadeRule,
+	to, from *time.Time,
+) error {
+	return inTx(ctx, db, func(tx *sql.Tx) error {
+		return markDeleted(ctx, tx, table, id, cascades, to, from)
+	})
+}
+
+// markDeleted is setDeletedAt within a caller's transaction.
+func markDeleted(
+	ctx context.Context,
+	tx *sql.Tx,
+	table string,
+	id uuid.UUID,
+	cascades []cascadeRule,
+	to, from *time.Time,
+) error {
+	res, err := tx.ExecContext(ctx, `
+		UPDATE `+table+` SET deleted_at = $2, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NOT DISTINCT FROM $3
+	`, id, to, from)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	for _, c := range cascades {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE `+c.table+` SET deleted_at = $2
+			WHERE (`+c.where+`) AND deleted_at IS NOT DISTINCT FROM $3
+		`, id, to, from); err != nil {
+			return err
+		}
+	}
+	return nil
+}
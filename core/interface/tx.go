@@ -0,0 +1,61 @@
+package _interface
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+)
+
+// txKey is the context key of the transaction started by Transactor.InTx.
+type txKey struct{}
+
+// dbConn is satisfied by both *sql.DB and *sql.Tx.
+type dbConn interface {
+	queryRower
+	execer
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// conn returns the transaction ctx carries, so repository calls made inside
+// Transactor.InTx join it, or db otherwise.
+func conn(ctx context.Context, db *sql.DB) dbConn {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// inTx runs fn in the transaction ctx carries or else in a new one, which is
+// committed if fn succeeds.
+func inTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(tx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+type transactorPG struct {
+	db *sql.DB
+}
+
+// NewTransactorPG returns a Transactor running PostgreSQL transactions.
+func NewTransactorPG(db *sql.DB) repository.Transactor {
+	return &transactorPG{db: db}
+}
+
+func (t *transactorPG) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return inTx(ctx, t.db, func(tx *sql.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
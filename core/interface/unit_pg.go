@@ -19,6 +19,10 @@ func NewUnitPG(db *sql.DB) repository.UnitRepository {
 }
 
 func (r *unitPG) Create(ctx context.Context, u *model.Unit) error {
+	return insertUnit(ctx, conn(ctx, r.db), u)
+}
+
+func insertUnit(ctx context.Context, db execer, u *model.Unit) error {
 	query := `
 		INSERT INTO units (
 			id, course_id, title, description, "order_index", version,
@@ -28,57 +32,98 @@ func (r *unitPG) Create(ctx context.Context, u *model.Unit) error {
 			$7, $8, $9
 		)
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := db.ExecContext(ctx, query,
 		u.ID, u.CourseID, u.Title, u.Description, u.OrderIndex, u.Version,
 		u.DeletedAt, u.CreatedAt, u.UpdatedAt,
 	)
 	return err
 }
 
+// Update writes u only if the stored version still equals u.Version, then
+// bumps u.Version to the new stored version.
 func (r *unitPG) Update(ctx context.Context, u *model.Unit) error {
 	query := `
 		UPDATE units SET
 			title = $2,
 			description = $3,
 			"order_index" = $4,
-			version = $5,
-			deleted_at = $6,
-			updated_at = $7
-		WHERE id = $1
+			version = version + 1,
+			updated_at = $6,
+			status = $7
+		WHERE id = $1 AND version = $5 AND deleted_at IS NULL
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
 		u.ID, u.Title, u.Description, u.OrderIndex, u.Version,
-		u.DeletedAt, u.UpdatedAt,
+		u.UpdatedAt, u.Status,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if err := checkVersionedUpdate(ctx, conn(ctx, r.db), "units", u.ID, res); err != nil {
+		return err
+	}
+	u.Version++
+	return nil
 }
 
+// UpdateOrder writes the order_index of each unit in one transaction,
+// with the same version check as Update.
+func (r *unitPG) UpdateOrder(ctx context.Context, units []*model.Unit) error {
+	query := `
+		UPDATE units SET order_index = $2, version = version + 1, updated_at = $4
+		WHERE id = $1 AND version = $3 AND deleted_at IS NULL
+	`
+	return updateOrder(ctx, r.db, "units", query, units,
+		func(u *model.Unit) (uuid.UUID, []any) {
+			return u.ID, []any{u.ID, u.OrderIndex, u.Version, u.UpdatedAt}
+		},
+		func(u *model.Unit) { u.Version++ },
+	)
+}
+
+// Delete soft-deletes the unit together with its skills, lessons and exercises.
 func (r *unitPG) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM units WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	return softDelete(ctx, r.db, "units", id, unitCascade)
+}
+
+// Restore undoes Delete as long as the owning course is not deleted.
+func (r *unitPG) Restore(ctx context.Context, id uuid.UUID) error {
+	parentCheck := `
+		SELECT c.deleted_at IS NULL FROM units u
+		JOIN courses c ON c.id = u.course_id
+		WHERE u.id = $1
+	`
+	return restore(ctx, r.db, "units", id, parentCheck, unitCascade)
 }
 
-func (r *unitPG) GetByID(ctx context.Context, id uuid.UUID) (*model.Unit, error) {
+func (r *unitPG) GetByID(
+	ctx context.Context,
+	id uuid.UUID,
+	includeDeleted bool,
+) (*model.Unit, error) {
 	query := `
-		SELECT id, course_id, title, description, "order_index", version,
+		SELECT id, course_id, title, description, "order_index", version, status,
 		       deleted_at, created_at, updated_at
 		FROM units
-		WHERE id = $1
+		WHERE id = $1 AND ($2 OR deleted_at IS NULL)
 	`
-	row := r.db.QueryRowContext(ctx, query, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, id, includeDeleted)
 	return scanUnit(row)
 }
 
-func (r *unitPG) ListByCourseID(ctx context.Context, courseID uuid.UUID) ([]*model.Unit, error) {
+func (r *unitPG) ListByCourseID(
+	ctx context.Context,
+	courseID uuid.UUID,
+	includeDeleted bool,
+) ([]*model.Unit, error) {
 	query := `
-		SELECT id, course_id, title, description, "order_index", version,
+		SELECT id, course_id, title, description, "order_index", version, status,
 		       deleted_at, created_at, updated_at
 		FROM units
-		WHERE course_id = $1
+		WHERE course_id = $1 AND ($2 OR deleted_at IS NULL)
 		ORDER BY "order_index"
 	`
-	rows, err := r.db.QueryContext(ctx, query, courseID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, courseID, includeDeleted)
 	if err != nil {
 		return nil, err
 	}
@@ -95,6 +140,30 @@ func (r *unitPG) ListByCourseID(ctx context.Context, courseID uuid.UUID) ([]*mod
 	return units, nil
 }
 
+var unitListSpec = listSpec{
+	table:       "units",
+	columns:     `id, course_id, title, description, "order_index", version, status, deleted_at, created_at, updated_at`,
+	parent:      "course_id",
+	defaultSort: "order_index",
+	sorts:       []string{"title", "created_at", "updated_at", "order_index"},
+	filters:     []string{filterStatus},
+}
+
+func (r *unitPG) List(
+	ctx context.Context,
+	q repository.ListQuery,
+) (*repository.Page[*model.Unit], error) {
+	return queryPage(ctx, conn(ctx, r.db), unitListSpec, q, scanUnit,
+		func(u *model.Unit) (uuid.UUID, sortKeys) {
+			return u.ID, sortKeys{
+				title:      u.Title,
+				createdAt:  u.CreatedAt,
+				updatedAt:  u.UpdatedAt,
+				orderIndex: u.OrderIndex,
+			}
+		})
+}
+
 func scanUnit(scanner interface {
 	Scan(dest ...any) error
 }) (*model.Unit, error) {
@@ -106,6 +175,7 @@ func scanUnit(scanner interface {
 		&u.Description,
 		&u.OrderIndex,
 		&u.Version,
+		&u.Status,
 		&u.DeletedAt,
 		&u.CreatedAt,
 		&u.UpdatedAt,
@@ -0,0 +1,85 @@
+package _interface
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/google/uuid"
+)
+
+// queryRower is satisfied by both *sql.DB and *sql.Tx.
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// checkVersionedUpdate turns the result of an
+// `UPDATE ... WHERE id = $1 AND version = $n` into the right error: nil when a
+// row was written, sql.ErrNoRows when the row is gone, and
+// repository.ErrVersionConflict when someone else saved first.
+func checkVersionedUpdate(
+	ctx context.Context,
+	db queryRower,
+	table string,
+	id uuid.UUID,
+	res sql.Result,
+) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+
+	var exists bool
+	err = db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM `+table+` WHERE id = $1 AND deleted_at IS NULL)`, id,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+	return repository.ErrVersionConflict
+}
+
+// updateOrder runs a versioned position UPDATE for each item in one
+// transaction. args returns the item's ID and the query arguments; bump is
+// called on every item once all rows are written.
+func updateOrder[T any](
+	ctx context.Context,
+	db *sql.DB,
+	table string,
+	query string,
+	items []T,
+	args func(T) (uuid.UUID, []any),
+	bump func(T),
+) error {
+	err := inTx(ctx, db, func(tx *sql.Tx) error {
+		for _, item := range items {
+			id, a := args(item)
+			res, err := tx.ExecContext(ctx, query, a...)
+			if err != nil {
+				return err
+			}
+			if err := checkVersionedUpdate(ctx, tx, table, id, res); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		bump(item)
+	}
+	return nil
+}
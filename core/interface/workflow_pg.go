@@ -0,0 +1,92 @@
+package _interface
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/google/uuid"
+)
+
+// workflowTables maps each content type to its table.
+var workflowTables = map[model.EntityType]string{
+	model.EntityCourse:   "courses",
+	model.EntityUnit:     "units",
+	model.EntitySkill:    "skills",
+	model.EntityLesson:   "lessons",
+	model.EntityExercise: "exercises",
+}
+
+type workflowPG struct {
+	db *sql.DB
+}
+
+// NewWorkflowPG returns a PostgreSQL-backed WorkflowRepository.
+func NewWorkflowPG(db *sql.DB) repository.WorkflowRepository {
+	return &workflowPG{db: db}
+}
+
+func (r *workflowPG) SetStatus(
+	ctx context.Context,
+	changes []model.StatusChange,
+	at time.Time,
+) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		for _, ch := range changes {
+			table, ok := workflowTables[ch.EntityType]
+			if !ok {
+				return fmt.Errorf("unknown entity type %q", ch.EntityType)
+			}
+			set := "status = $3"
+			if ch.EntityType == model.EntityCourse {
+				// is_published mirrors the workflow for existing readers
+				set += ", is_published = ($3 = 'published')"
+			}
+			query := `UPDATE ` + table + ` SET ` + set + `, version = version + 1, updated_at = $4
+				WHERE id = $1 AND status = $2 AND version = $5 AND deleted_at IS NULL`
+
+			res, err := tx.ExecContext(ctx, query, ch.EntityID, ch.From, ch.To, at, ch.Version)
+			if err != nil {
+				return err
+			}
+			n, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if n == 0 {
+				return fmt.Errorf("%w: %s %s changed or is no longer %s",
+					repository.ErrVersionConflict, ch.EntityType, ch.EntityID, ch.From)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *workflowPG) LockStatus(
+	ctx context.Context,
+	entityType model.EntityType,
+	id uuid.UUID,
+	status model.WorkflowState,
+	version int,
+) error {
+	table, ok := workflowTables[entityType]
+	if !ok {
+		return fmt.Errorf("unknown entity type %q", entityType)
+	}
+	query := `SELECT id FROM ` + table + `
+		WHERE id = $1 AND status = $2 AND version = $3 AND deleted_at IS NULL
+		FOR SHARE`
+
+	var locked uuid.UUID
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id, status, version).Scan(&locked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %s %s changed or is no longer %s",
+			repository.ErrVersionConflict, entityType, id, status)
+	}
+	return err
+}
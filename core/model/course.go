@@ -25,13 +25,21 @@ type Course struct {
 	Difficulty  DifficultyLevel `json:"difficulty"`
 	IsPublished bool            `json:"is_published"`
 
+	// Scheduled workflow transitions, applied by the scheduler once due,
+	// with the user who scheduled each one.
+	PublishAt            *time.Time `json:"publish_at,omitempty"`
+	UnpublishAt          *time.Time `json:"unpublish_at,omitempty"`
+	PublishScheduledBy   *uuid.UUID `json:"publish_scheduled_by,omitempty"`
+	UnpublishScheduledBy *uuid.UUID `json:"unpublish_scheduled_by,omitempty"`
+
 	Tags     []string       `json:"tags,omitempty"`
 	Metadata map[string]any `json:"metadata,omitempty"`
 
-	Version   int        `json:"version"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
+	Version   int           `json:"version"`
+	Status    WorkflowState `json:"status"`
+	DeletedAt *time.Time    `json:"deleted_at,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
 
 	CreatorID *uuid.UUID `json:"creator_id,omitempty"`
 }
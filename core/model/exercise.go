@@ -46,6 +46,8 @@ type Exercise struct {
 	Syllabus     string        `json:"syllabus"`                // e.g. "ABRSM", "Trinity"
 	ObjectiveTag string        `json:"objective"`               // e.g. "intervals", "notation", etc.
 	Metadata     JSONB         `json:"metadata"`                // flexible config per exercise type
+	Version      int           `json:"version"`
+	Status       WorkflowState `json:"status"`
 	CreatedAt    time.Time     `json:"created_at"`
 	UpdatedAt    time.Time     `json:"updated_at"`
 	DeletedAt    *time.Time    `json:"deleted_at,omitempty"`
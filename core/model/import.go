@@ -0,0 +1,46 @@
+package model
+
+import "github.com/google/uuid"
+
+// ImportAction is what an import does to one entity.
+type ImportAction string
+
+const (
+	ImportCreate ImportAction = "create"
+	ImportUpdate ImportAction = "update"
+	ImportDelete ImportAction = "delete"
+)
+
+// ImportChange is one entity an import creates, updates or deletes. Key is
+// the slug of a course, skill or lesson and the title of a unit or exercise.
+type ImportChange struct {
+	Action     ImportAction
+	EntityType EntityType
+	EntityID   uuid.UUID
+	Key        string
+	Fields     []string // what an update changes
+}
+
+// EntityRef points at one content entity.
+type EntityRef struct {
+	EntityType EntityType
+	EntityID   uuid.UUID
+}
+
+// ImportPlan is everything an import writes. Entities in Created are
+// inserted and the others updated, guarded by their version; unchanged
+// entities are left out. Options replace the whole option set of an
+// exercise. Deletes holds only the topmost deleted entities, since their
+// descendants are deleted with them.
+type ImportPlan struct {
+	CourseID  uuid.UUID
+	Course    *Course // nil when the course itself is unchanged
+	Units     []*Unit
+	Skills    []*Skill
+	Lessons   []*Lesson
+	Exercises []*Exercise
+	Options   map[uuid.UUID][]*ExerciseOption
+	Created   map[uuid.UUID]bool
+	Deletes   []EntityRef
+	Changes   []ImportChange
+}
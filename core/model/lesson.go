@@ -26,6 +26,7 @@ type Lesson struct {
 	Tags              []string       `json:"tags"`
 	Metadata          map[string]any `json:"metadata"`
 	Version           int            `json:"version"`
+	Status            WorkflowState  `json:"status"`
 	DeletedAt         *time.Time     `json:"deleted_at,omitempty"`
 	CreatedAt         time.Time      `json:"created_at"`
 	UpdatedAt         time.Time      `json:"updated_at"`
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MediaAsset is an uploaded audio or image file. Exercises and options use
+// it by setting their media_url to the asset's URL, which carries its ID.
+type MediaAsset struct {
+	ID          uuid.UUID
+	FileName    string // as uploaded
+	ContentType string
+	Size        int64  // bytes
+	Checksum    string // "sha256:<hex>" of the content
+	ObjectPath  string // where the file lives in storage
+	URL         string
+	DurationMS  *int // audio only, when the format is understood
+	UploadedBy  *uuid.UUID
+	CreatedAt   time.Time
+
+	// Audio is set for audio in a format that could be analysed
+	Audio *AudioAnalysis
+
+	// Width and Height are the upright size of an image that could be
+	// decoded; Variants are its resized copies
+	Width    *int
+	Height   *int
+	Variants []MediaVariant
+}
+
+// MediaVariant is a resized copy of an image asset in one format, without
+// the original's EXIF data.
+type MediaVariant struct {
+	Name       string `json:"name"`   // thumbnail, mobile or full
+	Format     string `json:"format"` // png or jpeg
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	Size       int64  `json:"size"` // bytes
+	ObjectPath string `json:"object_path"`
+	URL        string `json:"url"`
+}
+
+// AudioAnalysis describes the sound of an audio asset so authors can check
+// it without playing it.
+type AudioAnalysis struct {
+	SampleRate int
+	Channels   int
+	PeakDB     float64 // dBFS
+	RMSDB      float64 // dBFS
+	// Waveform is a downsampled envelope of peak levels between 0 and 1
+	Waveform []float64
+	// LoudnessFlags name the ways the audio is out of line with other
+	// exercise audio: silent, clipping, too_quiet or too_loud
+	LoudnessFlags []string
+}
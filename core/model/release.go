@@ -0,0 +1,117 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReleaseChannel names a track learner apps follow.
+type ReleaseChannel string
+
+const (
+	ChannelBeta   ReleaseChannel = "beta"
+	ChannelStable ReleaseChannel = "stable"
+)
+
+// ReleaseChannels lists every channel a release can be promoted to.
+var ReleaseChannels = []ReleaseChannel{ChannelBeta, ChannelStable}
+
+// Release is an immutable snapshot of the published content of a course.
+// Versions count up from 1 per course; ContentHash identifies the content.
+type Release struct {
+	ID          uuid.UUID
+	CourseID    uuid.UUID
+	Version     int
+	ContentHash string
+	Content     *ReleaseContent // nil in listings
+	Notes       string
+	Channels    []ReleaseChannel // channels currently pointing at this release
+	CreatedBy   *uuid.UUID
+	CreatedAt   time.Time
+}
+
+// ReleaseContent is the frozen curriculum served to learner apps: the
+// published units, skills, lessons, exercises and options of a course, in
+// order. It carries content only; versions, workflow status and timestamps
+// are left out so that the same content always hashes the same.
+type ReleaseContent struct {
+	ID          uuid.UUID       `json:"id"`
+	Slug        string          `json:"slug"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Language    string          `json:"language"`
+	Difficulty  DifficultyLevel `json:"difficulty"`
+	Tags        []string        `json:"tags"`
+	Metadata    map[string]any  `json:"metadata"`
+	Units       []*ReleaseUnit  `json:"units"`
+}
+
+type ReleaseUnit struct {
+	ID          uuid.UUID       `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	OrderIndex  int             `json:"order_index"`
+	Skills      []*ReleaseSkill `json:"skills"`
+}
+
+type ReleaseSkill struct {
+	ID                   uuid.UUID        `json:"id"`
+	Slug                 string           `json:"slug"`
+	Title                string           `json:"title"`
+	Icon                 string           `json:"icon"`
+	OrderIndex           int              `json:"order_index"`
+	Difficulty           int              `json:"difficulty"`
+	MaxCrowns            int              `json:"max_crowns"`
+	BaseXPReward         int              `json:"base_xp_reward"`
+	XPPerCrown           int              `json:"xp_per_crown"`
+	PrerequisiteSkillIDs []uuid.UUID      `json:"prerequisite_skill_ids"`
+	Tags                 []string         `json:"tags"`
+	Metadata             map[string]any   `json:"metadata"`
+	Lessons              []*ReleaseLesson `json:"lessons"`
+}
+
+type ReleaseLesson struct {
+	ID                uuid.UUID          `json:"id"`
+	Slug              string             `json:"slug"`
+	Title             string             `json:"title"`
+	Description       string             `json:"description"`
+	OrderIndex        int                `json:"order_index"`
+	TotalExercises    int                `json:"total_exercises"`
+	BaseXP            int                `json:"base_xp"`
+	BonusXP           int                `json:"bonus_xp"`
+	RewardGems        int                `json:"reward_gems"`
+	RewardHearts      int                `json:"reward_hearts"`
+	RewardCondition   string             `json:"reward_condition"`
+	EstimatedDuration int                `json:"estimated_duration"`
+	DifficultyRating  float32            `json:"difficulty_rating"`
+	IsTestable        bool               `json:"is_testable"`
+	Tags              []string           `json:"tags"`
+	Metadata          map[string]any     `json:"metadata"`
+	Exercises         []*ReleaseExercise `json:"exercises"`
+}
+
+type ReleaseExercise struct {
+	ID           uuid.UUID        `json:"id"`
+	Title        string           `json:"title"`
+	Type         ExerciseType     `json:"type"`
+	MatchingType *MatchingType    `json:"matching_type,omitempty"`
+	Prompt       string           `json:"prompt"`
+	MediaURL     *string          `json:"media_url,omitempty"`
+	OrderIndex   int              `json:"order_index"`
+	Points       int              `json:"points"`
+	Grade        int              `json:"grade"`
+	Syllabus     string           `json:"syllabus"`
+	ObjectiveTag string           `json:"objective"`
+	Metadata     JSONB            `json:"metadata"`
+	Options      []*ReleaseOption `json:"options"`
+}
+
+type ReleaseOption struct {
+	ID         uuid.UUID `json:"id"`
+	Label      string    `json:"label"`
+	Value      string    `json:"value"`
+	IsCorrect  bool      `json:"is_correct"`
+	MediaURL   *string   `json:"media_url,omitempty"`
+	OrderIndex int       `json:"order_index"`
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EntityType names a kind of versioned content.
+type EntityType string
+
+const (
+	EntityCourse   EntityType = "course"
+	EntityUnit     EntityType = "unit"
+	EntitySkill    EntityType = "skill"
+	EntityLesson   EntityType = "lesson"
+	EntityExercise EntityType = "exercise"
+)
+
+// Revision is the full snapshot of a content entity at one version.
+type Revision struct {
+	ID         uuid.UUID  `json:"id"`
+	EntityType EntityType `json:"entity_type"`
+	EntityID   uuid.UUID  `json:"entity_id"`
+	Version    int        `json:"version"`
+	Snapshot   JSONB      `json:"snapshot,omitempty"` // the entity as JSON
+	AuthorID   *uuid.UUID `json:"author_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import "github.com/google/uuid"
+
+// Breadcrumb is one ancestor on the path to a search hit.
+type Breadcrumb struct {
+	EntityType EntityType `json:"type"`
+	ID         uuid.UUID  `json:"id"`
+	Title      string     `json:"title"`
+}
+
+// SearchHit is a course, unit, skill, lesson or exercise matching a search.
+type SearchHit struct {
+	EntityType  EntityType   `json:"type"`
+	ID          uuid.UUID    `json:"id"`
+	Title       string       `json:"title"`
+	Highlight   string       `json:"highlight"` // matched text as escaped HTML, terms wrapped in <mark>
+	Rank        float64      `json:"rank"`
+	Breadcrumbs []Breadcrumb `json:"breadcrumbs"` // course › unit › skill › lesson, outermost first
+}
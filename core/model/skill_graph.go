@@ -0,0 +1,34 @@
+package model
+
+import "github.com/google/uuid"
+
+// SkillGraph is the prerequisite graph of a course's skills.
+//
+// UnlockOrder lists the skills a learner can reach in an order that respects
+// every prerequisite. Unreachable skills can never be unlocked, because they
+// depend on a cycle or on a skill that no longer exists. Orphaned skills take
+// no part in the graph: they have no prerequisites and nothing depends on them.
+type SkillGraph struct {
+	CourseID    uuid.UUID
+	Nodes       []*SkillGraphNode
+	Edges       []SkillGraphEdge
+	UnlockOrder []uuid.UUID
+	Unreachable []uuid.UUID
+	Orphaned    []uuid.UUID
+}
+
+// SkillGraphNode is one skill of the graph. Depth is the length of the longest
+// prerequisite chain leading to the skill (0 for a skill with no
+// prerequisites) and is nil when the skill is unreachable.
+type SkillGraphNode struct {
+	Skill                  *Skill
+	Depth                  *int
+	MissingPrerequisiteIDs []uuid.UUID
+	Orphaned               bool
+}
+
+// SkillGraphEdge points from a prerequisite to the skill it unlocks.
+type SkillGraphEdge struct {
+	From uuid.UUID
+	To   uuid.UUID
+}
@@ -0,0 +1,64 @@
+package model
+
+// TreeStats aggregates the content beneath a node of a course tree.
+// EstimatedDuration is the sum of the lessons' estimated durations.
+type TreeStats struct {
+	Units             int `json:"units,omitempty"`
+	Skills            int `json:"skills,omitempty"`
+	Lessons           int `json:"lessons"`
+	Exercises         int `json:"exercises"`
+	EstimatedDuration int `json:"estimated_duration"`
+}
+
+func (s *TreeStats) add(o TreeStats) {
+	s.Units += o.Units
+	s.Skills += o.Skills
+	s.Lessons += o.Lessons
+	s.Exercises += o.Exercises
+	s.EstimatedDuration += o.EstimatedDuration
+}
+
+// CourseTree is a course with its nested units, skills, lessons and exercises.
+// Levels below the requested depth are left nil; stats always cover the
+// whole hierarchy.
+type CourseTree struct {
+	Course *Course
+	Units  []*UnitNode
+	Stats  TreeStats
+}
+
+type UnitNode struct {
+	Unit   *Unit
+	Skills []*SkillNode
+	Stats  TreeStats
+}
+
+type SkillNode struct {
+	Skill   *Skill
+	Lessons []*LessonNode
+	Stats   TreeStats
+}
+
+type LessonNode struct {
+	Lesson    *Lesson
+	Exercises []*Exercise
+	Stats     TreeStats
+}
+
+// AddChild rolls a child's stats into the node's own.
+func (n *UnitNode) AddChild(child *SkillNode) {
+	n.Stats.Skills++
+	n.Stats.add(child.Stats)
+}
+
+// AddChild rolls a child's stats into the node's own.
+func (n *SkillNode) AddChild(child *LessonNode) {
+	n.Stats.Lessons++
+	n.Stats.add(child.Stats)
+}
+
+// AddChild rolls a child's stats into the tree's totals.
+func (t *CourseTree) AddChild(child *UnitNode) {
+	t.Stats.Units++
+	t.Stats.add(child.Stats)
+}
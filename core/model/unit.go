@@ -7,14 +7,15 @@ import (
 )
 
 type Unit struct {
-	ID          uuid.UUID  `json:"id"`
-	CourseID    uuid.UUID  `json:"course_id"`
-	Title       string     `json:"title"`
-	Description string     `json:"description,omitempty"`
-	OrderIndex  int        `json:"order_index"`
-	Version     int        `json:"version"`
-	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID          uuid.UUID     `json:"id"`
+	CourseID    uuid.UUID     `json:"course_id"`
+	Title       string        `json:"title"`
+	Description string        `json:"description,omitempty"`
+	OrderIndex  int           `json:"order_index"`
+	Version     int           `json:"version"`
+	Status      WorkflowState `json:"status"`
+	DeletedAt   *time.Time    `json:"deleted_at,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
 }
 
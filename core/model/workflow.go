@@ -0,0 +1,26 @@
+package model
+
+import "github.com/google/uuid"
+
+// WorkflowState is the publishing state of a course, unit, skill, lesson or
+// exercise. Content moves draft → in_review → approved → published →
+// archived; see package workflow for the allowed transitions.
+type WorkflowState string
+
+const (
+	StateDraft     WorkflowState = "draft"
+	StateInReview  WorkflowState = "in_review"
+	StateApproved  WorkflowState = "approved"
+	StatePublished WorkflowState = "published"
+	StateArchived  WorkflowState = "archived"
+)
+
+// StatusChange records one entity moving between workflow states. Version
+// is the entity's version the change was checked against.
+type StatusChange struct {
+	EntityType EntityType    `json:"entity_type"`
+	EntityID   uuid.UUID     `json:"entity_id"`
+	From       WorkflowState `json:"from"`
+	To         WorkflowState `json:"to"`
+	Version    int           `json:"version"`
+}
@@ -0,0 +1,213 @@
+// Package pack bundles a course release and its media into a zip that
+// learner apps can use offline.
+package pack
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/google/uuid"
+)
+
+// FormatVersion is bumped whenever the layout of a pack changes.
+const FormatVersion = 1
+
+// ManifestName is the manifest's path in the zip. It is written last, once
+// the checksums of every other entry are known.
+const ManifestName = "manifest.json"
+
+// Media gives access to the stored media files a release references.
+type Media struct {
+	// Stored reports whether a URL points into storage; others are not bundled.
+	Stored func(url string) bool
+	// Open streams a stored media file.
+	Open func(ctx context.Context, url string) (io.ReadCloser, error)
+}
+
+// Manifest describes a pack: the course content of one release and where
+// each of its media files lives in the zip.
+type Manifest struct {
+	Format      int                   `json:"format"`
+	CourseID    uuid.UUID             `json:"course_id"`
+	Version     int                   `json:"version"`
+	ContentHash string                `json:"content_hash"`
+	BaseVersion *int                  `json:"base_version,omitempty"` // set on delta packs
+	CreatedAt   time.Time             `json:"created_at"`
+	Content     *model.ReleaseContent `json:"content"`
+
+	// Media maps every stored media URL of the release to its path in a
+	// full pack. A delta pack only holds files new since the base version.
+	Media map[string]string `json:"media"`
+	// Files lists the media entries of this zip with their checksums.
+	Files []File `json:"files"`
+	// Removed lists paths of the base version's media that are no longer used.
+	Removed []string `json:"removed,omitempty"`
+	// External lists media URLs outside storage, which are not bundled.
+	External []string `json:"external,omitempty"`
+	// Missing lists stored media that could not be fetched. A delta pack
+	// also lists media of the base version that cannot be fetched now; the
+	// base pack could not have held it either.
+	Missing []Missing `json:"missing,omitempty"`
+}
+
+// File is one media entry of the zip.
+type File struct {
+	Path   string `json:"path"`
+	URL    string `json:"url"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Missing is a stored media file left out of the pack.
+type Missing struct {
+	URL   string `json:"url"`
+	Error string `json:"error"`
+}
+
+// Write streams the pack of rel to w. When base is set the pack is a delta
+// containing only media not already referenced by base. Media that cannot
+// be fetched is listed in the manifest rather than failing the pack, since
+// part of the zip has already been sent by then.
+//
+// A delta leaves out base media as long as it can be fetched, so it is only
+// complete on top of a base pack without Missing entries; clients holding
+// one with Missing entries must download a full pack instead.
+func Write(
+	ctx context.Context,
+	w io.Writer,
+	rel *model.Release,
+	base *model.Release,
+	media Media,
+) error {
+	m := &Manifest{
+		Format:      FormatVersion,
+		CourseID:    rel.CourseID,
+		Version:     rel.Version,
+		ContentHash: rel.ContentHash,
+		CreatedAt:   time.Now().UTC(),
+		Content:     rel.Content,
+		Media:       map[string]string{},
+		Files:       []File{},
+	}
+
+	have := map[string]bool{}
+	if base != nil {
+		m.BaseVersion = &base.Version
+		for _, url := range MediaURLs(base.Content) {
+			have[url] = true
+		}
+	}
+
+	zw := zip.NewWriter(w)
+	for _, url := range MediaURLs(rel.Content) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if !media.Stored(url) {
+			m.External = append(m.External, url)
+			continue
+		}
+		m.Media[url] = mediaPath(url)
+
+		body, err := media.Open(ctx, url)
+		if err != nil {
+			m.Missing = append(m.Missing, Missing{URL: url, Error: err.Error()})
+			continue
+		}
+		if have[url] {
+			body.Close()
+			continue
+		}
+
+		file, err := writeEntry(zw, m.Media[url], body)
+		body.Close()
+		if err != nil {
+			return err
+		}
+		file.URL = url
+		m.Files = append(m.Files, file)
+	}
+
+	if base != nil {
+		for _, url := range MediaURLs(base.Content) {
+			if _, used := m.Media[url]; !used && media.Stored(url) {
+				m.Removed = append(m.Removed, mediaPath(url))
+			}
+		}
+	}
+
+	manifest, err := zw.Create(ManifestName)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(manifest)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(m); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// MediaURLs returns the distinct media URLs of exercises and their options,
+// sorted.
+func MediaURLs(content *model.ReleaseContent) []string {
+	seen := map[string]bool{}
+	add := func(url *string) {
+		if url != nil && *url != "" {
+			seen[*url] = true
+		}
+	}
+	for _, u := range content.Units {
+		for _, s := range u.Skills {
+			for _, l := range s.Lessons {
+				for _, e := range l.Exercises {
+					add(e.MediaURL)
+					for _, o := range e.Options {
+						add(o.MediaURL)
+					}
+				}
+			}
+		}
+	}
+
+	urls := make([]string, 0, len(seen))
+	for url := range seen {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// mediaPath names a media file in the zip after a hash of its URL, keeping
+// the extension, so the same URL has the same path in every pack.
+func mediaPath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	ext := path.Ext(strings.SplitN(url, "?", 2)[0])
+	if len(ext) > 8 {
+		ext = ""
+	}
+	return "media/" + hex.EncodeToString(sum[:16]) + strings.ToLower(ext)
+}
+
+func writeEntry(zw *zip.Writer, name string, body io.Reader) (File, error) {
+	entry, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+	if err != nil {
+		return File{}, err
+	}
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(entry, hash), body)
+	if err != nil {
+		return File{}, err
+	}
+	return File{Path: name, Size: n, SHA256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
@@ -0,0 +1,182 @@
+// Package portable converts courses to and from a human-editable document,
+// in JSON or YAML, that refers to content by slug rather than by ID so it can
+// be kept in git and imported into any environment.
+package portable
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"gopkg.in/yaml.v3"
+)
+
+// FormatVersion is bumped whenever the document layout changes.
+const FormatVersion = 1
+
+// Format names a document encoding.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// Document is a whole course. Skills and lessons are identified by slug,
+// units by title and exercises by title within their lesson; order follows
+// the order of each list. Workflow status and IDs are not part of it.
+type Document struct {
+	Format      int                   `json:"format" yaml:"format"`
+	Slug        string                `json:"slug" yaml:"slug"`
+	Title       string                `json:"title" yaml:"title"`
+	Description string                `json:"description,omitempty" yaml:"description,omitempty"`
+	Language    string                `json:"language,omitempty" yaml:"language,omitempty"`
+	Difficulty  model.DifficultyLevel `json:"difficulty,omitempty" yaml:"difficulty,omitempty"`
+	Tags        []string              `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Metadata    map[string]any        `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Units       []*Unit               `json:"units" yaml:"units"`
+}
+
+type Unit struct {
+	Title       string   `json:"title" yaml:"title"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Skills      []*Skill `json:"skills" yaml:"skills"`
+}
+
+type Skill struct {
+	Slug          string         `json:"slug" yaml:"slug"`
+	Title         string         `json:"title" yaml:"title"`
+	Icon          string         `json:"icon,omitempty" yaml:"icon,omitempty"`
+	Difficulty    int            `json:"difficulty,omitempty" yaml:"difficulty,omitempty"`
+	MaxCrowns     int            `json:"max_crowns,omitempty" yaml:"max_crowns,omitempty"`
+	BaseXPReward  int            `json:"base_xp_reward,omitempty" yaml:"base_xp_reward,omitempty"`
+	XPPerCrown    int            `json:"xp_per_crown,omitempty" yaml:"xp_per_crown,omitempty"`
+	Prerequisites []string       `json:"prerequisites,omitempty" yaml:"prerequisites,omitempty"` // skill slugs
+	Tags          []string       `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Metadata      map[string]any `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Lessons       []*Lesson      `json:"lessons" yaml:"lessons"`
+}
+
+type Lesson struct {
+	Slug              string         `json:"slug" yaml:"slug"`
+	Title             string         `json:"title" yaml:"title"`
+	Description       string         `json:"description,omitempty" yaml:"description,omitempty"`
+	TotalExercises    int            `json:"total_exercises,omitempty" yaml:"total_exercises,omitempty"`
+	BaseXP            int            `json:"base_xp,omitempty" yaml:"base_xp,omitempty"`
+	BonusXP           int            `json:"bonus_xp,omitempty" yaml:"bonus_xp,omitempty"`
+	RewardGems        int            `json:"reward_gems,omitempty" yaml:"reward_gems,omitempty"`
+	RewardHearts      int            `json:"reward_hearts,omitempty" yaml:"reward_hearts,omitempty"`
+	RewardCondition   string         `json:"reward_condition,omitempty" yaml:"reward_condition,omitempty"`
+	EstimatedDuration int            `json:"estimated_duration,omitempty" yaml:"estimated_duration,omitempty"`
+	DifficultyRating  float32        `json:"difficulty_rating,omitempty" yaml:"difficulty_rating,omitempty"`
+	IsTestable        bool           `json:"is_testable,omitempty" yaml:"is_testable,omitempty"`
+	Tags              []string       `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Metadata          map[string]any `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Exercises         []*Exercise    `json:"exercises" yaml:"exercises"`
+}
+
+type Exercise struct {
+	Title        string              `json:"title" yaml:"title"`
+	Type         model.ExerciseType  `json:"type" yaml:"type"`
+	MatchingType *model.MatchingType `json:"matching_type,omitempty" yaml:"matching_type,omitempty"`
+	Prompt       string              `json:"prompt,omitempty" yaml:"prompt,omitempty"`
+	MediaURL     *string             `json:"media_url,omitempty" yaml:"media_url,omitempty"`
+	Points       int                 `json:"points,omitempty" yaml:"points,omitempty"`
+	Grade        int                 `json:"grade,omitempty" yaml:"grade,omitempty"`
+	Syllabus     string              `json:"syllabus,omitempty" yaml:"syllabus,omitempty"`
+	Objective    string              `json:"objective,omitempty" yaml:"objective,omitempty"`
+	Metadata     map[string]any      `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Options      []*Option           `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+type Option struct {
+	Label     string  `json:"label" yaml:"label"`
+	Value     string  `json:"value,omitempty" yaml:"value,omitempty"`
+	IsCorrect bool    `json:"is_correct,omitempty" yaml:"is_correct,omitempty"`
+	MediaURL  *string `json:"media_url,omitempty" yaml:"media_url,omitempty"`
+}
+
+// ParseFormat reads a ?format= value; an empty value means JSON.
+func ParseFormat(s string) (Format, error) {
+	switch s {
+	case "", "json":
+		return FormatJSON, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	default:
+		return "", fmt.Errorf("unknown format %q: must be json or yaml", s)
+	}
+}
+
+// Encode writes doc to w.
+func Encode(w io.Writer, doc *Document, format Format) error {
+	if format == FormatYAML {
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// Decode reads a document, rejecting unknown fields so that typos do not
+// silently drop content. Metadata is normalised to what JSON decoding
+// produces, so YAML and JSON documents compare alike.
+func Decode(r io.Reader, format Format) (*Document, error) {
+	var doc Document
+	if format == FormatYAML {
+		dec := yaml.NewDecoder(r)
+		dec.KnownFields(true)
+		if err := dec.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("invalid YAML document: %w", err)
+		}
+	} else {
+		dec := json.NewDecoder(r)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("invalid JSON document: %w", err)
+		}
+	}
+
+	if err := normalizeMetadata(&doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func normalizeMetadata(doc *Document) error {
+	var err error
+	normalize := func(m *map[string]any) {
+		if *m == nil || err != nil {
+			return
+		}
+		var buf bytes.Buffer
+		if err = json.NewEncoder(&buf).Encode(*m); err != nil {
+			err = fmt.Errorf("metadata must be JSON compatible: %w", err)
+			return
+		}
+		*m = nil
+		err = json.Unmarshal(buf.Bytes(), m)
+	}
+
+	normalize(&doc.Metadata)
+	for _, u := range doc.Units {
+		for _, s := range u.Skills {
+			normalize(&s.Metadata)
+			for _, l := range s.Lessons {
+				normalize(&l.Metadata)
+				for _, e := range l.Exercises {
+					normalize(&e.Metadata)
+				}
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,129 @@
+package portable
+
+import (
+	"fmt"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/validation"
+	"github.com/google/uuid"
+)
+
+// Export builds the document of a full course tree, as built with every
+// level, whatever the workflow status of its content. options holds the
+// options of the course's exercises in order.
+//
+// Prerequisites are named by slug, so a skill requiring a skill of another
+// course cannot be exported; Export returns validation.Errors naming each
+// such prerequisite by its path rather than leave it out.
+func Export(tree *model.CourseTree, options []*model.ExerciseOption) (*Document, error) {
+	optionsByExercise := map[uuid.UUID][]*Option{}
+	for _, o := range options {
+		optionsByExercise[o.ExerciseID] = append(optionsByExercise[o.ExerciseID], &Option{
+			Label:     o.Label,
+			Value:     o.Value,
+			IsCorrect: o.IsCorrect,
+			MediaURL:  o.MediaURL,
+		})
+	}
+
+	slugs := map[uuid.UUID]string{}
+	for _, un := range tree.Units {
+		for _, sn := range un.Skills {
+			slugs[sn.Skill.ID] = sn.Skill.Slug
+		}
+	}
+
+	c := tree.Course
+	doc := &Document{
+		Format:      FormatVersion,
+		Slug:        c.Slug,
+		Title:       c.Title,
+		Description: c.Description,
+		Language:    c.Language,
+		Difficulty:  c.Difficulty,
+		Tags:        c.Tags,
+		Metadata:    c.Metadata,
+		Units:       []*Unit{},
+	}
+
+	var errs validation.Errors
+	for i, un := range tree.Units {
+		unit := &Unit{
+			Title:       un.Unit.Title,
+			Description: un.Unit.Description,
+			Skills:      []*Skill{},
+		}
+		doc.Units = append(doc.Units, unit)
+
+		for j, sn := range un.Skills {
+			s := sn.Skill
+			var prereqs []string
+			for k, id := range s.PrerequisiteSkillIDs {
+				slug, ok := slugs[id]
+				if !ok {
+					errs.Add(fmt.Sprintf("units[%d].skills[%d].prerequisites[%d]", i, j, k),
+						"skill '%s' requires skill %s outside the course", s.Slug, id)
+					continue
+				}
+				prereqs = append(prereqs, slug)
+			}
+			skill := &Skill{
+				Slug:          s.Slug,
+				Title:         s.Title,
+				Icon:          s.Icon,
+				Difficulty:    s.Difficulty,
+				MaxCrowns:     s.MaxCrowns,
+				BaseXPReward:  s.BaseXPReward,
+				XPPerCrown:    s.XPPerCrown,
+				Prerequisites: prereqs,
+				Tags:          s.Tags,
+				Metadata:      s.Metadata,
+				Lessons:       []*Lesson{},
+			}
+			unit.Skills = append(unit.Skills, skill)
+
+			for _, ln := range sn.Lessons {
+				l := ln.Lesson
+				lesson := &Lesson{
+					Slug:              l.Slug,
+					Title:             l.Title,
+					Description:       l.Description,
+					TotalExercises:    l.TotalExercises,
+					BaseXP:            l.BaseXP,
+					BonusXP:           l.BonusXP,
+					RewardGems:        l.RewardGems,
+					RewardHearts:      l.RewardHearts,
+					RewardCondition:   l.RewardCondition,
+					EstimatedDuration: l.EstimatedDuration,
+					DifficultyRating:  l.DifficultyRating,
+					IsTestable:        l.IsTestable,
+					Tags:              l.Tags,
+					Metadata:          l.Metadata,
+					Exercises:         []*Exercise{},
+				}
+				skill.Lessons = append(skill.Lessons, lesson)
+
+				for _, e := range ln.Exercises {
+					lesson.Exercises = append(lesson.Exercises, &Exercise{
+						Title:        e.Title,
+						Type:         e.Type,
+						MatchingType: e.MatchingType,
+						Prompt:       e.Prompt,
+						MediaURL:     e.MediaURL,
+						Points:       e.Points,
+						Grade:        e.Grade,
+						Syllabus:     e.Syllabus,
+						Objective:    e.ObjectiveTag,
+						Metadata:     e.Metadata,
+						Options:      optionsByExercise[e.ID],
+					})
+				}
+			}
+		}
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
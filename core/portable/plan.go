@@ -0,0 +1,647 @@
+package portable
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/diff"
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/skillgraph"
+	"github.com/bytebeatz/bandroom-cms/core/validation"
+	"github.com/bytebeatz/bandroom-cms/core/workflow"
+	"github.com/google/uuid"
+)
+
+// untracked fields are not set by documents, so they never make an update.
+var untracked = []string{"version", "status", "created_at", "updated_at", "deleted_at"}
+
+// Plan works out what importing doc does to the course it names. current is
+// the course's full tree with options, or nil when the course is new.
+// Skills and lessons are matched by slug anywhere in the course, so they may
+// move; units are matched by title and exercises by title within their
+// lesson, taking duplicates in order. Whatever the document does not mention
+// is deleted. New content is created as a draft by userID; updated content
+// follows workflow.AfterEdit, so approved content goes back to draft and a
+// document that changes or leaves out published content fails with
+// workflow.ErrPublished.
+//
+// Plan checks the whole document and returns validation.Errors naming each
+// problem by its path, e.g. "units[0].skills[2].prerequisites[1]".
+func Plan(
+	doc *Document,
+	current *model.CourseTree,
+	options []*model.ExerciseOption,
+	userID uuid.UUID,
+	now time.Time,
+) (*model.ImportPlan, error) {
+	if err := check(doc); err != nil {
+		return nil, err
+	}
+
+	p := &planner{
+		plan: &model.ImportPlan{
+			Options: map[uuid.UUID][]*model.ExerciseOption{},
+			Created: map[uuid.UUID]bool{},
+		},
+		userID:    userID,
+		now:       now,
+		units:     map[string][]*model.UnitNode{},
+		skills:    map[string][]*model.SkillNode{},
+		lessons:   map[string][]*model.LessonNode{},
+		exercises: map[uuid.UUID][]*model.Exercise{},
+		options:   map[uuid.UUID][]*model.ExerciseOption{},
+		kept:      map[uuid.UUID]bool{},
+		skillIDs:  map[string]uuid.UUID{},
+	}
+	for _, o := range options {
+		p.options[o.ExerciseID] = append(p.options[o.ExerciseID], o)
+	}
+	if current != nil {
+		for _, un := range current.Units {
+			p.units[un.Unit.Title] = append(p.units[un.Unit.Title], un)
+			for _, sn := range un.Skills {
+				p.skills[sn.Skill.Slug] = append(p.skills[sn.Skill.Slug], sn)
+				for _, ln := range sn.Lessons {
+					p.lessons[ln.Lesson.Slug] = append(p.lessons[ln.Lesson.Slug], ln)
+					p.exercises[ln.Lesson.ID] = ln.Exercises
+				}
+			}
+		}
+	}
+
+	courseID := p.course(doc, current)
+	if err := p.content(doc, courseID); err != nil {
+		return nil, err
+	}
+	if current != nil {
+		p.deletes(current)
+	}
+	if len(p.published) > 0 {
+		return nil, fmt.Errorf("%w: unpublish %s first",
+			workflow.ErrPublished, strings.Join(p.published, ", "))
+	}
+	return p.plan, nil
+}
+
+type planner struct {
+	plan   *model.ImportPlan
+	userID uuid.UUID
+	now    time.Time
+
+	// Existing content not yet matched, by title or slug
+	units     map[string][]*model.UnitNode
+	skills    map[string][]*model.SkillNode
+	lessons   map[string][]*model.LessonNode
+	exercises map[uuid.UUID][]*model.Exercise // by lesson
+	options   map[uuid.UUID][]*model.ExerciseOption
+
+	kept      map[uuid.UUID]bool   // existing content the document keeps
+	skillIDs  map[string]uuid.UUID // skill IDs by slug, for prerequisites
+	published []string             // published content the document changes or deletes
+}
+
+// check validates the shape of a document: required fields, unique keys and
+// prerequisites that name skills of the document.
+func check(doc *Document) error {
+	var errs validation.Errors
+	if doc.Format != 0 && doc.Format != FormatVersion {
+		errs.Add("format", "unsupported format %d, expected %d", doc.Format, FormatVersion)
+	}
+	required(&errs, "slug", doc.Slug)
+	required(&errs, "title", doc.Title)
+
+	unitTitles := map[string]bool{}
+	skillSlugs, skillTitles := map[string]bool{}, map[string]bool{}
+	lessonSlugs := map[string]bool{}
+	for i, u := range doc.Units {
+		up := fmt.Sprintf("units[%d]", i)
+		if u == nil {
+			errs.Add(up, "must not be empty")
+			continue
+		}
+		if required(&errs, up+".title", u.Title) {
+			unique(&errs, up+".title", "unit title", u.Title, unitTitles)
+		}
+
+		for j, s := range u.Skills {
+			sp := fmt.Sprintf("%s.skills[%d]", up, j)
+			if s == nil {
+				errs.Add(sp, "must not be empty")
+				continue
+			}
+			if required(&errs, sp+".slug", s.Slug) {
+				unique(&errs, sp+".slug", "skill slug", s.Slug, skillSlugs)
+			}
+			if required(&errs, sp+".title", s.Title) {
+				unique(&errs, sp+".title", "skill title", s.Title, skillTitles)
+			}
+
+			lessonTitles := map[string]bool{}
+			for k, l := range s.Lessons {
+				lp := fmt.Sprintf("%s.lessons[%d]", sp, k)
+				if l == nil {
+					errs.Add(lp, "must not be empty")
+					continue
+				}
+				if required(&errs, lp+".slug", l.Slug) {
+					unique(&errs, lp+".slug", "lesson slug", l.Slug, lessonSlugs)
+				}
+				if required(&errs, lp+".title", l.Title) {
+					unique(&errs, lp+".title", "lesson title in this skill", l.Title, lessonTitles)
+				}
+
+				for m, e := range l.Exercises {
+					ep := fmt.Sprintf("%s.exercises[%d]", lp, m)
+					if e == nil {
+						errs.Add(ep, "must not be empty")
+						continue
+					}
+					for n, o := range e.Options {
+						if o == nil {
+							errs.Add(fmt.Sprintf("%s.options[%d]", ep, n), "must not be empty")
+						}
+					}
+				}
+			}
+		}
+	}
+
+	for i, u := range doc.Units {
+		if u == nil {
+			continue
+		}
+		for j, s := range u.Skills {
+			if s == nil {
+				continue
+			}
+			seen := map[string]bool{}
+			for k, slug := range s.Prerequisites {
+				field := fmt.Sprintf("units[%d].skills[%d].prerequisites[%d]", i, j, k)
+				switch {
+				case slug == s.Slug:
+					errs.Add(field, "a skill cannot be its own prerequisite")
+				case seen[slug]:
+					errs.Add(field, "prerequisite '%s' is listed more than once", slug)
+				case !skillSlugs[slug]:
+					errs.Add(field, "no skill with slug '%s' in this course", slug)
+				}
+				seen[slug] = true
+			}
+		}
+	}
+
+	return errs.Err()
+}
+
+func required(errs *validation.Errors, field, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		errs.Add(field, "is required")
+		return false
+	}
+	return true
+}
+
+func unique(errs *validation.Errors, field, what, value string, seen map[string]bool) {
+	if seen[value] {
+		errs.Add(field, "%s '%s' is used more than once", what, value)
+	}
+	seen[value] = true
+}
+
+// course plans the course row itself and returns its ID.
+func (p *planner) course(doc *Document, current *model.CourseTree) uuid.UUID {
+	if current == nil {
+		userID := p.userID
+		c := &model.Course{
+			ID:          uuid.New(),
+			Slug:        doc.Slug,
+			Title:       doc.Title,
+			Description: doc.Description,
+			Language:    doc.Language,
+			Difficulty:  doc.Difficulty,
+			Tags:        doc.Tags,
+			Metadata:    doc.Metadata,
+			Version:     1,
+			Status:      model.StateDraft,
+			CreatedAt:   p.now,
+			UpdatedAt:   p.now,
+			CreatorID:   &userID,
+		}
+		p.plan.CourseID, p.plan.Course = c.ID, c
+		p.create(model.EntityCourse, c.ID, c.Slug)
+		return c.ID
+	}
+
+	existing := current.Course
+	c := *existing
+	c.Slug, c.Title, c.Description = doc.Slug, doc.Title, doc.Description
+	c.Language, c.Difficulty = doc.Language, doc.Difficulty
+	c.Tags, c.Metadata = keepEmpty(existing.Tags, doc.Tags), keepEmptyMap(existing.Metadata, doc.Metadata)
+
+	p.plan.CourseID = c.ID
+	if fields := changedFields(existing, &c); len(fields) > 0 {
+		c.UpdatedAt = p.now
+		p.plan.Course = &c
+		p.update(model.EntityCourse, c.ID, c.Slug, fields, &c.Status)
+	}
+	return c.ID
+}
+
+// content plans units, skills, lessons and exercises in document order.
+func (p *planner) content(doc *Document, courseID uuid.UUID) error {
+	// Skills get their IDs first, so prerequisites can point forward
+	for _, u := range doc.Units {
+		for _, s := range u.Skills {
+			if existing := p.skills[s.Slug]; len(existing) > 0 {
+				p.skillIDs[s.Slug] = existing[0].Skill.ID
+			} else {
+				p.skillIDs[s.Slug] = uuid.New()
+			}
+		}
+	}
+
+	var errs validation.Errors
+	var skills []*model.Skill
+	for i, du := range doc.Units {
+		unitID := p.unit(du, courseID, i)
+		for j, ds := range du.Skills {
+			skill := p.skill(ds, courseID, unitID, j)
+			skills = append(skills, skill)
+			for k, dl := range ds.Lessons {
+				lesson, _ := p.lesson(dl, skill.ID, k)
+				for m, de := range dl.Exercises {
+					path := fmt.Sprintf("units[%d].skills[%d].lessons[%d].exercises[%d]", i, j, k, m)
+					errs = append(errs, p.exercise(de, lesson, m, path)...)
+				}
+			}
+		}
+	}
+
+	// Report a prerequisite cycle once, by slug
+	graph := skillgraph.New(skills)
+	for _, s := range skills {
+		if cycle := graph.CycleFrom(s.ID); cycle != nil {
+			slugs := make([]string, 0, len(cycle))
+			for _, id := range cycle {
+				slugs = append(slugs, graph.Skill(id).Slug)
+			}
+			errs.Add("prerequisites", "prerequisites form a cycle: %s", strings.Join(slugs, " → "))
+			break
+		}
+	}
+	return errs.Err()
+}
+
+func (p *planner) unit(du *Unit, courseID uuid.UUID, index int) uuid.UUID {
+	if node := pop(p.units, du.Title); node != nil {
+		existing := node.Unit
+		u := *existing
+		u.Title, u.Description, u.OrderIndex = du.Title, du.Description, index
+		p.kept[u.ID] = true
+		if fields := changedFields(existing, &u); len(fields) > 0 {
+			u.UpdatedAt = p.now
+			p.plan.Units = append(p.plan.Units, &u)
+			p.update(model.EntityUnit, u.ID, u.Title, fields, &u.Status)
+		}
+		return u.ID
+	}
+
+	u := &model.Unit{
+		ID:          uuid.New(),
+		CourseID:    courseID,
+		Title:       du.Title,
+		Description: du.Description,
+		OrderIndex:  index,
+		Version:     1,
+		Status:      model.StateDraft,
+		CreatedAt:   p.now,
+		UpdatedAt:   p.now,
+	}
+	p.plan.Units = append(p.plan.Units, u)
+	p.create(model.EntityUnit, u.ID, u.Title)
+	return u.ID
+}
+
+func (p *planner) skill(ds *Skill, courseID, unitID uuid.UUID, index int) *model.Skill {
+	prereqs := make([]uuid.UUID, 0, len(ds.Prerequisites))
+	for _, slug := range ds.Prerequisites {
+		prereqs = append(prereqs, p.skillIDs[slug])
+	}
+
+	var s *model.Skill
+	var existing *model.Skill
+	if node := pop(p.skills, ds.Slug); node != nil {
+		existing = node.Skill
+		copied := *existing
+		s = &copied
+		p.kept[s.ID] = true
+	} else {
+		s = &model.Skill{
+			ID:        p.skillIDs[ds.Slug],
+			CourseID:  courseID,
+			CreatorID: p.userID,
+			Version:   1,
+			Status:    model.StateDraft,
+			CreatedAt: p.now,
+			UpdatedAt: p.now,
+		}
+	}
+
+	s.UnitID, s.OrderIndex = unitID, index
+	s.Slug, s.Title, s.Icon = ds.Slug, ds.Title, ds.Icon
+	s.Difficulty, s.MaxCrowns = ds.Difficulty, ds.MaxCrowns
+	s.BaseXPReward, s.XPPerCrown = ds.BaseXPReward, ds.XPPerCrown
+	if existing != nil {
+		s.PrerequisiteSkillIDs = keepEmpty(existing.PrerequisiteSkillIDs, prereqs)
+		s.Tags = keepEmpty(existing.Tags, ds.Tags)
+		s.Metadata = keepEmptyMap(existing.Metadata, ds.Metadata)
+		if fields := changedFields(existing, s); len(fields) > 0 {
+			s.UpdatedAt = p.now
+			p.plan.Skills = append(p.plan.Skills, s)
+			p.update(model.EntitySkill, s.ID, s.Slug, fields, &s.Status)
+		}
+		return s
+	}
+
+	s.PrerequisiteSkillIDs, s.Tags, s.Metadata = prereqs, ds.Tags, ds.Metadata
+	p.plan.Skills = append(p.plan.Skills, s)
+	p.create(model.EntitySkill, s.ID, s.Slug)
+	return s
+}
+
+// lesson plans a lesson and reports whether it already existed.
+func (p *planner) lesson(dl *Lesson, skillID uuid.UUID, index int) (*model.Lesson, bool) {
+	node := pop(p.lessons, dl.Slug)
+
+	var l *model.Lesson
+	if node != nil {
+		copied := *node.Lesson
+		l = &copied
+		p.kept[l.ID] = true
+	} else {
+		l = &model.Lesson{
+			ID:        uuid.New(),
+			CreatorID: p.userID,
+			Version:   1,
+			Status:    model.StateDraft,
+			CreatedAt: p.now,
+			UpdatedAt: p.now,
+		}
+	}
+
+	l.SkillID, l.OrderIndex = skillID, index
+	l.Slug, l.Title, l.Description = dl.Slug, dl.Title, dl.Description
+	l.TotalExercises, l.BaseXP, l.BonusXP = dl.TotalExercises, dl.BaseXP, dl.BonusXP
+	l.RewardGems, l.RewardHearts, l.RewardCondition = dl.RewardGems, dl.RewardHearts, dl.RewardCondition
+	l.EstimatedDuration, l.DifficultyRating = dl.EstimatedDuration, dl.DifficultyRating
+	l.IsTestable = dl.IsTestable
+	if node != nil {
+		l.Tags = keepEmpty(node.Lesson.Tags, dl.Tags)
+		l.Metadata = keepEmptyMap(node.Lesson.Metadata, dl.Metadata)
+		if fields := changedFields(node.Lesson, l); len(fields) > 0 {
+			l.UpdatedAt = p.now
+			p.plan.Lessons = append(p.plan.Lessons, l)
+			p.update(model.EntityLesson, l.ID, l.Slug, fields, &l.Status)
+		}
+		return l, true
+	}
+
+	l.Tags, l.Metadata = dl.Tags, dl.Metadata
+	p.plan.Lessons = append(p.plan.Lessons, l)
+	p.create(model.EntityLesson, l.ID, l.Slug)
+	return l, false
+}
+
+// exercise plans an exercise and its options, matched by title among the
+// exercises its lesson had, and validates it like the exercise API does.
+func (p *planner) exercise(de *Exercise, lesson *model.Lesson, index int, path string) validation.Errors {
+	var existing *model.Exercise
+	remaining := p.exercises[lesson.ID]
+	for i, e := range remaining {
+		if e.Title == de.Title {
+			existing = e
+			p.exercises[lesson.ID] = slices.Delete(slices.Clone(remaining), i, i+1)
+			break
+		}
+	}
+
+	var e *model.Exercise
+	if existing != nil {
+		copied := *existing
+		e = &copied
+		p.kept[e.ID] = true
+	} else {
+		e = &model.Exercise{
+			ID:        uuid.New(),
+			Version:   1,
+			Status:    model.StateDraft,
+			CreatedAt: p.now,
+			UpdatedAt: p.now,
+		}
+	}
+
+	e.LessonID, e.SkillID, e.OrderIndex = lesson.ID, lesson.SkillID, index
+	e.Title, e.Type, e.MatchingType = de.Title, de.Type, de.MatchingType
+	e.Prompt, e.MediaURL = de.Prompt, de.MediaURL
+	e.Points, e.Grade, e.Syllabus, e.ObjectiveTag = de.Points, de.Grade, de.Syllabus, de.Objective
+	e.Metadata = de.Metadata
+	if e.Metadata == nil {
+		e.Metadata = model.JSONB{}
+	}
+
+	options := make([]model.ExerciseOption, 0, len(de.Options))
+	for i, o := range de.Options {
+		options = append(options, model.ExerciseOption{
+			ExerciseID: e.ID,
+			Label:      o.Label,
+			Value:      o.Value,
+			IsCorrect:  o.IsCorrect,
+			MediaURL:   o.MediaURL,
+			OrderIndex: i,
+		})
+	}
+
+	var errs validation.Errors
+	if err := validation.Validate(e, options); err != nil {
+		for _, fe := range err.(validation.Errors) {
+			errs.Add(path+"."+fe.Field, "%s", fe.Message)
+		}
+	}
+
+	optionsChanged := existing == nil && len(options) > 0 ||
+		existing != nil && !sameOptions(p.options[e.ID], options)
+	if optionsChanged {
+		replaced := make([]*model.ExerciseOption, 0, len(options))
+		for _, o := range options {
+			o.ID, o.CreatedAt, o.UpdatedAt = uuid.New(), p.now, p.now
+			replaced = append(replaced, &o)
+		}
+		p.plan.Options[e.ID] = replaced
+	}
+
+	if existing == nil {
+		p.plan.Exercises = append(p.plan.Exercises, e)
+		p.create(model.EntityExercise, e.ID, e.Title)
+		return errs
+	}
+
+	// Options are versioned with their exercise, so changing them updates it
+	fields := changedFields(existing, e)
+	if optionsChanged {
+		fields = append(fields, "options")
+	}
+	if len(fields) > 0 {
+		e.UpdatedAt = p.now
+		p.plan.Exercises = append(p.plan.Exercises, e)
+		p.update(model.EntityExercise, e.ID, e.Title, fields, &e.Status)
+	}
+	return errs
+}
+
+// deletes plans the removal of everything current has that the document
+// does not keep. Only the topmost entities are deleted directly; the rest go
+// with them. Kept content has by then been moved to its new parent, so the
+// cascade does not reach it.
+func (p *planner) deletes(current *model.CourseTree) {
+	for _, un := range current.Units {
+		unitGone := !p.kept[un.Unit.ID]
+		if unitGone {
+			p.remove(model.EntityUnit, un.Unit.ID, un.Unit.Title, un.Unit.Status, true)
+		}
+		for _, sn := range un.Skills {
+			skillGone := !p.kept[sn.Skill.ID]
+			if skillGone {
+				p.remove(model.EntitySkill, sn.Skill.ID, sn.Skill.Slug, sn.Skill.Status, !unitGone)
+			}
+			for _, ln := range sn.Lessons {
+				lessonGone := !p.kept[ln.Lesson.ID]
+				if lessonGone {
+					p.remove(model.EntityLesson, ln.Lesson.ID, ln.Lesson.Slug, ln.Lesson.Status,
+						!skillGone)
+				}
+				for _, e := range ln.Exercises {
+					if !p.kept[e.ID] {
+						p.remove(model.EntityExercise, e.ID, e.Title, e.Status, !lessonGone)
+					}
+				}
+			}
+		}
+	}
+}
+
+func (p *planner) create(entityType model.EntityType, id uuid.UUID, key string) {
+	p.plan.Created[id] = true
+	p.plan.Changes = append(p.plan.Changes, model.ImportChange{
+		Action: model.ImportCreate, EntityType: entityType, EntityID: id, Key: key,
+	})
+}
+
+// update records a change to existing content and moves it to the state an
+// edit leaves it in.
+func (p *planner) update(
+	entityType model.EntityType,
+	id uuid.UUID,
+	key string,
+	fields []string,
+	status *model.WorkflowState,
+) {
+	next, err := workflow.AfterEdit(*status)
+	if err != nil {
+		p.published = append(p.published, fmt.Sprintf("%s '%s'", entityType, key))
+	}
+	*status = next
+	p.plan.Changes = append(p.plan.Changes, model.ImportChange{
+		Action: model.ImportUpdate, EntityType: entityType, EntityID: id, Key: key, Fields: fields,
+	})
+}
+
+// remove records the deletion of existing content, directly or along with
+// its parent. Published content may not be deleted, like it may not be
+// edited; content beneath unpublished content cannot be published, so
+// checking direct deletions covers everything.
+func (p *planner) remove(
+	entityType model.EntityType,
+	id uuid.UUID,
+	key string,
+	status model.WorkflowState,
+	direct bool,
+) {
+	if direct {
+		if status == model.StatePublished {
+			p.published = append(p.published, fmt.Sprintf("%s '%s'", entityType, key))
+		}
+		p.plan.Deletes = append(p.plan.Deletes, model.EntityRef{EntityType: entityType, EntityID: id})
+	}
+	p.plan.Changes = append(p.plan.Changes, model.ImportChange{
+		Action: model.ImportDelete, EntityType: entityType, EntityID: id, Key: key,
+	})
+}
+
+// pop takes the first unmatched node with the given key.
+func pop[T any](nodes map[string][]T, key string) T {
+	var zero T
+	list := nodes[key]
+	if len(list) == 0 {
+		return zero
+	}
+	nodes[key] = list[1:]
+	return list[0]
+}
+
+// changedFields names the fields that differ between two versions of an
+// entity, as in revision diffs.
+func changedFields(before, after any) []string {
+	a, b := snapshot(before), snapshot(after)
+	var fields []string
+	for _, ch := range diff.Fields(a, b, untracked...) {
+		fields = append(fields, ch.Field)
+	}
+	return fields
+}
+
+func snapshot(entity any) map[string]any {
+	raw, _ := json.Marshal(entity)
+	var m map[string]any
+	_ = json.Unmarshal(raw, &m)
+	return m
+}
+
+func sameOptions(existing []*model.ExerciseOption, options []model.ExerciseOption) bool {
+	if len(existing) != len(options) {
+		return false
+	}
+	for i, o := range existing {
+		n := options[i]
+		if o.Label != n.Label || o.Value != n.Value || o.IsCorrect != n.IsCorrect ||
+			!samePtr(o.MediaURL, n.MediaURL) {
+			return false
+		}
+	}
+	return true
+}
+
+func samePtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// keepEmpty keeps an existing empty list when the document has none, so
+// nil and [] do not count as a change.
+func keepEmpty[T any](existing, doc []T) []T {
+	if len(doc) == 0 && len(existing) == 0 {
+		return existing
+	}
+	return doc
+}
+
+func keepEmptyMap(existing, doc map[string]any) map[string]any {
+	if len(doc) == 0 && len(existing) == 0 {
+		return existing
+	}
+	return doc
+}
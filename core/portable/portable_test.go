@@ -0,0 +1,384 @@
+package portable
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/validation"
+	"github.com/bytebeatz/bandroom-cms/core/workflow"
+	"github.com/google/uuid"
+)
+
+var (
+	author = uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+	now    = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+)
+
+// testDocument is a small course: two skills, the second requiring the
+// first, and a lesson with a multiple choice and a typing exercise.
+func testDocument() *Document {
+	return &Document{
+		Format: FormatVersion,
+		Slug:   "theory",
+		Title:  "Music Theory",
+		Units: []*Unit{{
+			Title: "Basics",
+			Skills: []*Skill{
+				{
+					Slug:  "notes",
+					Title: "Notes",
+					Lessons: []*Lesson{{
+						Slug:  "note-names",
+						Title: "Note names",
+						Exercises: []*Exercise{
+							{
+								Title:  "Root of C major",
+								Type:   model.ExerciseMultipleChoice,
+								Prompt: "Which note is the root of C major?",
+								Points: 10,
+								Options: []*Option{
+									{Label: "C", Value: "C", IsCorrect: true},
+									{Label: "G", Value: "G"},
+								},
+							},
+							{
+								Title:    "Name the interval",
+								Type:     model.ExerciseTyping,
+								Prompt:   "C to G is a…",
+								Metadata: map[string]any{"accepted_answers": []any{"Perfect 5th"}},
+							},
+						},
+					}},
+				},
+				{Slug: "intervals", Title: "Intervals", Prerequisites: []string{"notes"}, Lessons: []*Lesson{}},
+			},
+		}},
+	}
+}
+
+// treeOf builds the course tree an applied plan of a new course leaves,
+// with the options of its exercises in order.
+func treeOf(plan *model.ImportPlan) (*model.CourseTree, []*model.ExerciseOption) {
+	tree := &model.CourseTree{Course: plan.Course}
+	var options []*model.ExerciseOption
+	for _, u := range plan.Units {
+		un := &model.UnitNode{Unit: u}
+		for _, s := range plan.Skills {
+			if s.UnitID != u.ID {
+				continue
+			}
+			sn := &model.SkillNode{Skill: s}
+			for _, l := range plan.Lessons {
+				if l.SkillID != s.ID {
+					continue
+				}
+				ln := &model.LessonNode{Lesson: l}
+				for _, e := range plan.Exercises {
+					if e.LessonID == l.ID {
+						ln.Exercises = append(ln.Exercises, e)
+						options = append(options, plan.Options[e.ID]...)
+					}
+				}
+				sn.Lessons = append(sn.Lessons, ln)
+			}
+			un.Skills = append(un.Skills, sn)
+		}
+		tree.Units = append(tree.Units, un)
+	}
+	return tree, options
+}
+
+func TestPlanNewCourse(t *testing.T) {
+	plan, err := Plan(testDocument(), nil, nil, author, now)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if plan.Course == nil || plan.Course.Status != model.StateDraft || *plan.Course.CreatorID != author {
+		t.Errorf("course = %+v", plan.Course)
+	}
+	if len(plan.Units) != 1 || len(plan.Skills) != 2 || len(plan.Lessons) != 1 || len(plan.Exercises) != 2 {
+		t.Fatalf("plan has %d units, %d skills, %d lessons, %d exercises",
+			len(plan.Units), len(plan.Skills), len(plan.Lessons), len(plan.Exercises))
+	}
+	if len(plan.Changes) != 7 || len(plan.Created) != 7 || len(plan.Deletes) != 0 {
+		t.Errorf("plan has %d changes, %d created, %d deletes", len(plan.Changes), len(plan.Created), len(plan.Deletes))
+	}
+	notes, intervals := plan.Skills[0], plan.Skills[1]
+	if !slices.Equal(intervals.PrerequisiteSkillIDs, []uuid.UUID{notes.ID}) {
+		t.Errorf("prerequisites of intervals = %v, want [%s]", intervals.PrerequisiteSkillIDs, notes.ID)
+	}
+	if opts := plan.Options[plan.Exercises[0].ID]; len(opts) != 2 || !opts[0].IsCorrect || opts[1].OrderIndex != 1 {
+		t.Errorf("options = %+v", opts)
+	}
+}
+
+func TestPlanChanges(t *testing.T) {
+	created, err := Plan(testDocument(), nil, nil, author, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		edit    func(doc *Document)
+		state   model.WorkflowState // of the existing content
+		changes []string            // "action type key fields"
+		wantErr error
+	}{
+		{
+			name: "unchanged",
+			edit: func(doc *Document) {},
+		},
+		{
+			name: "field edit",
+			edit: func(doc *Document) {
+				doc.Units[0].Skills[0].Lessons[0].Title = "Naming notes"
+			},
+			changes: []string{"update lesson note-names title"},
+		},
+		{
+			name: "approved content goes back to draft",
+			edit: func(doc *Document) {
+				doc.Units[0].Skills[1].Title = "Intervals 101"
+			},
+			state:   model.StateApproved,
+			changes: []string{"update skill intervals title"},
+		},
+		{
+			name: "option edit updates the exercise",
+			edit: func(doc *Document) {
+				doc.Units[0].Skills[0].Lessons[0].Exercises[0].Options[1].Label = "F"
+			},
+			changes: []string{"update exercise Root of C major options"},
+		},
+		{
+			name: "removed exercise and skill",
+			edit: func(doc *Document) {
+				lesson := doc.Units[0].Skills[0].Lessons[0]
+				lesson.Exercises = lesson.Exercises[:1]
+				doc.Units[0].Skills = doc.Units[0].Skills[:1]
+			},
+			changes: []string{
+				"delete exercise Name the interval ",
+				"delete skill intervals ",
+			},
+		},
+		{
+			name: "moved lesson",
+			edit: func(doc *Document) {
+				skills := doc.Units[0].Skills
+				skills[1].Lessons, skills[0].Lessons = skills[0].Lessons, []*Lesson{}
+			},
+			changes: []string{
+				"update lesson note-names skill_id",
+				"update exercise Root of C major skill_id",
+				"update exercise Name the interval skill_id",
+			},
+		},
+		{
+			name: "published content",
+			edit: func(doc *Document) {
+				doc.Title = "Theory"
+			},
+			state:   model.StatePublished,
+			wantErr: workflow.ErrPublished,
+		},
+		{
+			name: "removed published content",
+			edit: func(doc *Document) {
+				doc.Units[0].Skills = doc.Units[0].Skills[:1]
+			},
+			state:   model.StatePublished,
+			wantErr: workflow.ErrPublished,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The course and its skills are in the given state, draft by default
+			state := tt.state
+			if state == "" {
+				state = model.StateDraft
+			}
+			created.Course.Status = state
+			for _, s := range created.Skills {
+				s.Status = state
+			}
+			tree, options := treeOf(created)
+			doc, err := Export(tree, options)
+			if err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+			tt.edit(doc)
+
+			plan, err := Plan(doc, tree, options, author, now.Add(time.Hour))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Plan() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Plan() error = %v", err)
+			}
+			var got []string
+			for _, ch := range plan.Changes {
+				fields := slices.Clone(ch.Fields)
+				slices.Sort(fields)
+				got = append(got, strings.Join([]string{
+					string(ch.Action), string(ch.EntityType), ch.Key, strings.Join(fields, ","),
+				}, " "))
+			}
+			if !slices.Equal(got, tt.changes) {
+				t.Errorf("changes = %q, want %q", got, tt.changes)
+			}
+			if len(plan.Created) != 0 {
+				t.Errorf("plan creates %d entities", len(plan.Created))
+			}
+			for _, s := range plan.Skills {
+				if s.Status != model.StateDraft {
+					t.Errorf("skill %s is %s after the import, want draft", s.Slug, s.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		edit   func(doc *Document)
+		fields []string
+	}{
+		{"no slug or title", func(d *Document) { d.Slug, d.Title = "", " " }, []string{"slug", "title"}},
+		{"future format", func(d *Document) { d.Format = FormatVersion + 1 }, []string{"format"}},
+		{"empty unit", func(d *Document) { d.Units = append(d.Units, nil) }, []string{"units[1]"}},
+		{"repeated unit title", func(d *Document) {
+			d.Units = append(d.Units, &Unit{Title: "Basics"})
+		}, []string{"units[1].title"}},
+		{"repeated skill slug", func(d *Document) {
+			d.Units[0].Skills[1].Slug = "notes"
+		}, []string{"units[0].skills[1].slug", "units[0].skills[1].prerequisites[0]"}},
+		{"prerequisite problems", func(d *Document) {
+			d.Units[0].Skills[1].Prerequisites = []string{"notes", "notes", "intervals", "chords"}
+		}, []string{
+			"units[0].skills[1].prerequisites[1]",
+			"units[0].skills[1].prerequisites[2]",
+			"units[0].skills[1].prerequisites[3]",
+		}},
+		{"cycle", func(d *Document) {
+			d.Units[0].Skills[0].Prerequisites = []string{"intervals"}
+		}, []string{"prerequisites"}},
+		{"lesson without slug", func(d *Document) {
+			d.Units[0].Skills[0].Lessons[0].Slug = ""
+		}, []string{"units[0].skills[0].lessons[0].slug"}},
+		{"invalid exercise", func(d *Document) {
+			d.Units[0].Skills[0].Lessons[0].Exercises[1].Metadata = nil
+		}, []string{"units[0].skills[0].lessons[0].exercises[1].metadata.accepted_answers"}},
+		{"empty option", func(d *Document) {
+			e := d.Units[0].Skills[0].Lessons[0].Exercises[0]
+			e.Options = append(e.Options, nil)
+		}, []string{"units[0].skills[0].lessons[0].exercises[0].options[2]"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := testDocument()
+			tt.edit(doc)
+			_, err := Plan(doc, nil, nil, author, now)
+			var errs validation.Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Plan() error = %v, want validation.Errors", err)
+			}
+			for _, f := range tt.fields {
+				if !slices.ContainsFunc(errs, func(fe validation.FieldError) bool { return fe.Field == f }) {
+					t.Errorf("Plan() errors = %v, want one for %s", errs, f)
+				}
+			}
+		})
+	}
+}
+
+func TestExportOutsidePrerequisite(t *testing.T) {
+	plan, err := Plan(testDocument(), nil, nil, author, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan.Skills[1].PrerequisiteSkillIDs = append(plan.Skills[1].PrerequisiteSkillIDs, uuid.New())
+	tree, options := treeOf(plan)
+
+	_, err = Export(tree, options)
+	var errs validation.Errors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Field != "units[0].skills[1].prerequisites[1]" {
+		t.Errorf("Export() error = %v", err)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	for _, format := range []Format{FormatJSON, FormatYAML} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Encode(&buf, testDocument(), format); err != nil {
+				t.Fatal(err)
+			}
+			doc, err := Decode(&buf, format)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(doc, testDocument()) {
+				t.Errorf("Decode(Encode(doc)) = %+v, want %+v", doc, testDocument())
+			}
+		})
+	}
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		input  string
+	}{
+		{"empty JSON", FormatJSON, ""},
+		{"JSON syntax", FormatJSON, `{"slug": "theory",`},
+		{"unknown JSON field", FormatJSON, `{"slug": "theory", "colour": "red"}`},
+		{"JSON type mismatch", FormatJSON, `{"units": {"title": "Basics"}}`},
+		{"unknown nested JSON field", FormatJSON, `{"units": [{"title": "Basics", "skils": []}]}`},
+		{"empty YAML", FormatYAML, ""},
+		{"YAML syntax", FormatYAML, "slug: [theory"},
+		{"unknown YAML field", FormatYAML, "slug: theory\ncolour: red\n"},
+		{"YAML type mismatch", FormatYAML, "units: basics\n"},
+		{"YAML metadata not JSON", FormatYAML, "metadata:\n  when: .inf\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if doc, err := Decode(strings.NewReader(tt.input), tt.format); err == nil {
+				t.Errorf("Decode() = %+v, want an error", doc)
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Format
+		ok   bool
+	}{
+		{"", FormatJSON, true},
+		{"json", FormatJSON, true},
+		{"yaml", FormatYAML, true},
+		{"yml", FormatYAML, true},
+		{"xml", "", false},
+		{"JSON", "", false},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.in)
+		if got != tt.want || (err == nil) != tt.ok {
+			t.Errorf("ParseFormat(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
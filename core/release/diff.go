@@ -0,0 +1,146 @@
+package release
+
+import (
+	"encoding/json"
+
+	"github.com/bytebeatz/bandroom-cms/core/diff"
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/google/uuid"
+)
+
+// ChangeKind says what happened to an entity between two releases.
+type ChangeKind string
+
+const (
+	Added   ChangeKind = "added"
+	Removed ChangeKind = "removed"
+	Changed ChangeKind = "changed"
+)
+
+// Change is one course, unit, skill, lesson or exercise that differs between
+// two releases. Fields lists what changed; a move to another parent shows up
+// as a change of unit_id, skill_id or lesson_id, and an exercise's options
+// are compared as one "options" field.
+type Change struct {
+	EntityType model.EntityType `json:"entity_type"`
+	EntityID   uuid.UUID        `json:"entity_id"`
+	Title      string           `json:"title"`
+	Kind       ChangeKind       `json:"change"`
+	Fields     []diff.Change    `json:"fields,omitempty"`
+}
+
+// entry is one entity of release content with its own fields, children
+// left out.
+type entry struct {
+	entityType model.EntityType
+	id         uuid.UUID
+	title      string
+	fields     map[string]any
+}
+
+// Diff lists the entities added, changed or removed from one release's
+// content to another's: added and changed ones in the order of to, then
+// removed ones in the order of from.
+func Diff(from, to *model.ReleaseContent) ([]Change, error) {
+	before, err := flatten(from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := flatten(to)
+	if err != nil {
+		return nil, err
+	}
+
+	old := make(map[uuid.UUID]entry, len(before))
+	for _, e := range before {
+		old[e.id] = e
+	}
+	seen := make(map[uuid.UUID]bool, len(after))
+
+	var changes []Change
+	for _, e := range after {
+		seen[e.id] = true
+		prev, ok := old[e.id]
+		if !ok {
+			changes = append(changes, Change{
+				EntityType: e.entityType, EntityID: e.id, Title: e.title, Kind: Added,
+			})
+			continue
+		}
+		if fields := diff.Fields(prev.fields, e.fields); len(fields) > 0 {
+			changes = append(changes, Change{
+				EntityType: e.entityType, EntityID: e.id, Title: e.title, Kind: Changed,
+				Fields: fields,
+			})
+		}
+	}
+	for _, e := range before {
+		if !seen[e.id] {
+			changes = append(changes, Change{
+				EntityType: e.entityType, EntityID: e.id, Title: e.title, Kind: Removed,
+			})
+		}
+	}
+	return changes, nil
+}
+
+// flatten lists the entities of content depth first, in order.
+func flatten(content *model.ReleaseContent) ([]entry, error) {
+	var entries []entry
+	add := func(entityType model.EntityType, id uuid.UUID, title string, v any,
+		children string, parent string, parentID uuid.UUID) error {
+		fields, err := ownFields(v, children)
+		if err != nil {
+			return err
+		}
+		if parent != "" {
+			fields[parent] = parentID.String()
+		}
+		entries = append(entries, entry{entityType, id, title, fields})
+		return nil
+	}
+
+	if err := add(model.EntityCourse, content.ID, content.Title, content,
+		"units", "", uuid.Nil); err != nil {
+		return nil, err
+	}
+	for _, u := range content.Units {
+		if err := add(model.EntityUnit, u.ID, u.Title, u,
+			"skills", "", uuid.Nil); err != nil {
+			return nil, err
+		}
+		for _, s := range u.Skills {
+			if err := add(model.EntitySkill, s.ID, s.Title, s,
+				"lessons", "unit_id", u.ID); err != nil {
+				return nil, err
+			}
+			for _, l := range s.Lessons {
+				if err := add(model.EntityLesson, l.ID, l.Title, l,
+					"exercises", "skill_id", s.ID); err != nil {
+					return nil, err
+				}
+				for _, e := range l.Exercises {
+					if err := add(model.EntityExercise, e.ID, e.Title, e,
+						"", "lesson_id", l.ID); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+	return entries, nil
+}
+
+// ownFields returns v as a JSON object without its children field.
+func ownFields(v any, children string) (map[string]any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, children)
+	return fields, nil
+}
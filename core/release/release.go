@@ -0,0 +1,169 @@
+// Package release freezes the published content of a course into the
+// snapshots learner apps download, and compares snapshots.
+package release
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotPublished is returned when releasing a course that is not published.
+	ErrNotPublished = errors.New("course is not published")
+	// ErrUnchanged is returned when the content equals the latest release.
+	ErrUnchanged = errors.New("content has not changed since the latest release")
+	// ErrCorrupt is returned when stored content no longer matches its hash.
+	ErrCorrupt = errors.New("release content does not match its hash")
+)
+
+// Freeze copies the published part of a full course tree, as built with
+// every level, into release content. Content is only included when it and
+// all of its ancestors are published. options holds the options of the
+// course's exercises in order.
+func Freeze(tree *model.CourseTree, options []*model.ExerciseOption) *model.ReleaseContent {
+	optionsByExercise := map[uuid.UUID][]*model.ReleaseOption{}
+	for _, o := range options {
+		optionsByExercise[o.ExerciseID] = append(optionsByExercise[o.ExerciseID], &model.ReleaseOption{
+			ID:         o.ID,
+			Label:      o.Label,
+			Value:      o.Value,
+			IsCorrect:  o.IsCorrect,
+			MediaURL:   o.MediaURL,
+			OrderIndex: o.OrderIndex,
+		})
+	}
+
+	c := tree.Course
+	content := &model.ReleaseContent{
+		ID:          c.ID,
+		Slug:        c.Slug,
+		Title:       c.Title,
+		Description: c.Description,
+		Language:    c.Language,
+		Difficulty:  c.Difficulty,
+		Tags:        c.Tags,
+		Metadata:    c.Metadata,
+		Units:       []*model.ReleaseUnit{},
+	}
+
+	for _, un := range tree.Units {
+		u := un.Unit
+		if u.Status != model.StatePublished {
+			continue
+		}
+		unit := &model.ReleaseUnit{
+			ID:          u.ID,
+			Title:       u.Title,
+			Description: u.Description,
+			OrderIndex:  u.OrderIndex,
+			Skills:      []*model.ReleaseSkill{},
+		}
+		content.Units = append(content.Units, unit)
+
+		for _, sn := range un.Skills {
+			s := sn.Skill
+			if s.Status != model.StatePublished {
+				continue
+			}
+			skill := &model.ReleaseSkill{
+				ID:                   s.ID,
+				Slug:                 s.Slug,
+				Title:                s.Title,
+				Icon:                 s.Icon,
+				OrderIndex:           s.OrderIndex,
+				Difficulty:           s.Difficulty,
+				MaxCrowns:            s.MaxCrowns,
+				BaseXPReward:         s.BaseXPReward,
+				XPPerCrown:           s.XPPerCrown,
+				PrerequisiteSkillIDs: s.PrerequisiteSkillIDs,
+				Tags:                 s.Tags,
+				Metadata:             s.Metadata,
+				Lessons:              []*model.ReleaseLesson{},
+			}
+			unit.Skills = append(unit.Skills, skill)
+
+			for _, ln := range sn.Lessons {
+				l := ln.Lesson
+				if l.Status != model.StatePublished {
+					continue
+				}
+				lesson := &model.ReleaseLesson{
+					ID:                l.ID,
+					Slug:              l.Slug,
+					Title:             l.Title,
+					Description:       l.Description,
+					OrderIndex:        l.OrderIndex,
+					TotalExercises:    l.TotalExercises,
+					BaseXP:            l.BaseXP,
+					BonusXP:           l.BonusXP,
+					RewardGems:        l.RewardGems,
+					RewardHearts:      l.RewardHearts,
+					RewardCondition:   l.RewardCondition,
+					EstimatedDuration: l.EstimatedDuration,
+					DifficultyRating:  l.DifficultyRating,
+					IsTestable:        l.IsTestable,
+					Tags:              l.Tags,
+					Metadata:          l.Metadata,
+					Exercises:         []*model.ReleaseExercise{},
+				}
+				skill.Lessons = append(skill.Lessons, lesson)
+
+				for _, e := range ln.Exercises {
+					if e.Status != model.StatePublished {
+						continue
+					}
+					opts := optionsByExercise[e.ID]
+					if opts == nil {
+						opts = []*model.ReleaseOption{}
+					}
+					lesson.Exercises = append(lesson.Exercises, &model.ReleaseExercise{
+						ID:           e.ID,
+						Title:        e.Title,
+						Type:         e.Type,
+						MatchingType: e.MatchingType,
+						Prompt:       e.Prompt,
+						MediaURL:     e.MediaURL,
+						OrderIndex:   e.OrderIndex,
+						Points:       e.Points,
+						Grade:        e.Grade,
+						Syllabus:     e.Syllabus,
+						ObjectiveTag: e.ObjectiveTag,
+						Metadata:     e.Metadata,
+						Options:      opts,
+					})
+				}
+			}
+		}
+	}
+
+	return content
+}
+
+// Hash returns the content hash of release content, "sha256:" followed by
+// the hex digest of its JSON encoding. Field order is fixed by the structs
+// and map keys are sorted, so equal content always hashes the same.
+func Hash(content *model.ReleaseContent) (string, error) {
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// Verify reports ErrCorrupt unless content still hashes to hash.
+func Verify(content *model.ReleaseContent, hash string) error {
+	got, err := Hash(content)
+	if err != nil {
+		return err
+	}
+	if got != hash {
+		return ErrCorrupt
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/google/uuid"
+)
+
+// id gives each entity name a fixed ID.
+func id(name string) uuid.UUID {
+	return uuid.NewMD5(uuid.Nil, []byte(name))
+}
+
+func TestFreeze(t *testing.T) {
+	published, draft := model.StatePublished, model.StateDraft
+	exercise := func(name string, status model.WorkflowState) *model.Exercise {
+		return &model.Exercise{ID: id(name), Title: name, Status: status}
+	}
+	tree := &model.CourseTree{
+		Course: &model.Course{ID: id("C"), Title: "C", Status: published},
+		Units: []*model.UnitNode{
+			{Unit: &model.Unit{ID: id("U1"), Title: "U1", Status: published}, Skills: []*model.SkillNode{
+				{Skill: &model.Skill{ID: id("S1"), Title: "S1", Status: published}, Lessons: []*model.LessonNode{
+					{Lesson: &model.Lesson{ID: id("L1"), Title: "L1", Status: published}, Exercises: []*model.Exercise{
+						exercise("E1", published),
+						exercise("E2", draft),
+						exercise("E3", published),
+					}},
+					{Lesson: &model.Lesson{ID: id("L2"), Title: "L2", Status: draft}, Exercises: []*model.Exercise{
+						exercise("E4", published),
+					}},
+				}},
+				{Skill: &model.Skill{ID: id("S2"), Title: "S2", Status: draft}},
+			}},
+			{Unit: &model.Unit{ID: id("U2"), Title: "U2", Status: draft}, Skills: []*model.SkillNode{
+				{Skill: &model.Skill{ID: id("S3"), Title: "S3", Status: published}},
+			}},
+		},
+	}
+	options := []*model.ExerciseOption{
+		{ID: id("O1"), ExerciseID: id("E1"), Label: "O1", OrderIndex: 0},
+		{ID: id("O4"), ExerciseID: id("E4"), Label: "O4", OrderIndex: 0},
+		{ID: id("O2"), ExerciseID: id("E1"), Label: "O2", OrderIndex: 1},
+	}
+
+	content := Freeze(tree, options)
+
+	var got []string
+	for _, u := range content.Units {
+		got = append(got, u.Title)
+		for _, s := range u.Skills {
+			got = append(got, s.Title)
+			for _, l := range s.Lessons {
+				got = append(got, l.Title)
+				for _, e := range l.Exercises {
+					got = append(got, e.Title)
+					if e.Options == nil {
+						t.Errorf("options of %s are nil, want an empty list", e.Title)
+					}
+					for _, o := range e.Options {
+						got = append(got, o.Label)
+					}
+				}
+			}
+		}
+	}
+	want := []string{"U1", "S1", "L1", "E1", "O1", "O2", "E3"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Freeze() content = %v, want %v", got, want)
+	}
+	if content.ID != id("C") || content.Title != "C" {
+		t.Errorf("Freeze() course = %s %q", content.ID, content.Title)
+	}
+}
+
+// testContent builds release content with one unit, skill and lesson, and
+// two exercises with an option each.
+func testContent() *model.ReleaseContent {
+	exercise := func(name string) *model.ReleaseExercise {
+		return &model.ReleaseExercise{
+			ID: id(name), Title: name, Type: model.ExerciseMultipleChoice,
+			Metadata: model.JSONB{"b": 2, "a": 1},
+			Options: []*model.ReleaseOption{
+				{ID: id(name + "/O1"), Label: "O1", IsCorrect: true},
+			},
+		}
+	}
+	return &model.ReleaseContent{
+		ID: id("C"), Title: "C", Tags: []string{"theory"},
+		Metadata: map[string]any{"icon": "clef", "color": "blue"},
+		Units: []*model.ReleaseUnit{{
+			ID: id("U1"), Title: "U1",
+			Skills: []*model.ReleaseSkill{{
+				ID: id("S1"), Title: "S1",
+				Lessons: []*model.ReleaseLesson{{
+					ID: id("L1"), Title: "L1",
+					Exercises: []*model.ReleaseExercise{exercise("E1"), exercise("E2")},
+				}},
+			}},
+		}},
+	}
+}
+
+func TestHash(t *testing.T) {
+	hash, err := Hash(testContent())
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if !strings.HasPrefix(hash, "sha256:") || len(hash) != len("sha256:")+64 {
+		t.Errorf("Hash() = %q, want sha256: and 64 hex digits", hash)
+	}
+
+	tests := []struct {
+		name   string
+		change func(c *model.ReleaseContent)
+		same   bool
+	}{
+		{"same content", func(c *model.ReleaseContent) {}, true},
+		{"metadata built in another order", func(c *model.ReleaseContent) {
+			c.Metadata = map[string]any{"color": "blue", "icon": "clef"}
+		}, true},
+		{"course title", func(c *model.ReleaseContent) { c.Title = "D" }, false},
+		{"exercise order", func(c *model.ReleaseContent) {
+			ex := c.Units[0].Skills[0].Lessons[0].Exercises
+			ex[0], ex[1] = ex[1], ex[0]
+		}, false},
+		{"option", func(c *model.ReleaseContent) {
+			c.Units[0].Skills[0].Lessons[0].Exercises[0].Options[0].IsCorrect = false
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := testContent()
+			tt.change(content)
+			got, err := Hash(content)
+			if err != nil {
+				t.Fatalf("Hash() error = %v", err)
+			}
+			if (got == hash) != tt.same {
+				t.Errorf("Hash() = %s, original %s, want same %v", got, hash, tt.same)
+			}
+			err = Verify(content, hash)
+			if tt.same && err != nil {
+				t.Errorf("Verify() error = %v, want nil", err)
+			}
+			if !tt.same && !errors.Is(err, ErrCorrupt) {
+				t.Errorf("Verify() error = %v, want ErrCorrupt", err)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	lesson := func(c *model.ReleaseContent) *model.ReleaseLesson {
+		return c.Units[0].Skills[0].Lessons[0]
+	}
+
+	tests := []struct {
+		name   string
+		change func(c *model.ReleaseContent)
+		want   []string // "kind title: fields"
+	}{
+		{"unchanged", func(c *model.ReleaseContent) {}, nil},
+		{"course fields", func(c *model.ReleaseContent) {
+			c.Metadata["icon"] = "note"
+			c.Tags = append(c.Tags, "ear")
+		}, []string{"changed C: metadata.icon tags"}},
+		{"exercise title", func(c *model.ReleaseContent) {
+			lesson(c).Exercises[1].Title = "E2 renamed"
+		}, []string{"changed E2 renamed: title"}},
+		{"option compared as one field", func(c *model.ReleaseContent) {
+			lesson(c).Exercises[0].Options[0].Label = "O1 renamed"
+		}, []string{"changed E1: options"}},
+		{"children are not the parent's fields", func(c *model.ReleaseContent) {
+			lesson(c).Exercises = lesson(c).Exercises[:1]
+		}, []string{"removed E2: "}},
+		{"exercise moved to a new lesson", func(c *model.ReleaseContent) {
+			e2 := lesson(c).Exercises[1]
+			lesson(c).Exercises = lesson(c).Exercises[:1]
+			skill := c.Units[0].Skills[0]
+			skill.Lessons = append(skill.Lessons, &model.ReleaseLesson{
+				ID: id("L2"), Title: "L2", Exercises: []*model.ReleaseExercise{e2},
+			})
+		}, []string{"added L2: ", "changed E2: lesson_id"}},
+		{"skill moved to a new unit", func(c *model.ReleaseContent) {
+			s1 := c.Units[0].Skills[0]
+			c.Units[0].Skills = nil
+			c.Units = append(c.Units, &model.ReleaseUnit{
+				ID: id("U2"), Title: "U2", Skills: []*model.ReleaseSkill{s1},
+			})
+		}, []string{"added U2: ", "changed S1: unit_id"}},
+		{"added before removed", func(c *model.ReleaseContent) {
+			c.Units = []*model.ReleaseUnit{{ID: id("U2"), Title: "U2"}}
+		}, []string{
+			"added U2: ",
+			"removed U1: ", "removed S1: ", "removed L1: ", "removed E1: ", "removed E2: ",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			to := testContent()
+			tt.change(to)
+			changes, err := Diff(testContent(), to)
+			if err != nil {
+				t.Fatalf("Diff() error = %v", err)
+			}
+			var got []string
+			for _, c := range changes {
+				fields := make([]string, len(c.Fields))
+				for i, f := range c.Fields {
+					fields[i] = f.Field
+				}
+				got = append(got, fmt.Sprintf("%s %s: %s", c.Kind, c.Title, strings.Join(fields, " ")))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Diff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffEntityTypes(t *testing.T) {
+	changes, err := Diff(&model.ReleaseContent{ID: id("C")}, testContent())
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	var got []model.EntityType
+	for _, c := range changes {
+		if c.EntityID != id(c.Title) {
+			t.Errorf("change %q has ID %s", c.Title, c.EntityID)
+		}
+		got = append(got, c.EntityType)
+	}
+	want := []model.EntityType{
+		model.EntityCourse, model.EntityUnit, model.EntitySkill, model.EntityLesson,
+		model.EntityExercise, model.EntityExercise,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Diff() entity types = %v, want %v", got, want)
+	}
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/bytebeatz/bandroom-cms/core/model"
 	"github.com/google/uuid"
@@ -12,9 +13,22 @@ type CourseRepository interface {
 	Create(ctx context.Context, course *model.Course) error
 	Update(ctx context.Context, course *model.Course) error
 	Delete(ctx context.Context, id uuid.UUID) error
-	GetByID(ctx context.Context, id uuid.UUID) (*model.Course, error)
+	Restore(ctx context.Context, id uuid.UUID) error
+	GetByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*model.Course, error)
 	GetBySlug(ctx context.Context, slug string) (*model.Course, error)
-	List(ctx context.Context, publishedOnly bool) ([]*model.Course, error)
+	// List returns one page of courses matching q.
+	List(ctx context.Context, q ListQuery) (*Page[*model.Course], error)
+
+	// SetSchedule stores when a course is to be published and unpublished and
+	// who scheduled it; nil times clear the schedule.
+	SetSchedule(ctx context.Context, course *model.Course) error
+	// ListDueSchedules returns live courses with a publish_at or
+	// unpublish_at at or before now.
+	ListDueSchedules(ctx context.Context, now time.Time) ([]*model.Course, error)
+	// ClearPublishAt and ClearUnpublishAt drop a schedule once it has been
+	// handled, unless it was changed to a different time meanwhile.
+	ClearPublishAt(ctx context.Context, id uuid.UUID, at time.Time) error
+	ClearUnpublishAt(ctx context.Context, id uuid.UUID, at time.Time) error
 
 	// ✅ New method for conflict prevention
 	ExistsByTitle(ctx context.Context, title string) (bool, error)
@@ -0,0 +1,12 @@
+package repository
+
+import "errors"
+
+// ErrParentDeleted is returned when restoring an entity whose parent is still deleted.
+var ErrParentDeleted = errors.New("parent is deleted")
+
+// ErrVersionConflict is returned when an update was based on a stale version.
+var ErrVersionConflict = errors.New("version conflict")
+
+// ErrMediaInUse is returned when deleting media that content still uses.
+var ErrMediaInUse = errors.New("media is in use")
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/google/uuid"
+)
+
+// ExerciseOptionRepository defines contract for accessing exercise option data.
+type ExerciseOptionRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*model.ExerciseOption, error)
+	ListByExerciseID(ctx context.Context, exerciseID uuid.UUID) ([]*model.ExerciseOption, error)
+	// ListForUpdate is ListByExerciseID that also locks the exercise until the
+	// transaction in ctx ends, so option edits of one exercise run one at a time.
+	ListForUpdate(ctx context.Context, exerciseID uuid.UUID) ([]*model.ExerciseOption, error)
+	// ListByCourseID returns the options of every live exercise of a course.
+	ListByCourseID(ctx context.Context, courseID uuid.UUID) ([]*model.ExerciseOption, error)
+
+	// ReplaceAll swaps the full option set of an exercise in one transaction.
+	// Options are written as edited; callers validate the set first.
+	ReplaceAll(ctx context.Context, exerciseID uuid.UUID, options []*model.ExerciseOption) error
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/google/uuid"
+)
+
+// ExerciseRepository defines contract for accessing exercise data.
+type ExerciseRepository interface {
+	Create(ctx context.Context, exercise *model.Exercise) error
+	// CreateMany inserts exercises together with their options, keyed by
+	// exercise ID, in one transaction.
+	CreateMany(
+		ctx context.Context,
+		exercises []*model.Exercise,
+		options map[uuid.UUID][]*model.ExerciseOption,
+	) error
+	Update(ctx context.Context, exercise *model.Exercise) error
+	// UpdateOrder saves the position of each exercise (parent and order_index) in
+	// one transaction, with the same version check as Update.
+	UpdateOrder(ctx context.Context, exercises []*model.Exercise) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) error
+	GetByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*model.Exercise, error)
+	ListByLessonID(
+		ctx context.Context,
+		lessonID uuid.UUID,
+		includeDeleted bool,
+	) ([]*model.Exercise, error)
+	ListBySkillID(
+		ctx context.Context,
+		skillID uuid.UUID,
+		includeDeleted bool,
+	) ([]*model.Exercise, error)
+	// ListByCourseID returns every live exercise of a course ordered by order_index.
+	ListByCourseID(ctx context.Context, courseID uuid.UUID) ([]*model.Exercise, error)
+	// CountByLessonForCourse returns the number of live exercises per lesson of a course.
+	CountByLessonForCourse(ctx context.Context, courseID uuid.UUID) (map[uuid.UUID]int, error)
+}
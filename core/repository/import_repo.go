@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+)
+
+// ImportRepository writes course imports.
+type ImportRepository interface {
+	// Apply writes everything in plan in one transaction, so an import is
+	// either applied whole or not at all. Updates are guarded by version and
+	// fail with ErrVersionConflict; versions of written entities are bumped.
+	Apply(ctx context.Context, plan *model.ImportPlan) error
+}
@@ -10,10 +10,25 @@ import (
 // LessonRepository defines contract for accessing lesson data.
 type LessonRepository interface {
 	Create(ctx context.Context, lesson *model.Lesson) error
+	// List returns one page of lessons matching q.
+	List(ctx context.Context, q ListQuery) (*Page[*model.Lesson], error)
 	Update(ctx context.Context, lesson *model.Lesson) error
+	// UpdateOrder saves the position of each lesson (parent and order_index) in
+	// one transaction, with the same version check as Update.
+	UpdateOrder(ctx context.Context, lessons []*model.Lesson) error
 	Delete(ctx context.Context, id uuid.UUID) error
-	GetByID(ctx context.Context, id uuid.UUID) (*model.Lesson, error)
-	ListBySkillID(ctx context.Context, skillID uuid.UUID) ([]*model.Lesson, error)
+	Restore(ctx context.Context, id uuid.UUID) error
+	GetByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*model.Lesson, error)
+	ListBySkillID(
+		ctx context.Context,
+		skillID uuid.UUID,
+		includeDeleted bool,
+	) ([]*model.Lesson, error)
+	// ListByCourseID returns every live lesson of a course ordered by order_index.
+	ListByCourseID(ctx context.Context, courseID uuid.UUID) ([]*model.Lesson, error)
+	// Lock holds the lesson row until the transaction in ctx ends, so
+	// exercises are appended to one lesson in turn.
+	Lock(ctx context.Context, id uuid.UUID) error
 
 	// For conflict checking
 	ExistsByTitleInSkill(ctx context.Context, skillID uuid.UUID, title string) (bool, error)
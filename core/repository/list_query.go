@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultPageSize is used when a list request does not set a limit.
+	DefaultPageSize = 20
+	// MaxPageSize caps the limit a client may ask for.
+	MaxPageSize = 100
+)
+
+// ErrInvalidQuery is returned for sorts, filters, cursors or search terms an
+// endpoint does not support.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// ListQuery describes one page of a list: filters, sort order and the
+// position to continue from. Filters left at their zero value are ignored.
+type ListQuery struct {
+	ParentID    *uuid.UUID // course for units, unit for skills, skill for lessons
+	Language    string
+	Difficulty  *int
+	IsPublished *bool
+	Tags        []string // rows must carry every tag
+	CreatorID   *uuid.UUID
+	Status      model.WorkflowState
+
+	IncludeDeleted bool
+
+	Sort  string // title, created_at, updated_at or order_index
+	Desc  bool
+	Limit int
+	After *Cursor // continue after this row; nil for the first page
+}
+
+// Page is one page of results. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+// Cursor is the keyset position of the last row of a page. It also records the
+// sort it was issued for, so it cannot be replayed against another order.
+type Cursor struct {
+	Sort  string    `json:"s"`
+	Desc  bool      `json:"d,omitempty"`
+	Value any       `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// Encode turns the cursor into the opaque string handed to clients.
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a cursor produced by Cursor.Encode.
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidQuery
+	}
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.Sort == "" || c.ID == uuid.Nil {
+		return nil, ErrInvalidQuery
+	}
+	return &c, nil
+}
@@ -0,0 +1,10 @@
+package repository
+
+import "context"
+
+// Locker hands out locks shared by every server replica.
+type Locker interface {
+	// TryLock takes the named lock without waiting. When ok is true the lock
+	// is held until release is called.
+	TryLock(ctx context.Context, name string) (release func(), ok bool, err error)
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/google/uuid"
+)
+
+// MediaRepository stores the records of uploaded media files.
+type MediaRepository interface {
+	Create(ctx context.Context, asset *model.MediaAsset) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.MediaAsset, error)
+	// GetByURLs returns the assets with the given URLs; URLs without an
+	// asset are left out.
+	GetByURLs(ctx context.Context, urls []string) ([]*model.MediaAsset, error)
+	// Delete removes the record unless an exercise or option, deleted or
+	// not, or a release uses the asset's URL, in which case it returns
+	// ErrMediaInUse.
+	Delete(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/google/uuid"
+)
+
+// ReleaseRepository stores course releases, which are never changed once
+// created, and the channels pointing at them.
+type ReleaseRepository interface {
+	// Create stores rel as the course's next version and sets rel.Version.
+	Create(ctx context.Context, rel *model.Release) error
+	GetByVersion(ctx context.Context, courseID uuid.UUID, version int) (*model.Release, error)
+	GetByChannel(
+		ctx context.Context,
+		courseID uuid.UUID,
+		channel model.ReleaseChannel,
+	) (*model.Release, error)
+	// ListByCourse returns the course's releases newest first, without content.
+	ListByCourse(ctx context.Context, courseID uuid.UUID) ([]*model.Release, error)
+	// Promote points a channel of the course at a release.
+	Promote(
+		ctx context.Context,
+		courseID uuid.UUID,
+		channel model.ReleaseChannel,
+		releaseID uuid.UUID,
+		by *uuid.UUID,
+		at time.Time,
+	) error
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/google/uuid"
+)
+
+type RevisionRepository interface {
+	// Create stores a revision; a version that is already recorded is left as is.
+	Create(ctx context.Context, rev *model.Revision) error
+	GetByVersion(
+		ctx context.Context,
+		entityType model.EntityType,
+		entityID uuid.UUID,
+		version int,
+	) (*model.Revision, error)
+	// ListByEntity returns the revisions newest first, without their snapshots.
+	ListByEntity(
+		ctx context.Context,
+		entityType model.EntityType,
+		entityID uuid.UUID,
+	) ([]*model.Revision, error)
+}
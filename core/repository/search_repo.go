@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/google/uuid"
+)
+
+// SearchQuery describes a full-text search across the curriculum.
+type SearchQuery struct {
+	Text     string
+	Types    []model.EntityType // empty searches every type
+	CourseID *uuid.UUID
+	Limit    int
+}
+
+type SearchRepository interface {
+	// Search returns the best matching content, highest rank first.
+	Search(ctx context.Context, q SearchQuery) ([]*model.SearchHit, error)
+}
@@ -10,10 +10,25 @@ import (
 // SkillRepository defines contract for accessing skill data.
 type SkillRepository interface {
 	Create(ctx context.Context, skill *model.Skill) error
+	// List returns one page of skills matching q.
+	List(ctx context.Context, q ListQuery) (*Page[*model.Skill], error)
 	Update(ctx context.Context, skill *model.Skill) error
+	// UpdateOrder saves the position of each skill (parent and order_index) in
+	// one transaction, with the same version check as Update.
+	UpdateOrder(ctx context.Context, skills []*model.Skill) error
 	Delete(ctx context.Context, id uuid.UUID) error
-	GetByID(ctx context.Context, id uuid.UUID) (*model.Skill, error)
-	ListByUnitID(ctx context.Context, unitID uuid.UUID) ([]*model.Skill, error)
+	Restore(ctx context.Context, id uuid.UUID) error
+	GetByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*model.Skill, error)
+	ListByUnitID(
+		ctx context.Context,
+		unitID uuid.UUID,
+		includeDeleted bool,
+	) ([]*model.Skill, error)
+	// ListByCourseID returns every live skill of a course ordered by order_index.
+	ListByCourseID(ctx context.Context, courseID uuid.UUID) ([]*model.Skill, error)
+	// LockCourse holds the course row until the transaction in ctx ends, so
+	// prerequisite changes within one course are checked and saved in turn.
+	LockCourse(ctx context.Context, courseID uuid.UUID) error
 
 	// For conflict checking
 	ExistsByTitleInCourse(ctx context.Context, courseID uuid.UUID, title string) (bool, error)
@@ -0,0 +1,11 @@
+package repository
+
+import "context"
+
+// Transactor runs work in one database transaction.
+type Transactor interface {
+	// InTx calls fn with a context carrying a transaction that is committed
+	// if fn returns nil and rolled back otherwise. Repository calls made with
+	// that context join the transaction, as do nested InTx calls.
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
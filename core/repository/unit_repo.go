@@ -9,8 +9,18 @@ import (
 
 type UnitRepository interface {
 	Create(ctx context.Context, unit *model.Unit) error
-	GetByID(ctx context.Context, id uuid.UUID) (*model.Unit, error)
-	ListByCourseID(ctx context.Context, courseID uuid.UUID) ([]*model.Unit, error)
+	GetByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*model.Unit, error)
+	ListByCourseID(
+		ctx context.Context,
+		courseID uuid.UUID,
+		includeDeleted bool,
+	) ([]*model.Unit, error)
+	// List returns one page of units matching q.
+	List(ctx context.Context, q ListQuery) (*Page[*model.Unit], error)
 	Update(ctx context.Context, unit *model.Unit) error
+	// UpdateOrder saves the position of each unit (parent and order_index) in
+	// one transaction, with the same version check as Update.
+	UpdateOrder(ctx context.Context, units []*model.Unit) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) error
 }
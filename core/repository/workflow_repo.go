@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/google/uuid"
+)
+
+// WorkflowRepository moves content between workflow states.
+type WorkflowRepository interface {
+	// SetStatus applies every change in one transaction, bumping each
+	// entity's version. It fails with ErrVersionConflict if an entity is no
+	// longer in its From state at its Version.
+	SetStatus(ctx context.Context, changes []model.StatusChange, at time.Time) error
+	// LockStatus keeps an entity from changing until the transaction in ctx
+	// ends. It fails with ErrVersionConflict unless the entity is still in
+	// state status at version.
+	LockStatus(
+		ctx context.Context,
+		entityType model.EntityType,
+		id uuid.UUID,
+		status model.WorkflowState,
+		version int,
+	) error
+}
@@ -2,23 +2,32 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/bytebeatz/bandroom-cms/core/model"
 	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/bytebeatz/bandroom-cms/core/workflow"
 	"github.com/bytebeatz/bandroom-cms/utils"
 	"github.com/google/uuid"
 )
 
 // CourseService handles business logic for courses.
 type CourseService struct {
-	repo repository.CourseRepository
+	repo      repository.CourseRepository
+	revisions repository.RevisionRepository
+	tx        repository.Transactor
 }
 
-func NewCourseService(repo repository.CourseRepository) *CourseService {
-	return &CourseService{repo: repo}
+func NewCourseService(
+	repo repository.CourseRepository,
+	revisions repository.RevisionRepository,
+	tx repository.Transactor,
+) *CourseService {
+	return &CourseService{repo: repo, revisions: revisions, tx: tx}
 }
 
 func (s *CourseService) CreateCourse(ctx context.Context, course *model.Course) error {
@@ -39,55 +48,112 @@ func (s *CourseService) CreateCourse(ctx context.Context, course *model.Course)
 		course.Slug = utils.GenerateSlug(course.Title)
 	}
 
-	if course.Version == 0 {
-		course.Version = 1
-	}
+	course.Version = 1
 
-	if val := ctx.Value("user_id"); val != nil {
-		if userIDStr, ok := val.(string); ok {
-			if parsed, err := uuid.Parse(userIDStr); err == nil {
-				course.CreatorID = &parsed
-			}
-		}
+	// New courses start as drafts; publishing goes through the workflow
+	course.Status = model.StateDraft
+	course.IsPublished = false
+
+	if userID := currentUserID(ctx); userID != nil {
+		course.CreatorID = userID
 	}
 
 	fmt.Printf("Creating course: %+v\n", course)
-	return s.repo.Create(ctx, course)
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, course); err != nil {
+			return err
+		}
+		return recordRevision(ctx, s.revisions, model.EntityCourse, course.ID, course.Version, course,
+			currentUserID(ctx), course.CreatedAt)
+	})
 }
 
 func (s *CourseService) UpdateCourse(ctx context.Context, updated *model.Course) error {
-	existing, err := s.repo.GetByID(ctx, updated.ID)
+	existing, err := s.repo.GetByID(ctx, updated.ID, false)
 	if err != nil {
 		return fmt.Errorf("course not found: %w", err)
 	}
 
+	// Version holds the version the client last read; reject stale writes
+	if updated.Version != existing.Version {
+		return repository.ErrVersionConflict
+	}
+
+	// Fields the update does not write keep their stored values, so the
+	// response and revision show the row as saved
+	updated.CreatorID = existing.CreatorID
+	updated.PublishAt, updated.PublishScheduledBy = existing.PublishAt, existing.PublishScheduledBy
+	updated.UnpublishAt, updated.UnpublishScheduledBy = existing.UnpublishAt, existing.UnpublishScheduledBy
 	updated.CreatedAt = existing.CreatedAt
+	// Workflow state only changes through transitions, except that an edit
+	// sends approved content back to draft
+	if updated.Status, err = workflow.AfterEdit(existing.Status); err != nil {
+		return err
+	}
+	updated.IsPublished = existing.IsPublished
 	updated.UpdatedAt = time.Now().UTC()
-	updated.Version = existing.Version + 1
 
 	if updated.Slug == "" {
 		updated.Slug = utils.GenerateSlug(updated.Title)
 	}
 
-	return s.repo.Update(ctx, updated)
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		// Keep the version being replaced, in case it predates revision history
+		if err := recordRevision(ctx, s.revisions, model.EntityCourse, existing.ID, existing.Version,
+			existing, nil, existing.UpdatedAt); err != nil {
+			return err
+		}
+
+		if err := s.repo.Update(ctx, updated); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("course not found: %w", err)
+			}
+			return err
+		}
+		return recordRevision(ctx, s.revisions, model.EntityCourse, updated.ID, updated.Version, updated,
+			currentUserID(ctx), updated.UpdatedAt)
+	})
 }
 
+// DeleteCourse soft-deletes a course and everything beneath it.
 func (s *CourseService) DeleteCourse(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("course not found: %w", err)
+		}
+		return err
+	}
+	return nil
 }
 
-func (s *CourseService) GetCourseByID(ctx context.Context, id uuid.UUID) (*model.Course, error) {
-	return s.repo.GetByID(ctx, id)
+// RestoreCourse brings back a soft-deleted course and the content deleted with it.
+func (s *CourseService) RestoreCourse(ctx context.Context, id uuid.UUID) (*model.Course, error) {
+	if err := s.repo.Restore(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("course not found: %w", err)
+		}
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id, false)
+}
+
+func (s *CourseService) GetCourseByID(
+	ctx context.Context,
+	id uuid.UUID,
+	includeDeleted bool,
+) (*model.Course, error) {
+	return s.repo.GetByID(ctx, id, includeDeleted)
 }
 
 func (s *CourseService) GetCourseBySlug(ctx context.Context, slug string) (*model.Course, error) {
 	return s.repo.GetBySlug(ctx, slug)
 }
 
+// ListCourses returns one page of courses matching q.
 func (s *CourseService) ListCourses(
 	ctx context.Context,
-	publishedOnly bool,
-) ([]*model.Course, error) {
-	return s.repo.List(ctx, publishedOnly)
+	q repository.ListQuery,
+) (*repository.Page[*model.Course], error) {
+	return s.repo.List(ctx, q)
 }
 
@@ -0,0 +1,549 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/exercisecsv"
+	"github.com/bytebeatz/bandroom-cms/core/grading"
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/bytebeatz/bandroom-cms/core/validation"
+	"github.com/bytebeatz/bandroom-cms/core/workflow"
+	"github.com/google/uuid"
+)
+
+// ExerciseService handles business logic for exercises.
+type ExerciseService struct {
+	repo       repository.ExerciseRepository
+	optionRepo repository.ExerciseOptionRepository
+	lessonRepo repository.LessonRepository
+	skillRepo  repository.SkillRepository
+	revisions  repository.RevisionRepository
+	tx         repository.Transactor
+}
+
+func NewExerciseService(
+	repo repository.ExerciseRepository,
+	optionRepo repository.ExerciseOptionRepository,
+	lessonRepo repository.LessonRepository,
+	skillRepo repository.SkillRepository,
+	revisions repository.RevisionRepository,
+	tx repository.Transactor,
+) *ExerciseService {
+	return &ExerciseService{
+		repo:       repo,
+		optionRepo: optionRepo,
+		lessonRepo: lessonRepo,
+		skillRepo:  skillRepo,
+		revisions:  revisions,
+		tx:         tx,
+	}
+}
+
+func (s *ExerciseService) CreateExercise(
+	ctx context.Context,
+	exercise *model.Exercise,
+	lessonID uuid.UUID,
+) error {
+	if exercise.Metadata == nil {
+		exercise.Metadata = model.JSONB{}
+	}
+	if err := validation.ValidateExercise(exercise); err != nil {
+		return err
+	}
+
+	// Exercises always inherit their skill from the owning lesson
+	lesson, err := s.lessonRepo.GetByID(ctx, lessonID, false)
+	if err != nil {
+		return fmt.Errorf("lesson not found: %w", err)
+	}
+
+	now := time.Now().UTC()
+	exercise.ID = uuid.New()
+	exercise.LessonID = lesson.ID
+	exercise.SkillID = lesson.SkillID
+	exercise.Version = 1
+	exercise.Status = model.StateDraft
+	exercise.CreatedAt = now
+	exercise.UpdatedAt = now
+
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, exercise); err != nil {
+			return err
+		}
+		return recordExerciseRevision(ctx, s.revisions, s.optionRepo, exercise,
+			currentUserID(ctx), exercise.CreatedAt)
+	})
+}
+
+// ImportExercises creates the exercises of CSV rows, already validated by
+// exercisecsv.Parse, at the end of a lesson, all in one transaction. The
+// lesson is locked while its last position is read, so concurrent imports
+// into it append one after the other.
+func (s *ExerciseService) ImportExercises(
+	ctx context.Context,
+	lessonID uuid.UUID,
+	rows []exercisecsv.Row,
+) ([]*model.Exercise, error) {
+	var exercises []*model.Exercise
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.lessonRepo.Lock(ctx, lessonID); err != nil {
+			return err
+		}
+		lesson, err := s.lessonRepo.GetByID(ctx, lessonID, false)
+		if err != nil {
+			return fmt.Errorf("lesson not found: %w", err)
+		}
+		existing, err := s.repo.ListByLessonID(ctx, lesson.ID, false)
+		if err != nil {
+			return err
+		}
+		next := 0
+		for _, e := range existing {
+			next = max(next, e.OrderIndex+1)
+		}
+
+		now := time.Now().UTC()
+		exercises = make([]*model.Exercise, 0, len(rows))
+		options := map[uuid.UUID][]*model.ExerciseOption{}
+		for i, row := range rows {
+			e := row.Exercise
+			e.ID = uuid.New()
+			e.LessonID = lesson.ID
+			e.SkillID = lesson.SkillID
+			e.OrderIndex = next + i
+			e.Version = 1
+			e.Status = model.StateDraft
+			e.CreatedAt = now
+			e.UpdatedAt = now
+			exercises = append(exercises, e)
+
+			for _, o := range row.Options {
+				o.ID = uuid.New()
+				o.ExerciseID = e.ID
+				o.CreatedAt = now
+				o.UpdatedAt = now
+				options[e.ID] = append(options[e.ID], &o)
+			}
+		}
+
+		if err := s.repo.CreateMany(ctx, exercises, options); err != nil {
+			return err
+		}
+		for _, e := range exercises {
+			if err := recordExerciseRevision(ctx, s.revisions, s.optionRepo, e,
+				currentUserID(ctx), e.CreatedAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return exercises, nil
+}
+
+func (s *ExerciseService) UpdateExercise(ctx context.Context, updated *model.Exercise) error {
+	return s.updateExercise(ctx, updated, nil)
+}
+
+// updateExercise is UpdateExercise that also replaces the exercise's options
+// unless options is nil, as one change. Rollbacks use it to restore both.
+func (s *ExerciseService) updateExercise(
+	ctx context.Context,
+	updated *model.Exercise,
+	options []*model.ExerciseOption,
+) error {
+	if updated.Metadata == nil {
+		updated.Metadata = model.JSONB{}
+	}
+	if err := validation.ValidateExercise(updated); err != nil {
+		return err
+	}
+
+	existing, err := s.repo.GetByID(ctx, updated.ID, false)
+	if err != nil {
+		return fmt.Errorf("exercise not found: %w", err)
+	}
+
+	// Version holds the version the client last read; reject stale writes
+	if updated.Version != existing.Version {
+		return repository.ErrVersionConflict
+	}
+
+	// Moves go through ReorderExercises, which checks the target lesson
+	updated.LessonID = existing.LessonID
+	updated.SkillID = existing.SkillID
+	updated.OrderIndex = existing.OrderIndex
+	updated.CreatedAt = existing.CreatedAt
+	// Workflow state only changes through transitions, except that an edit
+	// sends approved content back to draft
+	if updated.Status, err = workflow.AfterEdit(existing.Status); err != nil {
+		return err
+	}
+	updated.UpdatedAt = time.Now().UTC()
+
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		// The options must still fit the exercise when only its type or
+		// matching type changes, so check the stored ones, held until commit
+		current, err := s.optionRepo.ListForUpdate(ctx, updated.ID)
+		if err != nil {
+			return err
+		}
+		check := options
+		if check == nil {
+			check = current
+		}
+		values := make([]model.ExerciseOption, 0, len(check))
+		for i, o := range check {
+			o.ExerciseID = updated.ID
+			o.OrderIndex = i
+			values = append(values, *o)
+		}
+		if err := validation.ValidateOptions(updated, values); err != nil {
+			return err
+		}
+
+		// Keep the version being replaced, in case it predates revision history
+		if err := recordExerciseRevision(ctx, s.revisions, s.optionRepo, existing,
+			nil, existing.UpdatedAt); err != nil {
+			return err
+		}
+
+		if options != nil {
+			if err := s.optionRepo.ReplaceAll(ctx, updated.ID, options); err != nil {
+				return err
+			}
+		}
+		if err := s.repo.Update(ctx, updated); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("exercise not found: %w", err)
+			}
+			return err
+		}
+		return recordExerciseRevision(ctx, s.revisions, s.optionRepo, updated,
+			currentUserID(ctx), updated.UpdatedAt)
+	})
+}
+
+// DeleteExercise soft-deletes an exercise.
+func (s *ExerciseService) DeleteExercise(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("exercise not found: %w", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// RestoreExercise brings back a soft-deleted exercise.
+func (s *ExerciseService) RestoreExercise(
+	ctx context.Context,
+	id uuid.UUID,
+) (*model.Exercise, error) {
+	if err := s.repo.Restore(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("exercise not found: %w", err)
+		}
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id, false)
+}
+
+func (s *ExerciseService) GetExerciseByID(
+	ctx context.Context,
+	id uuid.UUID,
+	includeDeleted bool,
+) (*model.Exercise, error) {
+	return s.repo.GetByID(ctx, id, includeDeleted)
+}
+
+func (s *ExerciseService) ListExercisesByLessonID(
+	ctx context.Context,
+	lessonID uuid.UUID,
+	includeDeleted bool,
+) ([]*model.Exercise, error) {
+	return s.repo.ListByLessonID(ctx, lessonID, includeDeleted)
+}
+
+func (s *ExerciseService) ListExercisesBySkillID(
+	ctx context.Context,
+	skillID uuid.UUID,
+	includeDeleted bool,
+) ([]*model.Exercise, error) {
+	return s.repo.ListBySkillID(ctx, skillID, includeDeleted)
+}
+
+func (s *ExerciseService) ListOptions(
+	ctx context.Context,
+	exerciseID uuid.UUID,
+) ([]*model.ExerciseOption, error) {
+	if _, err := s.repo.GetByID(ctx, exerciseID, false); err != nil {
+		return nil, fmt.Errorf("exercise not found: %w", err)
+	}
+	return s.optionRepo.ListByExerciseID(ctx, exerciseID)
+}
+
+// AddOption appends a single option to the end of an exercise's option list.
+func (s *ExerciseService) AddOption(
+	ctx context.Context,
+	exerciseID uuid.UUID,
+	option *model.ExerciseOption,
+) error {
+	return s.editOptions(ctx, exerciseID, func(current []*model.ExerciseOption) ([]*model.ExerciseOption, error) {
+		now := time.Now().UTC()
+		option.ID = uuid.New()
+		option.CreatedAt = now
+		option.UpdatedAt = now
+		return append(current, option), nil
+	})
+}
+
+// UpdateOption edits an option's content; its position is changed via ReorderOptions.
+func (s *ExerciseService) UpdateOption(
+	ctx context.Context,
+	exerciseID uuid.UUID,
+	updated *model.ExerciseOption,
+) error {
+	return s.editOptions(ctx, exerciseID, func(current []*model.ExerciseOption) ([]*model.ExerciseOption, error) {
+		for i, o := range current {
+			if o.ID == updated.ID {
+				updated.CreatedAt = o.CreatedAt
+				updated.UpdatedAt = time.Now().UTC()
+				current[i] = updated
+				return current, nil
+			}
+		}
+		return nil, fmt.Errorf("option not found in this exercise")
+	})
+}
+
+// DeleteOption removes an option and closes the gap it leaves in the ordering.
+func (s *ExerciseService) DeleteOption(
+	ctx context.Context,
+	exerciseID uuid.UUID,
+	optionID uuid.UUID,
+) error {
+	return s.editOptions(ctx, exerciseID, func(current []*model.ExerciseOption) ([]*model.ExerciseOption, error) {
+		for i, o := range current {
+			if o.ID == optionID {
+				return append(current[:i], current[i+1:]...), nil
+			}
+		}
+		return nil, fmt.Errorf("option not found in this exercise")
+	})
+}
+
+// ReplaceOptions validates and saves the full option set of an exercise in one
+// transaction. Options are ordered by their position in the slice; options
+// that carry the ID of an existing option keep that ID.
+func (s *ExerciseService) ReplaceOptions(
+	ctx context.Context,
+	exerciseID uuid.UUID,
+	options []*model.ExerciseOption,
+) error {
+	return s.editOptions(ctx, exerciseID, func(current []*model.ExerciseOption) ([]*model.ExerciseOption, error) {
+		createdAt := make(map[uuid.UUID]time.Time, len(current))
+		for _, o := range current {
+			createdAt[o.ID] = o.CreatedAt
+		}
+
+		now := time.Now().UTC()
+		seen := make(map[uuid.UUID]bool, len(options))
+		for _, o := range options {
+			if created, ok := createdAt[o.ID]; ok && !seen[o.ID] {
+				o.CreatedAt = created
+			} else {
+				o.ID = uuid.New()
+				o.CreatedAt = now
+			}
+			seen[o.ID] = true
+			o.UpdatedAt = now
+		}
+		return options, nil
+	})
+}
+
+// editOptions saves the option set edit makes of an exercise's current
+// options, in order, once it passes validation. Reading, checking and
+// writing happen in one transaction holding the exercise, so every edit
+// starts from the set the previous one saved. Options are versioned with
+// their exercise, so the exercise gets a new version and revision.
+func (s *ExerciseService) editOptions(
+	ctx context.Context,
+	exerciseID uuid.UUID,
+	edit func(current []*model.ExerciseOption) ([]*model.ExerciseOption, error),
+) error {
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		current, err := s.optionRepo.ListForUpdate(ctx, exerciseID)
+		if err != nil {
+			return err
+		}
+		exercise, err := s.repo.GetByID(ctx, exerciseID, false)
+		if err != nil {
+			return fmt.Errorf("exercise not found: %w", err)
+		}
+		status, err := workflow.AfterEdit(exercise.Status)
+		if err != nil {
+			return err
+		}
+
+		// Keep the version being replaced, in case it predates revision history
+		if err := recordExerciseRevision(ctx, s.revisions, s.optionRepo, exercise,
+			nil, exercise.UpdatedAt); err != nil {
+			return err
+		}
+
+		options, err := edit(current)
+		if err != nil {
+			return err
+		}
+		values := make([]model.ExerciseOption, 0, len(options))
+		for i, o := range options {
+			o.ExerciseID = exerciseID
+			o.OrderIndex = i
+			values = append(values, *o)
+		}
+		if err := validation.ValidateOptions(exercise, values); err != nil {
+			return err
+		}
+		if err := s.optionRepo.ReplaceAll(ctx, exerciseID, options); err != nil {
+			return err
+		}
+
+		exercise.Status = status
+		exercise.UpdatedAt = time.Now().UTC()
+		if err := s.repo.Update(ctx, exercise); err != nil {
+			return err
+		}
+		return recordExerciseRevision(ctx, s.revisions, s.optionRepo, exercise,
+			currentUserID(ctx), exercise.UpdatedAt)
+	})
+}
+
+// ReorderOptions rewrites option positions. The ID list must contain exactly
+// the exercise's current options.
+func (s *ExerciseService) ReorderOptions(
+	ctx context.Context,
+	exerciseID uuid.UUID,
+	ids []uuid.UUID,
+) error {
+	return s.editOptions(ctx, exerciseID, func(current []*model.ExerciseOption) ([]*model.ExerciseOption, error) {
+		if !sameIDSet(ids, current, func(o *model.ExerciseOption) uuid.UUID { return o.ID }) {
+			return nil, fmt.Errorf("option list must contain exactly the current options")
+		}
+
+		byID := make(map[uuid.UUID]*model.ExerciseOption, len(current))
+		for _, o := range current {
+			byID[o.ID] = o
+		}
+		now := time.Now().UTC()
+		ordered := make([]*model.ExerciseOption, 0, len(ids))
+		for i, id := range ids {
+			o := byID[id]
+			if o.OrderIndex != i {
+				o.UpdatedAt = now
+			}
+			ordered = append(ordered, o)
+		}
+		return ordered, nil
+	})
+}
+
+// ReorderExercises rewrites the order of a lesson's exercises. The ID list
+// must contain the lesson's current exercises and may add exercises from
+// other lessons of the same course, which are moved into this lesson.
+func (s *ExerciseService) ReorderExercises(
+	ctx context.Context,
+	lessonID uuid.UUID,
+	ids []uuid.UUID,
+) ([]*model.Exercise, error) {
+	lesson, err := s.lessonRepo.GetByID(ctx, lessonID, false)
+	if err != nil {
+		return nil, fmt.Errorf("lesson not found: %w", err)
+	}
+	skill, err := s.skillRepo.GetByID(ctx, lesson.SkillID, false)
+	if err != nil {
+		return nil, fmt.Errorf("skill not found: %w", err)
+	}
+
+	exercises, err := s.repo.ListByCourseID(ctx, skill.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	lessons, err := s.lessonRepo.ListByCourseID(ctx, skill.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	skillOf := make(map[uuid.UUID]uuid.UUID, len(lessons))
+	for _, l := range lessons {
+		skillOf[l.ID] = l.SkillID
+	}
+
+	order := childOrder[*model.Exercise]{
+		entity: model.EntityExercise,
+		id:     func(e *model.Exercise) uuid.UUID { return e.ID },
+		parent: func(e *model.Exercise) uuid.UUID { return e.LessonID },
+		index:  func(e *model.Exercise) int { return e.OrderIndex },
+		move: func(e *model.Exercise, lessonID uuid.UUID, index int, at time.Time) {
+			e.LessonID, e.SkillID, e.OrderIndex, e.UpdatedAt = lessonID, skillOf[lessonID], index, at
+		},
+		version:   func(e *model.Exercise) int { return e.Version },
+		updatedAt: func(e *model.Exercise) time.Time { return e.UpdatedAt },
+		snapshot: func(ctx context.Context, e *model.Exercise) (any, error) {
+			return revisionSnapshot(ctx, s.optionRepo, e)
+		},
+		save: s.repo.UpdateOrder,
+	}
+	return order.reorder(ctx, s.tx, s.revisions, lessonID, ids, exercises, true)
+}
+
+// GradeResponse scores a learner response against the stored answer key
+// without recording an ExerciseAnswer. Authors use it to test their keys.
+func (s *ExerciseService) GradeResponse(
+	ctx context.Context,
+	exerciseID uuid.UUID,
+	response model.JSONB,
+) (*grading.Result, error) {
+	exercise, err := s.repo.GetByID(ctx, exerciseID, false)
+	if err != nil {
+		return nil, fmt.Errorf("exercise not found: %w", err)
+	}
+
+	options, err := s.optionRepo.ListByExerciseID(ctx, exerciseID)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]model.ExerciseOption, 0, len(options))
+	for _, o := range options {
+		values = append(values, *o)
+	}
+
+	return grading.Grade(exercise, values, response)
+}
+
+// sameIDSet reports whether ids lists every item exactly once.
+func sameIDSet[T any](ids []uuid.UUID, items []T, idOf func(T) uuid.UUID) bool {
+	if len(ids) != len(items) {
+		return false
+	}
+
+	want := make(map[uuid.UUID]bool, len(items))
+	for _, item := range items {
+		want[idOf(item)] = true
+	}
+	for _, id := range ids {
+		if !want[id] {
+			return false
+		}
+		delete(want, id)
+	}
+	return len(want) == 0
+}
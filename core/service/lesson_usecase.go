@@ -2,23 +2,35 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/bytebeatz/bandroom-cms/core/model"
 	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/bytebeatz/bandroom-cms/core/validation"
+	"github.com/bytebeatz/bandroom-cms/core/workflow"
 	"github.com/bytebeatz/bandroom-cms/utils"
 	"github.com/google/uuid"
 )
 
 // LessonService handles business logic for lessons.
 type LessonService struct {
-	repo repository.LessonRepository
+	repo      repository.LessonRepository
+	skills    repository.SkillRepository
+	revisions repository.RevisionRepository
+	tx        repository.Transactor
 }
 
-func NewLessonService(repo repository.LessonRepository) *LessonService {
-	return &LessonService{repo: repo}
+func NewLessonService(
+	repo repository.LessonRepository,
+	skills repository.SkillRepository,
+	revisions repository.RevisionRepository,
+	tx repository.Transactor,
+) *LessonService {
+	return &LessonService{repo: repo, skills: skills, revisions: revisions, tx: tx}
 }
 
 func (s *LessonService) CreateLesson(
@@ -44,42 +56,159 @@ func (s *LessonService) CreateLesson(
 		lesson.Slug = utils.GenerateSlug(lesson.Title)
 	}
 
-	if lesson.Version == 0 {
-		lesson.Version = 1
-	}
+	lesson.Version = 1
+	lesson.Status = model.StateDraft
 
-	return s.repo.Create(ctx, lesson)
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, lesson); err != nil {
+			return err
+		}
+		return recordRevision(ctx, s.revisions, model.EntityLesson, lesson.ID, lesson.Version, lesson,
+			currentUserID(ctx), lesson.CreatedAt)
+	})
 }
 
 func (s *LessonService) UpdateLesson(ctx context.Context, updated *model.Lesson) error {
-	existing, err := s.repo.GetByID(ctx, updated.ID)
+	existing, err := s.repo.GetByID(ctx, updated.ID, false)
 	if err != nil {
 		return fmt.Errorf("lesson not found: %w", err)
 	}
 
+	// Version holds the version the client last read; reject stale writes
+	if updated.Version != existing.Version {
+		return repository.ErrVersionConflict
+	}
+
+	// Fields the update does not write keep their stored values, so the
+	// response and revision show the row as saved; lessons are moved through
+	// ReorderLessons
+	updated.SkillID = existing.SkillID
+	updated.CreatorID = existing.CreatorID
 	updated.CreatedAt = existing.CreatedAt
+	// Workflow state only changes through transitions, except that an edit
+	// sends approved content back to draft
+	if updated.Status, err = workflow.AfterEdit(existing.Status); err != nil {
+		return err
+	}
 	updated.UpdatedAt = time.Now().UTC()
-	updated.Version = existing.Version + 1
 
 	if strings.TrimSpace(updated.Slug) == "" {
 		updated.Slug = utils.GenerateSlug(updated.Title)
 	}
 
-	return s.repo.Update(ctx, updated)
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		// Keep the version being replaced, in case it predates revision history
+		if err := recordRevision(ctx, s.revisions, model.EntityLesson, existing.ID, existing.Version,
+			existing, nil, existing.UpdatedAt); err != nil {
+			return err
+		}
+
+		if err := s.repo.Update(ctx, updated); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("lesson not found: %w", err)
+			}
+			return err
+		}
+		return recordRevision(ctx, s.revisions, model.EntityLesson, updated.ID, updated.Version, updated,
+			currentUserID(ctx), updated.UpdatedAt)
+	})
 }
 
+// DeleteLesson soft-deletes a lesson together with its exercises.
 func (s *LessonService) DeleteLesson(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("lesson not found: %w", err)
+		}
+		return err
+	}
+	return nil
 }
 
-func (s *LessonService) GetLessonByID(ctx context.Context, id uuid.UUID) (*model.Lesson, error) {
-	return s.repo.GetByID(ctx, id)
+// RestoreLesson brings back a soft-deleted lesson and the exercises deleted with it.
+func (s *LessonService) RestoreLesson(ctx context.Context, id uuid.UUID) (*model.Lesson, error) {
+	if err := s.repo.Restore(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("lesson not found: %w", err)
+		}
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id, false)
+}
+
+func (s *LessonService) GetLessonByID(
+	ctx context.Context,
+	id uuid.UUID,
+	includeDeleted bool,
+) (*model.Lesson, error) {
+	return s.repo.GetByID(ctx, id, includeDeleted)
 }
 
 func (s *LessonService) ListLessonsBySkillID(
 	ctx context.Context,
 	skillID uuid.UUID,
+	includeDeleted bool,
 ) ([]*model.Lesson, error) {
-	return s.repo.ListBySkillID(ctx, skillID)
+	return s.repo.ListBySkillID(ctx, skillID, includeDeleted)
+}
+
+// ListLessons returns one page of lessons matching q.
+func (s *LessonService) ListLessons(
+	ctx context.Context,
+	q repository.ListQuery,
+) (*repository.Page[*model.Lesson], error) {
+	return s.repo.List(ctx, q)
 }
 
+// ReorderLessons rewrites the order of a skill's lessons. The ID list must
+// contain the skill's current lessons and may add lessons from other skills
+// of the same course, which are moved into this skill.
+func (s *LessonService) ReorderLessons(
+	ctx context.Context,
+	skillID uuid.UUID,
+	ids []uuid.UUID,
+) ([]*model.Lesson, error) {
+	skill, err := s.skills.GetByID(ctx, skillID, false)
+	if err != nil {
+		return nil, fmt.Errorf("skill not found: %w", err)
+	}
+
+	lessons, err := s.repo.ListByCourseID(ctx, skill.CourseID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lesson titles are unique within a skill, so a lesson cannot move in
+	// next to one with the same title
+	var errs validation.Errors
+	for i, id := range ids {
+		for _, moved := range lessons {
+			if moved.ID != id || moved.SkillID == skillID {
+				continue
+			}
+			for _, l := range lessons {
+				if l.SkillID == skillID && strings.EqualFold(l.Title, moved.Title) {
+					errs.Add(fmt.Sprintf("ids[%d]", i),
+						"a lesson titled '%s' already exists in this skill", moved.Title)
+				}
+			}
+		}
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	order := childOrder[*model.Lesson]{
+		entity: model.EntityLesson,
+		id:     func(l *model.Lesson) uuid.UUID { return l.ID },
+		parent: func(l *model.Lesson) uuid.UUID { return l.SkillID },
+		index:  func(l *model.Lesson) int { return l.OrderIndex },
+		move: func(l *model.Lesson, skillID uuid.UUID, index int, at time.Time) {
+			l.SkillID, l.OrderIndex, l.UpdatedAt = skillID, index, at
+		},
+		version:   func(l *model.Lesson) int { return l.Version },
+		updatedAt: func(l *model.Lesson) time.Time { return l.UpdatedAt },
+		save:      s.repo.UpdateOrder,
+	}
+	return order.reorder(ctx, s.tx, s.revisions, skillID, ids, lessons, true)
+}
@@ -0,0 +1,414 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/audio"
+	"github.com/bytebeatz/bandroom-cms/core/imaging"
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/bytebeatz/bandroom-cms/core/validation"
+	"github.com/bytebeatz/bandroom-cms/storage"
+	"github.com/google/uuid"
+)
+
+// MaxMediaSize caps the size of one uploaded media file.
+const MaxMediaSize = 50 << 20
+
+// MediaService stores uploaded audio and image files and keeps a record of
+// each in media_assets.
+type MediaService struct {
+	repo  repository.MediaRepository
+	store storage.BlobStore
+	// urlExpiry is how long the URLs made by SignURL stay valid
+	urlExpiry time.Duration
+}
+
+func NewMediaService(
+	repo repository.MediaRepository,
+	store storage.BlobStore,
+	urlExpiry time.Duration,
+) *MediaService {
+	return &MediaService{repo: repo, store: store, urlExpiry: urlExpiry}
+}
+
+// UploadMedia stores an uploaded file under a new asset ID and records it
+// with its checksum and, for WAV, MP3 and Ogg Vorbis audio, an analysis of
+// its length, loudness and waveform. The content must match the file
+// extension, which alone decides the content type. JPEG, PNG, GIF and WebP images are
+// stored without EXIF and other metadata, see imaging.Strip, and get resized
+// variants stored next to the original.
+func (s *MediaService) UploadMedia(
+	ctx context.Context,
+	file multipart.File,
+	header *multipart.FileHeader,
+) (*model.MediaAsset, error) {
+	var errs validation.Errors
+	ext := strings.ToLower(path.Ext(header.Filename))
+	kind := validation.MediaKindOf(header.Filename)
+	if kind == "" {
+		errs.Add("file", "must be an audio or image file, got %q", header.Filename)
+	}
+	if header.Size > MaxMediaSize {
+		errs.Add("file", "must be at most %d MB", MaxMediaSize>>20)
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return nil, fmt.Errorf("reading upload: %w", err)
+	}
+
+	// The first 512 bytes are all http.DetectContentType looks at
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("reading upload: %w", err)
+	}
+	ct, ok := contentType(head[:n], ext)
+	if !ok {
+		errs.Add("file", "content is not a %s file", strings.TrimPrefix(ext, "."))
+		return nil, errs
+	}
+
+	asset := &model.MediaAsset{
+		ID:          uuid.New(),
+		FileName:    header.Filename,
+		ContentType: ct,
+		Size:        size,
+		Checksum:    "sha256:" + hex.EncodeToString(hash.Sum(nil)),
+		UploadedBy:  currentUserID(ctx),
+		CreatedAt:   time.Now().UTC(),
+	}
+	// The asset ID in the path ties media URLs back to their record
+	asset.ObjectPath = "media/" + asset.ID.String() + ext
+
+	if kind == validation.MediaAudio {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		analysis, err := audio.Analyze(file, ext)
+		switch {
+		case err == nil:
+			ms := int(analysis.Duration.Milliseconds())
+			asset.DurationMS = &ms
+			asset.Audio = &model.AudioAnalysis{
+				SampleRate:    analysis.SampleRate,
+				Channels:      analysis.Channels,
+				PeakDB:        analysis.PeakDB,
+				RMSDB:         analysis.RMSDB,
+				Waveform:      analysis.Envelope,
+				LoudnessFlags: analysis.Flags(),
+			}
+		case !errors.Is(err, audio.ErrUnsupported):
+			errs.Add("file", "could not be read as %s audio: %v", strings.TrimPrefix(ext, "."), err)
+			return nil, errs
+		}
+	}
+
+	var images *imaging.Result
+	var content io.Reader = file
+	if kind == validation.MediaImage {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		images, err = imaging.Process(file, ext)
+		if err == nil {
+			if _, err = file.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+			var stripped []byte
+			if stripped, err = imaging.Strip(file, ext); err == nil {
+				sum := sha256.Sum256(stripped)
+				asset.Size = int64(len(stripped))
+				asset.Checksum = "sha256:" + hex.EncodeToString(sum[:])
+				content = bytes.NewReader(stripped)
+			}
+		}
+		switch {
+		case err == nil:
+			asset.Width, asset.Height = &images.Width, &images.Height
+		case errors.Is(err, imaging.ErrUnsupported):
+			images = nil
+		default:
+			errs.Add("file", "could not be read as %s image: %v", strings.TrimPrefix(ext, "."), err)
+			return nil, errs
+		}
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	err = s.store.Upload(ctx, asset.ObjectPath, content, storage.UploadOptions{
+		ContentType: asset.ContentType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	asset.URL = s.store.URL(asset.ObjectPath)
+
+	if images != nil {
+		for _, v := range images.Variants {
+			variant := model.MediaVariant{
+				Name:       v.Size,
+				Format:     v.Format,
+				Width:      v.Width,
+				Height:     v.Height,
+				Size:       int64(len(v.Data)),
+				ObjectPath: "media/" + asset.ID.String() + "/" + v.Size + v.Ext(),
+			}
+			err := s.store.Upload(ctx, variant.ObjectPath, bytes.NewReader(v.Data), storage.UploadOptions{
+				ContentType: v.ContentType(),
+			})
+			if err != nil {
+				s.removeFiles(ctx, asset)
+				return nil, err
+			}
+			variant.URL = s.store.URL(variant.ObjectPath)
+			asset.Variants = append(asset.Variants, variant)
+		}
+	}
+
+	if err := s.repo.Create(ctx, asset); err != nil {
+		// Do not leave unrecorded files behind
+		s.removeFiles(ctx, asset)
+		return nil, err
+	}
+	return asset, nil
+}
+
+// removeFiles deletes the stored files of an asset. A file left behind is
+// only wasted space, so failures are logged.
+func (s *MediaService) removeFiles(ctx context.Context, asset *model.MediaAsset) {
+	paths := []string{asset.ObjectPath}
+	for _, v := range asset.Variants {
+		paths = append(paths, v.ObjectPath)
+	}
+	for _, p := range paths {
+		if err := s.store.Delete(ctx, p); err != nil {
+			log.Println("Failed to delete media file:", err)
+		}
+	}
+}
+
+// GetMedia returns the record of a media asset.
+func (s *MediaService) GetMedia(ctx context.Context, id uuid.UUID) (*model.MediaAsset, error) {
+	asset, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("media not found: %w", err)
+		}
+		return nil, err
+	}
+	return asset, nil
+}
+
+// OpenMedia returns a media asset with a stream of its content, which the
+// caller must close.
+func (s *MediaService) OpenMedia(
+	ctx context.Context,
+	id uuid.UUID,
+) (*model.MediaAsset, io.ReadCloser, error) {
+	asset, err := s.GetMedia(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := s.store.Download(ctx, asset.ObjectPath)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, fmt.Errorf("media file not found: %w", err)
+		}
+		return nil, nil, err
+	}
+	return asset, content, nil
+}
+
+// DeleteMedia removes a media asset and its files. It fails with
+// repository.ErrMediaInUse while an exercise or option, even a deleted one,
+// or a release uses the asset.
+func (s *MediaService) DeleteMedia(ctx context.Context, id uuid.UUID) error {
+	asset, err := s.GetMedia(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("media not found: %w", err)
+		}
+		return err
+	}
+
+	s.removeFiles(ctx, asset)
+	return nil
+}
+
+// SignURL returns a signed URL for reading the stored file behind a media
+// URL, with the time it expires. URLs outside storage come back unchanged
+// with a nil expiry.
+func (s *MediaService) SignURL(ctx context.Context, url string) (string, *time.Time, error) {
+	path, ok := s.store.ObjectPath(url)
+	if !ok {
+		return url, nil, nil
+	}
+	expires := time.Now().Add(s.urlExpiry).UTC().Truncate(time.Second)
+	signed, err := s.store.SignedURL(ctx, path, s.urlExpiry)
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, &expires, nil
+}
+
+// ResolvedMedia is how a media URL is presented to clients.
+type ResolvedMedia struct {
+	URL string
+	// ExpiresAt is set when URL is signed
+	ExpiresAt *time.Time
+	// Srcset lists an image's variants by format ("png" or "jpeg") as an
+	// HTML srcset: "<url> 256w, <url> 1080w, ...".
+	Srcset map[string]string
+}
+
+// ResolveURLs looks up the media behind URLs, returning srcsets for images
+// with variants and, if sign is set, signed URLs for stored files. URLs
+// outside storage come back unchanged.
+func (s *MediaService) ResolveURLs(
+	ctx context.Context,
+	urls []string,
+	sign bool,
+) (map[string]ResolvedMedia, error) {
+	if len(urls) == 0 {
+		return nil, nil
+	}
+	assets, err := s.repo.GetByURLs(ctx, urls)
+	if err != nil {
+		return nil, err
+	}
+	byURL := make(map[string]*model.MediaAsset, len(assets))
+	for _, a := range assets {
+		byURL[a.URL] = a
+	}
+
+	link := func(url string) (string, *time.Time, error) {
+		if !sign {
+			return url, nil, nil
+		}
+		return s.SignURL(ctx, url)
+	}
+	resolved := make(map[string]ResolvedMedia, len(urls))
+	for _, url := range urls {
+		if _, done := resolved[url]; done {
+			continue
+		}
+		var r ResolvedMedia
+		if r.URL, r.ExpiresAt, err = link(url); err != nil {
+			return nil, err
+		}
+		if a := byURL[url]; a != nil && len(a.Variants) > 0 {
+			r.Srcset = make(map[string]string)
+			// Small images are not scaled up, so sizes can share a width
+			widest := make(map[string]int)
+			for _, v := range a.Variants {
+				if v.Width <= widest[v.Format] {
+					continue
+				}
+				widest[v.Format] = v.Width
+				u, _, err := link(v.URL)
+				if err != nil {
+					return nil, err
+				}
+				if r.Srcset[v.Format] != "" {
+					r.Srcset[v.Format] += ", "
+				}
+				r.Srcset[v.Format] += fmt.Sprintf("%s %dw", u, v.Width)
+			}
+		}
+		resolved[url] = r
+	}
+	return resolved, nil
+}
+
+// OpenFile returns a stored file with a stream of its content, for stores
+// whose files the CMS serves itself. expires and signature come from a URL
+// made by SignURL. The caller must close the stream.
+func (s *MediaService) OpenFile(
+	ctx context.Context,
+	path, expires, signature string,
+) (*storage.Object, io.ReadCloser, error) {
+	verifier, ok := s.store.(storage.FileVerifier)
+	if !ok {
+		return nil, nil, errors.New("file not found: storage serves its own files")
+	}
+	if err := verifier.VerifySignature(path, expires, signature); err != nil {
+		return nil, nil, err
+	}
+	obj, err := s.store.Stat(ctx, path)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidPath) {
+			return nil, nil, fmt.Errorf("file not found: %w", err)
+		}
+		return nil, nil, err
+	}
+	content, err := s.store.Download(ctx, path)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, fmt.Errorf("file not found: %w", err)
+		}
+		return nil, nil, err
+	}
+	return obj, content, nil
+}
+
+// mediaTypes maps the extensions of media files to the content type they
+// are stored and served with and the types http.DetectContentType may find
+// in them. Formats it cannot recognize sniff as application/octet-stream.
+var mediaTypes = map[string]struct {
+	contentType string
+	sniffed     []string
+}{
+	".mp3":  {"audio/mpeg", []string{"audio/mpeg", "application/octet-stream"}},
+	".wav":  {"audio/wav", []string{"audio/wave"}},
+	".ogg":  {"audio/ogg", []string{"application/ogg"}},
+	".oga":  {"audio/ogg", []string{"application/ogg"}},
+	".m4a":  {"audio/mp4", []string{"video/mp4", "application/octet-stream"}},
+	".aac":  {"audio/aac", []string{"application/octet-stream"}},
+	".flac": {"audio/flac", []string{"application/octet-stream"}},
+	".png":  {"image/png", []string{"image/png"}},
+	".jpg":  {"image/jpeg", []string{"image/jpeg"}},
+	".jpeg": {"image/jpeg", []string{"image/jpeg"}},
+	".gif":  {"image/gif", []string{"image/gif"}},
+	".webp": {"image/webp", []string{"image/webp"}},
+	".svg":  {"image/svg+xml", []string{"text/xml", "text/plain"}},
+}
+
+// contentType returns the content type for a file with the given extension
+// whose content starts with head, and false if the content does not look
+// like that kind of file. The type the client sent is never used, so an
+// upload cannot make the API serve HTML or script.
+func contentType(head []byte, ext string) (string, bool) {
+	t, ok := mediaTypes[ext]
+	if !ok {
+		return "", false
+	}
+	sniffed, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	return t.contentType, slices.Contains(t.sniffed, sniffed)
+}
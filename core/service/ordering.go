@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/bytebeatz/bandroom-cms/core/validation"
+	"github.com/google/uuid"
+)
+
+// childOrder describes how units, skills, lessons and exercises are
+// positioned beneath their parent, so they can share one reordering routine.
+type childOrder[T any] struct {
+	entity model.EntityType
+	id     func(T) uuid.UUID
+	parent func(T) uuid.UUID
+	index  func(T) int
+	// move puts the item at position index under parent, updated at the given time.
+	move func(item T, parent uuid.UUID, index int, at time.Time)
+	// version and updatedAt feed revision history, and snapshot, when set,
+	// returns what a revision records instead of the item itself.
+	version   func(T) int
+	updatedAt func(T) time.Time
+	snapshot  func(ctx context.Context, item T) (any, error)
+	// save persists the new positions, see e.g. UnitRepository.UpdateOrder.
+	save func(ctx context.Context, items []T) error
+}
+
+// reorder makes ids the order of parentID's children and returns them in that
+// order. ids must hold every current child exactly once; when allowMove is
+// set it may also hold items of the course that live under another parent,
+// which are moved to parentID, and the siblings they leave behind are closed
+// up. courseItems holds every live item of the course.
+//
+// Only items whose position changes are written, in one transaction, each
+// bumping its version and getting a revision like an ordinary update.
+func (o childOrder[T]) reorder(
+	ctx context.Context,
+	tx repository.Transactor,
+	revisions repository.RevisionRepository,
+	parentID uuid.UUID,
+	ids []uuid.UUID,
+	courseItems []T,
+	allowMove bool,
+) ([]T, error) {
+	byID := make(map[uuid.UUID]T, len(courseItems))
+	for _, item := range courseItems {
+		byID[o.id(item)] = item
+	}
+
+	var errs validation.Errors
+	ordered := make([]T, 0, len(ids))
+	listed := make(map[uuid.UUID]bool, len(ids))
+	for i, id := range ids {
+		field := fmt.Sprintf("ids[%d]", i)
+		item, ok := byID[id]
+		switch {
+		case listed[id]:
+			errs.Add(field, "%s %s is listed more than once", o.entity, id)
+		case !ok:
+			errs.Add(field, "%s %s is not part of this course", o.entity, id)
+		case o.parent(item) != parentID && !allowMove:
+			errs.Add(field, "%s %s belongs to another parent", o.entity, id)
+		default:
+			ordered = append(ordered, item)
+		}
+		listed[id] = true
+	}
+	for _, item := range courseItems {
+		if o.parent(item) == parentID && !listed[o.id(item)] {
+			errs.Add("ids", "%s %s is missing from the list", o.entity, o.id(item))
+		}
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	// Target positions: the new list, then the closed-up siblings of moved items
+	type position struct {
+		parent uuid.UUID
+		index  int
+	}
+	target := make(map[uuid.UUID]position, len(ordered))
+	left := map[uuid.UUID]bool{}
+	for i, item := range ordered {
+		target[o.id(item)] = position{parentID, i}
+		if o.parent(item) != parentID {
+			left[o.parent(item)] = true
+		}
+	}
+	next := map[uuid.UUID]int{}
+	for _, item := range courseItems {
+		if p := o.parent(item); left[p] && !listed[o.id(item)] {
+			target[o.id(item)] = position{p, next[p]}
+			next[p]++
+		}
+	}
+
+	var changed []T
+	for _, item := range courseItems {
+		pos, ok := target[o.id(item)]
+		if ok && (pos.parent != o.parent(item) || pos.index != o.index(item)) {
+			changed = append(changed, item)
+		}
+	}
+	if len(changed) == 0 {
+		return ordered, nil
+	}
+
+	err := tx.InTx(ctx, func(ctx context.Context) error {
+		// Keep the versions being replaced, in case they predate revision history
+		for _, item := range changed {
+			if err := o.record(ctx, revisions, item, nil, o.updatedAt(item)); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now().UTC()
+		for _, item := range changed {
+			pos := target[o.id(item)]
+			o.move(item, pos.parent, pos.index, now)
+		}
+		if err := o.save(ctx, changed); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%s not found: %w", o.entity, err)
+			}
+			return err
+		}
+
+		for _, item := range changed {
+			if err := o.record(ctx, revisions, item, currentUserID(ctx), now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ordered, nil
+}
+
+// record stores a revision of item at its current version.
+func (o childOrder[T]) record(
+	ctx context.Context,
+	revisions repository.RevisionRepository,
+	item T,
+	author *uuid.UUID,
+	at time.Time,
+) error {
+	var snapshot any = item
+	if o.snapshot != nil {
+		var err error
+		if snapshot, err = o.snapshot(ctx, item); err != nil {
+			return err
+		}
+	}
+	return recordRevision(ctx, revisions, o.entity, o.id(item), o.version(item), snapshot, author, at)
+}
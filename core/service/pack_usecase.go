@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/pack"
+	"github.com/bytebeatz/bandroom-cms/core/validation"
+)
+
+// PackService builds offline packs of course releases.
+type PackService struct {
+	releases *ReleaseService
+	media    pack.Media
+}
+
+func NewPackService(releases *ReleaseService, media pack.Media) *PackService {
+	return &PackService{releases: releases, media: media}
+}
+
+// GetPackReleases resolves the release a pack is built from (see
+// ReleaseService.ResolveRelease) and, for a delta pack, the earlier release
+// sinceVersion it builds on; sinceVersion 0 means a full pack.
+func (s *PackService) GetPackReleases(
+	ctx context.Context,
+	courseRef string,
+	ref string,
+	sinceVersion int,
+) (rel, base *model.Release, err error) {
+	rel, err = s.releases.ResolveRelease(ctx, courseRef, ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sinceVersion == 0 {
+		return rel, nil, nil
+	}
+
+	if sinceVersion >= rel.Version {
+		var errs validation.Errors
+		errs.Add("since", "must be a version before %d", rel.Version)
+		return nil, nil, errs
+	}
+	base, err = s.releases.GetRelease(ctx, rel.CourseID.String(), sinceVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rel, base, nil
+}
+
+// WritePack streams the pack of rel to w, as a delta on base when base is set.
+func (s *PackService) WritePack(
+	ctx context.Context,
+	w io.Writer,
+	rel, base *model.Release,
+) error {
+	if err := pack.Write(ctx, w, rel, base, s.media); err != nil {
+		return fmt.Errorf("writing pack of release %d: %w", rel.Version, err)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/portable"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/google/uuid"
+)
+
+// PortableService exports courses as slug-based documents and imports them
+// back, creating or updating the course the document names.
+type PortableService struct {
+	imports   repository.ImportRepository
+	courses   repository.CourseRepository
+	options   repository.ExerciseOptionRepository
+	revisions repository.RevisionRepository
+	tree      *TreeService
+	tx        repository.Transactor
+}
+
+func NewPortableService(
+	imports repository.ImportRepository,
+	courses repository.CourseRepository,
+	options repository.ExerciseOptionRepository,
+	revisions repository.RevisionRepository,
+	tree *TreeService,
+	tx repository.Transactor,
+) *PortableService {
+	return &PortableService{
+		imports:   imports,
+		courses:   courses,
+		options:   options,
+		revisions: revisions,
+		tree:      tree,
+		tx:        tx,
+	}
+}
+
+// Export returns the document of a course with all of its live content.
+func (s *PortableService) Export(ctx context.Context, courseID uuid.UUID) (*portable.Document, error) {
+	tree, options, err := s.load(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	return portable.Export(tree, options)
+}
+
+// Import makes the course with the document's slug match the document,
+// creating it if there is none, and returns the plan of what changed. With
+// dryRun nothing is written.
+func (s *PortableService) Import(
+	ctx context.Context,
+	doc *portable.Document,
+	dryRun bool,
+) (*model.ImportPlan, error) {
+	userID := currentUserID(ctx)
+	if userID == nil {
+		return nil, errors.New("importing requires an authenticated user")
+	}
+
+	var tree *model.CourseTree
+	var options []*model.ExerciseOption
+	course, err := s.courses.GetBySlug(ctx, doc.Slug)
+	switch {
+	case err == nil:
+		if tree, options, err = s.load(ctx, course.ID); err != nil {
+			return nil, err
+		}
+	case errors.Is(err, sql.ErrNoRows):
+		exists, err := s.courses.ExistsByTitle(ctx, doc.Title)
+		if err != nil {
+			return nil, fmt.Errorf("error checking for duplicate title: %w", err)
+		}
+		if exists {
+			return nil, fmt.Errorf("course with title '%s' already exists", doc.Title)
+		}
+	default:
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	plan, err := portable.Plan(doc, tree, options, *userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun || len(plan.Changes) == 0 {
+		return plan, nil
+	}
+
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		// Keep the versions being replaced, in case they predate revision history
+		written := plannedRevisions(plan)
+		replaced := replacedRevisions(tree)
+		for _, r := range written {
+			if old, ok := replaced[r.id]; ok {
+				snapshot, err := revisionSnapshot(ctx, s.options, old.entity)
+				if err != nil {
+					return err
+				}
+				if err := recordRevision(ctx, s.revisions, r.entityType, r.id, *r.version,
+					snapshot, nil, old.updatedAt); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := s.imports.Apply(ctx, plan); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("content not found, it may have been deleted meanwhile: %w", err)
+			}
+			return err
+		}
+
+		for _, r := range written {
+			snapshot, err := revisionSnapshot(ctx, s.options, r.entity)
+			if err != nil {
+				return err
+			}
+			if err := recordRevision(ctx, s.revisions, r.entityType, r.id, *r.version,
+				snapshot, userID, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// load returns a course's full tree and the options of its exercises.
+func (s *PortableService) load(
+	ctx context.Context,
+	courseID uuid.UUID,
+) (*model.CourseTree, []*model.ExerciseOption, error) {
+	tree, err := s.tree.GetCourseTree(ctx, courseID, DepthExercises)
+	if err != nil {
+		return nil, nil, err
+	}
+	options, err := s.options.ListByCourseID(ctx, courseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tree, options, nil
+}
+
+// plannedRevision is an entity an import writes. version points at the
+// entity's version, so it reads the bumped version after Apply.
+type plannedRevision struct {
+	entityType model.EntityType
+	id         uuid.UUID
+	version    *int
+	entity     any
+}
+
+func plannedRevisions(plan *model.ImportPlan) []plannedRevision {
+	var out []plannedRevision
+	if c := plan.Course; c != nil {
+		out = append(out, plannedRevision{model.EntityCourse, c.ID, &c.Version, c})
+	}
+	for _, u := range plan.Units {
+		out = append(out, plannedRevision{model.EntityUnit, u.ID, &u.Version, u})
+	}
+	for _, sk := range plan.Skills {
+		out = append(out, plannedRevision{model.EntitySkill, sk.ID, &sk.Version, sk})
+	}
+	for _, l := range plan.Lessons {
+		out = append(out, plannedRevision{model.EntityLesson, l.ID, &l.Version, l})
+	}
+	for _, e := range plan.Exercises {
+		out = append(out, plannedRevision{model.EntityExercise, e.ID, &e.Version, e})
+	}
+	return out
+}
+
+type replacedRevision struct {
+	entity    any
+	updatedAt time.Time
+}
+
+// replacedRevisions indexes the stored version of everything in tree, which
+// may be nil.
+func replacedRevisions(tree *model.CourseTree) map[uuid.UUID]replacedRevision {
+	out := map[uuid.UUID]replacedRevision{}
+	if tree == nil {
+		return out
+	}
+	out[tree.Course.ID] = replacedRevision{tree.Course, tree.Course.UpdatedAt}
+	for _, un := range tree.Units {
+		out[un.Unit.ID] = replacedRevision{un.Unit, un.Unit.UpdatedAt}
+		for _, sn := range un.Skills {
+			out[sn.Skill.ID] = replacedRevision{sn.Skill, sn.Skill.UpdatedAt}
+			for _, ln := range sn.Lessons {
+				out[ln.Lesson.ID] = replacedRevision{ln.Lesson, ln.Lesson.UpdatedAt}
+				for _, e := range ln.Exercises {
+					out[e.ID] = replacedRevision{e, e.UpdatedAt}
+				}
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/release"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/bytebeatz/bandroom-cms/core/validation"
+	"github.com/google/uuid"
+)
+
+// ReleaseService cuts immutable releases of published courses for learner
+// apps and moves release channels between them.
+type ReleaseService struct {
+	releases repository.ReleaseRepository
+	courses  repository.CourseRepository
+	options  repository.ExerciseOptionRepository
+	tree     *TreeService
+}
+
+func NewReleaseService(
+	releases repository.ReleaseRepository,
+	courses repository.CourseRepository,
+	options repository.ExerciseOptionRepository,
+	tree *TreeService,
+) *ReleaseService {
+	return &ReleaseService{
+		releases: releases,
+		courses:  courses,
+		options:  options,
+		tree:     tree,
+	}
+}
+
+// CreateRelease freezes the published content of a course, given by ID or
+// slug, as its next release. It fails with release.ErrNotPublished if the
+// course is not published and release.ErrUnchanged if the content equals
+// the latest release.
+func (s *ReleaseService) CreateRelease(
+	ctx context.Context,
+	courseRef string,
+	notes string,
+) (*model.Release, error) {
+	course, err := s.resolveCourse(ctx, courseRef)
+	if err != nil {
+		return nil, err
+	}
+	if course.Status != model.StatePublished {
+		return nil, fmt.Errorf("%w: course %s is %s", release.ErrNotPublished, course.ID, course.Status)
+	}
+
+	tree, err := s.tree.GetCourseTree(ctx, course.ID, DepthExercises)
+	if err != nil {
+		return nil, err
+	}
+	options, err := s.options.ListByCourseID(ctx, course.ID)
+	if err != nil {
+		return nil, err
+	}
+	content := release.Freeze(tree, options)
+	hash, err := release.Hash(content)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.releases.ListByCourse(ctx, course.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 && existing[0].ContentHash == hash {
+		return nil, fmt.Errorf("%w (version %d)", release.ErrUnchanged, existing[0].Version)
+	}
+
+	rel := &model.Release{
+		ID:          uuid.New(),
+		CourseID:    course.ID,
+		ContentHash: hash,
+		Content:     content,
+		Notes:       notes,
+		Channels:    []model.ReleaseChannel{},
+		CreatedBy:   currentUserID(ctx),
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := s.releases.Create(ctx, rel); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// ListReleases returns a course's releases, newest first, without content.
+func (s *ReleaseService) ListReleases(
+	ctx context.Context,
+	courseRef string,
+) ([]*model.Release, error) {
+	course, err := s.resolveCourse(ctx, courseRef)
+	if err != nil {
+		return nil, err
+	}
+	return s.releases.ListByCourse(ctx, course.ID)
+}
+
+// GetRelease returns one release of a course with its content, checked
+// against its hash.
+func (s *ReleaseService) GetRelease(
+	ctx context.Context,
+	courseRef string,
+	version int,
+) (*model.Release, error) {
+	course, err := s.resolveCourse(ctx, courseRef)
+	if err != nil {
+		return nil, err
+	}
+	return s.verified(s.releases.GetByVersion(ctx, course.ID, version))
+}
+
+// GetChannelRelease returns the release a channel of the course points at.
+func (s *ReleaseService) GetChannelRelease(
+	ctx context.Context,
+	courseRef string,
+	channel model.ReleaseChannel,
+) (*model.Release, error) {
+	if err := checkChannel(channel); err != nil {
+		return nil, err
+	}
+	course, err := s.resolveCourse(ctx, courseRef)
+	if err != nil {
+		return nil, err
+	}
+	return s.verified(s.releases.GetByChannel(ctx, course.ID, channel))
+}
+
+// ResolveRelease returns the release ref names: a version number, a channel
+// (beta, stable), or the latest release when ref is empty.
+func (s *ReleaseService) ResolveRelease(
+	ctx context.Context,
+	courseRef string,
+	ref string,
+) (*model.Release, error) {
+	if ref == "" {
+		releases, err := s.ListReleases(ctx, courseRef)
+		if err != nil {
+			return nil, err
+		}
+		if len(releases) == 0 {
+			return nil, errors.New("release not found: course has no releases")
+		}
+		ref = strconv.Itoa(releases[0].Version)
+	}
+
+	if version, err := strconv.Atoi(ref); err == nil {
+		if version < 1 {
+			var errs validation.Errors
+			errs.Add("version", "must be a positive number or a channel")
+			return nil, errs
+		}
+		return s.GetRelease(ctx, courseRef, version)
+	}
+	return s.GetChannelRelease(ctx, courseRef, model.ReleaseChannel(ref))
+}
+
+// DiffReleases lists what was added, changed and removed between two
+// releases of a course.
+func (s *ReleaseService) DiffReleases(
+	ctx context.Context,
+	courseRef string,
+	fromVersion, toVersion int,
+) ([]release.Change, error) {
+	from, err := s.GetRelease(ctx, courseRef, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.GetRelease(ctx, courseRef, toVersion)
+	if err != nil {
+		return nil, err
+	}
+	return release.Diff(from.Content, to.Content)
+}
+
+// Promote points a channel of the course at one of its releases and
+// returns that release.
+func (s *ReleaseService) Promote(
+	ctx context.Context,
+	courseRef string,
+	version int,
+	channel model.ReleaseChannel,
+) (*model.Release, error) {
+	if err := checkChannel(channel); err != nil {
+		return nil, err
+	}
+	rel, err := s.GetRelease(ctx, courseRef, version)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.releases.Promote(ctx, rel.CourseID, channel, rel.ID, currentUserID(ctx), time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return s.GetRelease(ctx, rel.CourseID.String(), version)
+}
+
+// resolveCourse finds a live course by ID or, failing that, by slug.
+func (s *ReleaseService) resolveCourse(ctx context.Context, ref string) (*model.Course, error) {
+	var course *model.Course
+	var err error
+	if id, parseErr := uuid.Parse(ref); parseErr == nil {
+		course, err = s.courses.GetByID(ctx, id, false)
+	} else {
+		course, err = s.courses.GetBySlug(ctx, ref)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("course not found: %w", err)
+	}
+	return course, nil
+}
+
+func (s *ReleaseService) verified(rel *model.Release, err error) (*model.Release, error) {
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("release not found: %w", err)
+		}
+		return nil, err
+	}
+	if err := release.Verify(rel.Content, rel.ContentHash); err != nil {
+		return nil, fmt.Errorf("release %d of course %s: %w", rel.Version, rel.CourseID, err)
+	}
+	return rel, nil
+}
+
+func checkChannel(channel model.ReleaseChannel) error {
+	if slices.Contains(model.ReleaseChannels, channel) {
+		return nil
+	}
+	var errs validation.Errors
+	errs.Add("channel", "must be one of %v", model.ReleaseChannels)
+	return errs.Err()
+}
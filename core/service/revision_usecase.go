@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/diff"
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/google/uuid"
+)
+
+// volatileFields change on every save and are left out of revision diffs.
+var volatileFields = []string{"version", "created_at", "updated_at", "deleted_at"}
+
+// RevisionService exposes the revision history of content entities and rolls
+// entities back to earlier versions.
+type RevisionService struct {
+	repo      repository.RevisionRepository
+	courses   *CourseService
+	units     *UnitService
+	skills    *SkillService
+	lessons   *LessonService
+	exercises *ExerciseService
+}
+
+func NewRevisionService(
+	repo repository.RevisionRepository,
+	courses *CourseService,
+	units *UnitService,
+	skills *SkillService,
+	lessons *LessonService,
+	exercises *ExerciseService,
+) *RevisionService {
+	return &RevisionService{
+		repo:      repo,
+		courses:   courses,
+		units:     units,
+		skills:    skills,
+		lessons:   lessons,
+		exercises: exercises,
+	}
+}
+
+// ListRevisions returns an entity's revisions, newest first, without snapshots.
+func (s *RevisionService) ListRevisions(
+	ctx context.Context,
+	entityType model.EntityType,
+	id uuid.UUID,
+) ([]*model.Revision, error) {
+	return s.repo.ListByEntity(ctx, entityType, id)
+}
+
+// GetRevision returns an entity's snapshot at one version.
+func (s *RevisionService) GetRevision(
+	ctx context.Context,
+	entityType model.EntityType,
+	id uuid.UUID,
+	version int,
+) (*model.Revision, error) {
+	rev, err := s.repo.GetByVersion(ctx, entityType, id, version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("revision not found: %w", err)
+		}
+		return nil, err
+	}
+	return rev, nil
+}
+
+// DiffRevisions lists the fields that changed between two versions.
+func (s *RevisionService) DiffRevisions(
+	ctx context.Context,
+	entityType model.EntityType,
+	id uuid.UUID,
+	fromVersion, toVersion int,
+) ([]diff.Change, error) {
+	from, err := s.GetRevision(ctx, entityType, id, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.GetRevision(ctx, entityType, id, toVersion)
+	if err != nil {
+		return nil, err
+	}
+	return diff.Fields(from.Snapshot, to.Snapshot, volatileFields...), nil
+}
+
+// Rollback saves the snapshot of an earlier version as a new version and
+// returns the revision recorded for it. expectedVersion guards against
+// concurrent edits like a normal update; 0 rolls back whatever is current.
+func (s *RevisionService) Rollback(
+	ctx context.Context,
+	entityType model.EntityType,
+	id uuid.UUID,
+	version int,
+	expectedVersion int,
+) (*model.Revision, error) {
+	target, err := s.GetRevision(ctx, entityType, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	var newVersion int
+	switch entityType {
+	case model.EntityCourse:
+		current, err := s.courses.GetCourseByID(ctx, id, false)
+		if err != nil {
+			return nil, fmt.Errorf("course not found: %w", err)
+		}
+		var c model.Course
+		if err := decodeSnapshot(target.Snapshot, &c); err != nil {
+			return nil, err
+		}
+		c.ID, c.Version = id, baseVersion(expectedVersion, current.Version)
+		if err := s.courses.UpdateCourse(ctx, &c); err != nil {
+			return nil, err
+		}
+		newVersion = c.Version
+
+	case model.EntityUnit:
+		current, err := s.units.GetUnitByID(ctx, id, false)
+		if err != nil {
+			return nil, fmt.Errorf("unit not found: %w", err)
+		}
+		var u model.Unit
+		if err := decodeSnapshot(target.Snapshot, &u); err != nil {
+			return nil, err
+		}
+		u.ID, u.Version = id, baseVersion(expectedVersion, current.Version)
+		if err := s.units.UpdateUnit(ctx, &u); err != nil {
+			return nil, err
+		}
+		newVersion = u.Version
+
+	case model.EntitySkill:
+		current, err := s.skills.GetSkillByID(ctx, id, false)
+		if err != nil {
+			return nil, fmt.Errorf("skill not found: %w", err)
+		}
+		var sk model.Skill
+		if err := decodeSnapshot(target.Snapshot, &sk); err != nil {
+			return nil, err
+		}
+		sk.ID, sk.Version = id, baseVersion(expectedVersion, current.Version)
+		if err := s.skills.UpdateSkill(ctx, &sk); err != nil {
+			return nil, err
+		}
+		newVersion = sk.Version
+
+	case model.EntityLesson:
+		current, err := s.lessons.GetLessonByID(ctx, id, false)
+		if err != nil {
+			return nil, fmt.Errorf("lesson not found: %w", err)
+		}
+		var l model.Lesson
+		if err := decodeSnapshot(target.Snapshot, &l); err != nil {
+			return nil, err
+		}
+		l.ID, l.Version = id, baseVersion(expectedVersion, current.Version)
+		if err := s.lessons.UpdateLesson(ctx, &l); err != nil {
+			return nil, err
+		}
+		newVersion = l.Version
+
+	case model.EntityExercise:
+		current, err := s.exercises.GetExerciseByID(ctx, id, false)
+		if err != nil {
+			return nil, fmt.Errorf("exercise not found: %w", err)
+		}
+		var e model.Exercise
+		snap := exerciseSnapshot{Exercise: &e}
+		if err := decodeSnapshot(target.Snapshot, &snap); err != nil {
+			return nil, err
+		}
+		// Revisions from before options were recorded leave them as they are
+		e.ID, e.Version = id, baseVersion(expectedVersion, current.Version)
+		if err := s.exercises.updateExercise(ctx, &e, snap.Options); err != nil {
+			return nil, err
+		}
+		newVersion = e.Version
+
+	default:
+		return nil, fmt.Errorf("unknown entity type %q", entityType)
+	}
+
+	return s.GetRevision(ctx, entityType, id, newVersion)
+}
+
+func baseVersion(expected, current int) int {
+	if expected > 0 {
+		return expected
+	}
+	return current
+}
+
+// recordRevision stores a snapshot of entity at version. Recording a version
+// that already exists is a no-op, which lets updates first record the version
+// they replace in case it predates revision history.
+func recordRevision(
+	ctx context.Context,
+	repo repository.RevisionRepository,
+	entityType model.EntityType,
+	id uuid.UUID,
+	version int,
+	entity any,
+	author *uuid.UUID,
+	at time.Time,
+) error {
+	snapshot, err := toSnapshot(entity)
+	if err != nil {
+		return err
+	}
+
+	rev := &model.Revision{
+		ID:         uuid.New(),
+		EntityType: entityType,
+		EntityID:   id,
+		Version:    version,
+		Snapshot:   snapshot,
+		AuthorID:   author,
+		CreatedAt:  at,
+	}
+	if err := repo.Create(ctx, rev); err != nil {
+		return fmt.Errorf("recording %s revision %d: %w", entityType, version, err)
+	}
+	return nil
+}
+
+// exerciseSnapshot is what exercise revisions record: the exercise together
+// with its options, which are versioned with it.
+type exerciseSnapshot struct {
+	*model.Exercise
+	Options []*model.ExerciseOption `json:"options"`
+}
+
+// recordExerciseRevision is recordRevision for an exercise, which is
+// recorded together with its current options.
+func recordExerciseRevision(
+	ctx context.Context,
+	revisions repository.RevisionRepository,
+	options repository.ExerciseOptionRepository,
+	e *model.Exercise,
+	author *uuid.UUID,
+	at time.Time,
+) error {
+	snapshot, err := revisionSnapshot(ctx, options, e)
+	if err != nil {
+		return err
+	}
+	return recordRevision(ctx, revisions, model.EntityExercise, e.ID, e.Version, snapshot, author, at)
+}
+
+// revisionSnapshot returns what a revision of entity records: exercises come
+// with their current options, anything else as it is.
+func revisionSnapshot(
+	ctx context.Context,
+	options repository.ExerciseOptionRepository,
+	entity any,
+) (any, error) {
+	e, ok := entity.(*model.Exercise)
+	if !ok {
+		return entity, nil
+	}
+	opts, err := options.ListByExerciseID(ctx, e.ID)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = []*model.ExerciseOption{}
+	}
+	return exerciseSnapshot{Exercise: e, Options: opts}, nil
+}
+
+// currentUserID returns the authenticated user stored on the request context.
+func currentUserID(ctx context.Context) *uuid.UUID {
+	if val, ok := ctx.Value("user_id").(string); ok {
+		if parsed, err := uuid.Parse(val); err == nil {
+			return &parsed
+		}
+	}
+	return nil
+}
+
+// withUserID returns a context acting on behalf of a user, for work done
+// outside a request. It uses the same key as the auth middleware.
+func withUserID(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, "user_id", id.String())
+}
+
+func toSnapshot(entity any) (model.JSONB, error) {
+	raw, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot model.JSONB
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func decodeSnapshot(snapshot model.JSONB, dst any) error {
+	raw, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return fmt.Errorf("decoding revision snapshot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/bytebeatz/bandroom-cms/core/validation"
+	"github.com/bytebeatz/bandroom-cms/core/workflow"
+	"github.com/google/uuid"
+)
+
+// schedulerLock is the lock every replica's scheduler competes for, so each
+// due transition is applied by exactly one of them.
+const schedulerLock = "bandroom-cms:scheduler"
+
+// Scheduler publishes and unpublishes courses at their scheduled times.
+type Scheduler struct {
+	courses  repository.CourseRepository
+	workflow *WorkflowService
+	locker   repository.Locker
+	tx       repository.Transactor
+	interval time.Duration
+}
+
+func NewScheduler(
+	courses repository.CourseRepository,
+	workflowService *WorkflowService,
+	locker repository.Locker,
+	tx repository.Transactor,
+	interval time.Duration,
+) *Scheduler {
+	return &Scheduler{
+		courses:  courses,
+		workflow: workflowService,
+		locker:   locker,
+		tx:       tx,
+		interval: interval,
+	}
+}
+
+// Run applies due transitions every interval until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce applies the transitions due now. It does nothing if another
+// replica is already at it.
+func (s *Scheduler) RunOnce(ctx context.Context) {
+	release, ok, err := s.locker.TryLock(ctx, schedulerLock)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Println("Scheduler could not take its lock:", err)
+		}
+		return
+	}
+	if !ok {
+		return
+	}
+	defer release()
+
+	// Read due work only once the lock is held, so nothing another replica
+	// has just applied is seen again
+	now := time.Now().UTC()
+	courses, err := s.courses.ListDueSchedules(ctx, now)
+	if err != nil {
+		log.Println("Scheduler could not list due courses:", err)
+		return
+	}
+
+	for _, c := range courses {
+		if ctx.Err() != nil {
+			return
+		}
+		if c.PublishAt != nil && !c.PublishAt.After(now) {
+			s.apply(ctx, c.ID, workflow.ActionPublish, *c.PublishAt, c.PublishScheduledBy,
+				s.courses.ClearPublishAt)
+		}
+		if c.UnpublishAt != nil && !c.UnpublishAt.After(now) {
+			s.apply(ctx, c.ID, workflow.ActionUnpublish, *c.UnpublishAt, c.UnpublishScheduledBy,
+				s.courses.ClearUnpublishAt)
+		}
+	}
+}
+
+// apply performs one scheduled transition on behalf of whoever scheduled it
+// and clears the schedule in the same transaction, so a transition is never
+// applied without its schedule being dropped. Transitions the workflow
+// refuses are dropped with a log line rather than retried forever; other
+// errors are retried on the next run.
+func (s *Scheduler) apply(
+	ctx context.Context,
+	courseID uuid.UUID,
+	action workflow.Action,
+	at time.Time,
+	by *uuid.UUID,
+	clearSchedule func(ctx context.Context, id uuid.UUID, at time.Time) error,
+) {
+	if by != nil {
+		ctx = withUserID(ctx, *by)
+	}
+
+	var dropped error
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		// Publishing also publishes approved content beneath the course
+		_, err := s.workflow.Transition(ctx, model.EntityCourse, courseID, action,
+			workflow.RoleAdmin, action == workflow.ActionPublish)
+		if err != nil && !refused(err) {
+			return err
+		}
+		// Refusals are decided before anything is written, so the
+		// transaction is still good for clearing the schedule
+		dropped = err
+		return clearSchedule(ctx, courseID, at)
+	})
+	switch {
+	case err != nil:
+		log.Printf("Scheduled %s of course %s failed, will retry: %v", action, courseID, err)
+	case dropped != nil:
+		log.Printf("Scheduled %s of course %s dropped: %v", action, courseID, dropped)
+	default:
+		log.Printf("Scheduled %s of course %s applied", action, courseID)
+	}
+}
+
+// refused reports whether the workflow turned a transition down, as opposed
+// to failing to apply it.
+func refused(err error) bool {
+	var verrs validation.Errors
+	return errors.As(err, &verrs) ||
+		errors.Is(err, workflow.ErrInvalidTransition) ||
+		errors.Is(err, workflow.ErrBlocked) ||
+		errors.Is(err, workflow.ErrForbidden)
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+)
+
+// MaxSearchResults caps the number of hits a search may return.
+const MaxSearchResults = 100
+
+// SearchService handles full-text search across the curriculum.
+type SearchService struct {
+	repo repository.SearchRepository
+}
+
+func NewSearchService(repo repository.SearchRepository) *SearchService {
+	return &SearchService{repo: repo}
+}
+
+// Search validates the query and returns the ranked hits.
+func (s *SearchService) Search(
+	ctx context.Context,
+	q repository.SearchQuery,
+) ([]*model.SearchHit, error) {
+	q.Text = strings.TrimSpace(q.Text)
+	if q.Text == "" {
+		return nil, fmt.Errorf("%w: search text is required", repository.ErrInvalidQuery)
+	}
+
+	for _, t := range q.Types {
+		switch t {
+		case model.EntityCourse, model.EntityUnit, model.EntitySkill,
+			model.EntityLesson, model.EntityExercise:
+		default:
+			return nil, fmt.Errorf("%w: unknown type %q", repository.ErrInvalidQuery, t)
+		}
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = repository.DefaultPageSize
+	}
+	q.Limit = min(q.Limit, MaxSearchResults)
+
+	return s.repo.Search(ctx, q)
+}
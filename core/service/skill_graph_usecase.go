@@ -0,0 +1,62 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/bytebeatz/bandroom-cms/core/skillgraph"
+	"github.com/google/uuid"
+)
+
+// SkillGraphService analyzes the prerequisite graph of a course's skills.
+type SkillGraphService struct {
+	courses repository.CourseRepository
+	units   repository.UnitRepository
+	skills  repository.SkillRepository
+}
+
+func NewSkillGraphService(
+	courses repository.CourseRepository,
+	units repository.UnitRepository,
+	skills repository.SkillRepository,
+) *SkillGraphService {
+	return &SkillGraphService{courses: courses, units: units, skills: skills}
+}
+
+// GetSkillGraph builds the skill graph of a course. Skills are taken in
+// course order (unit, then skill order), which also breaks ties in the
+// unlock order.
+func (s *SkillGraphService) GetSkillGraph(
+	ctx context.Context,
+	courseID uuid.UUID,
+) (*model.SkillGraph, error) {
+	if _, err := s.courses.GetByID(ctx, courseID, false); err != nil {
+		return nil, fmt.Errorf("course not found: %w", err)
+	}
+
+	units, err := s.units.ListByCourseID(ctx, courseID, false)
+	if err != nil {
+		return nil, err
+	}
+	unitOrder := make(map[uuid.UUID]int, len(units))
+	for i, u := range units {
+		unitOrder[u.ID] = i
+	}
+
+	skills, err := s.skills.ListByCourseID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(skills, func(a, b *model.Skill) int {
+		return cmp.Or(
+			cmp.Compare(unitOrder[a.UnitID], unitOrder[b.UnitID]),
+			cmp.Compare(a.OrderIndex, b.OrderIndex),
+		)
+	})
+
+	return skillgraph.New(skills).Analyze(courseID), nil
+}
@@ -2,23 +2,36 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/bytebeatz/bandroom-cms/core/model"
 	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/bytebeatz/bandroom-cms/core/skillgraph"
+	"github.com/bytebeatz/bandroom-cms/core/validation"
+	"github.com/bytebeatz/bandroom-cms/core/workflow"
 	"github.com/bytebeatz/bandroom-cms/utils"
 	"github.com/google/uuid"
 )
 
 // SkillService handles business logic for skills.
 type SkillService struct {
-	repo repository.SkillRepository
+	repo      repository.SkillRepository
+	units     repository.UnitRepository
+	revisions repository.RevisionRepository
+	tx        repository.Transactor
 }
 
-func NewSkillService(repo repository.SkillRepository) *SkillService {
-	return &SkillService{repo: repo}
+func NewSkillService(
+	repo repository.SkillRepository,
+	units repository.UnitRepository,
+	revisions repository.RevisionRepository,
+	tx repository.Transactor,
+) *SkillService {
+	return &SkillService{repo: repo, units: units, revisions: revisions, tx: tx}
 }
 
 func (s *SkillService) CreateSkill(
@@ -44,46 +57,221 @@ func (s *SkillService) CreateSkill(
 		skill.Slug = utils.GenerateSlug(skill.Title)
 	}
 
-	if skill.Version == 0 {
-		skill.Version = 1
-	}
+	skill.Version = 1
+	skill.Status = model.StateDraft
 
 	// ✅ Validate creator_id is not nil (i.e. zero UUID)
 	if skill.CreatorID == uuid.Nil {
 		return fmt.Errorf("creator_id must be set")
 	}
 
-	return s.repo.Create(ctx, skill)
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.checkPrerequisites(ctx, skill); err != nil {
+			return err
+		}
+		if err := s.repo.Create(ctx, skill); err != nil {
+			return err
+		}
+		return recordRevision(ctx, s.revisions, model.EntitySkill, skill.ID, skill.Version, skill,
+			currentUserID(ctx), skill.CreatedAt)
+	})
 }
 
 func (s *SkillService) UpdateSkill(ctx context.Context, updated *model.Skill) error {
-	existing, err := s.repo.GetByID(ctx, updated.ID)
+	existing, err := s.repo.GetByID(ctx, updated.ID, false)
 	if err != nil {
 		return fmt.Errorf("skill not found: %w", err)
 	}
 
+	// Version holds the version the client last read; reject stale writes
+	if updated.Version != existing.Version {
+		return repository.ErrVersionConflict
+	}
+
+	// Skills stay in their course; prerequisites are checked against it.
+	// Moves to another unit go through ReorderSkills
+	updated.CourseID = existing.CourseID
+	updated.UnitID = existing.UnitID
+	updated.CreatorID = existing.CreatorID
+
 	updated.CreatedAt = existing.CreatedAt
+	// Workflow state only changes through transitions, except that an edit
+	// sends approved content back to draft
+	if updated.Status, err = workflow.AfterEdit(existing.Status); err != nil {
+		return err
+	}
 	updated.UpdatedAt = time.Now().UTC()
-	updated.Version = existing.Version + 1
 
 	if strings.TrimSpace(updated.Slug) == "" {
 		updated.Slug = utils.GenerateSlug(updated.Title)
 	}
 
-	return s.repo.Update(ctx, updated)
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.checkPrerequisites(ctx, updated); err != nil {
+			return err
+		}
+		// Keep the version being replaced, in case it predates revision history
+		if err := recordRevision(ctx, s.revisions, model.EntitySkill, existing.ID, existing.Version,
+			existing, nil, existing.UpdatedAt); err != nil {
+			return err
+		}
+
+		if err := s.repo.Update(ctx, updated); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("skill not found: %w", err)
+			}
+			return err
+		}
+		return recordRevision(ctx, s.revisions, model.EntitySkill, updated.ID, updated.Version, updated,
+			currentUserID(ctx), updated.UpdatedAt)
+	})
+}
+
+// checkPrerequisites locks the skill's course and validates its
+// prerequisites. It runs in the transaction that saves the skill: two
+// concurrent edits could otherwise each pass the cycle check and together
+// form a cycle.
+func (s *SkillService) checkPrerequisites(ctx context.Context, skill *model.Skill) error {
+	if err := s.repo.LockCourse(ctx, skill.CourseID); err != nil {
+		return err
+	}
+	return s.validatePrerequisites(ctx, skill)
+}
+
+// validatePrerequisites checks that a skill's prerequisites exist, belong to
+// its course and do not form a cycle with the rest of the course's skills.
+func (s *SkillService) validatePrerequisites(ctx context.Context, skill *model.Skill) error {
+	if len(skill.PrerequisiteSkillIDs) == 0 {
+		return nil
+	}
+
+	courseSkills, err := s.repo.ListByCourseID(ctx, skill.CourseID)
+	if err != nil {
+		return err
+	}
+
+	// Check against the course as it will be once this skill is saved
+	candidate := make([]*model.Skill, 0, len(courseSkills)+1)
+	for _, cs := range courseSkills {
+		if cs.ID != skill.ID {
+			candidate = append(candidate, cs)
+		}
+	}
+	candidate = append(candidate, skill)
+	graph := skillgraph.New(candidate)
+
+	var errs validation.Errors
+	seen := map[uuid.UUID]bool{}
+	for i, id := range skill.PrerequisiteSkillIDs {
+		field := fmt.Sprintf("prerequisite_skill_ids[%d]", i)
+		switch {
+		case id == skill.ID:
+			errs.Add(field, "a skill cannot be its own prerequisite")
+		case seen[id]:
+			errs.Add(field, "skill %s is listed more than once", id)
+		case graph.Skill(id) == nil:
+			other, err := s.repo.GetByID(ctx, id, false)
+			switch {
+			case err != nil && !errors.Is(err, sql.ErrNoRows):
+				return err
+			case err == nil && other.CourseID != skill.CourseID:
+				errs.Add(field, "skill %s belongs to another course", id)
+			default:
+				errs.Add(field, "skill %s does not exist", id)
+			}
+		}
+		seen[id] = true
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	if cycle := graph.CycleFrom(skill.ID); cycle != nil {
+		titles := make([]string, len(cycle))
+		for i, id := range cycle {
+			titles[i] = graph.Skill(id).Title
+		}
+		errs.Add("prerequisite_skill_ids", "prerequisites form a cycle: %s",
+			strings.Join(titles, " → "))
+	}
+	return errs.Err()
 }
 
+// DeleteSkill soft-deletes a skill together with its lessons and exercises.
 func (s *SkillService) DeleteSkill(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("skill not found: %w", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// RestoreSkill brings back a soft-deleted skill and the content deleted with it.
+func (s *SkillService) RestoreSkill(ctx context.Context, id uuid.UUID) (*model.Skill, error) {
+	if err := s.repo.Restore(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("skill not found: %w", err)
+		}
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id, false)
 }
 
-func (s *SkillService) GetSkillByID(ctx context.Context, id uuid.UUID) (*model.Skill, error) {
-	return s.repo.GetByID(ctx, id)
+func (s *SkillService) GetSkillByID(
+	ctx context.Context,
+	id uuid.UUID,
+	includeDeleted bool,
+) (*model.Skill, error) {
+	return s.repo.GetByID(ctx, id, includeDeleted)
 }
 
 func (s *SkillService) ListSkillsByUnitID(
 	ctx context.Context,
 	unitID uuid.UUID,
+	includeDeleted bool,
 ) ([]*model.Skill, error) {
-	return s.repo.ListByUnitID(ctx, unitID)
+	return s.repo.ListByUnitID(ctx, unitID, includeDeleted)
+}
+
+// ListSkills returns one page of skills matching q.
+func (s *SkillService) ListSkills(
+	ctx context.Context,
+	q repository.ListQuery,
+) (*repository.Page[*model.Skill], error) {
+	return s.repo.List(ctx, q)
+}
+
+// ReorderSkills rewrites the order of a unit's skills. The ID list must
+// contain the unit's current skills and may add skills from other units of
+// the same course, which are moved into this unit.
+func (s *SkillService) ReorderSkills(
+	ctx context.Context,
+	unitID uuid.UUID,
+	ids []uuid.UUID,
+) ([]*model.Skill, error) {
+	unit, err := s.units.GetByID(ctx, unitID, false)
+	if err != nil {
+		return nil, fmt.Errorf("unit not found: %w", err)
+	}
+
+	skills, err := s.repo.ListByCourseID(ctx, unit.CourseID)
+	if err != nil {
+		return nil, err
+	}
+
+	order := childOrder[*model.Skill]{
+		entity: model.EntitySkill,
+		id:     func(sk *model.Skill) uuid.UUID { return sk.ID },
+		parent: func(sk *model.Skill) uuid.UUID { return sk.UnitID },
+		index:  func(sk *model.Skill) int { return sk.OrderIndex },
+		move: func(sk *model.Skill, unitID uuid.UUID, index int, at time.Time) {
+			sk.UnitID, sk.OrderIndex, sk.UpdatedAt = unitID, index, at
+		},
+		version:   func(sk *model.Skill) int { return sk.Version },
+		updatedAt: func(sk *model.Skill) time.Time { return sk.UpdatedAt },
+		save:      s.repo.UpdateOrder,
+	}
+	return order.reorder(ctx, s.tx, s.revisions, unitID, ids, skills, true)
 }
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/google/uuid"
+)
+
+// TreeDepth is the deepest level included in a course tree.
+type TreeDepth int
+
+const (
+	DepthUnits TreeDepth = iota + 1
+	DepthSkills
+	DepthLessons
+	DepthExercises
+)
+
+// ParseTreeDepth reads a ?depth= value; an empty value means the full tree.
+func ParseTreeDepth(s string) (TreeDepth, error) {
+	switch s {
+	case "units":
+		return DepthUnits, nil
+	case "skills":
+		return DepthSkills, nil
+	case "lessons":
+		return DepthLessons, nil
+	case "", "exercises":
+		return DepthExercises, nil
+	default:
+		return 0, fmt.Errorf("%w: depth must be units, skills, lessons or exercises",
+			repository.ErrInvalidQuery)
+	}
+}
+
+// TreeService assembles whole-course hierarchies.
+type TreeService struct {
+	courses   repository.CourseRepository
+	units     repository.UnitRepository
+	skills    repository.SkillRepository
+	lessons   repository.LessonRepository
+	exercises repository.ExerciseRepository
+}
+
+func NewTreeService(
+	courses repository.CourseRepository,
+	units repository.UnitRepository,
+	skills repository.SkillRepository,
+	lessons repository.LessonRepository,
+	exercises repository.ExerciseRepository,
+) *TreeService {
+	return &TreeService{
+		courses:   courses,
+		units:     units,
+		skills:    skills,
+		lessons:   lessons,
+		exercises: exercises,
+	}
+}
+
+// GetCourseTree loads a course and everything beneath it with one query per
+// level, whatever the size of the course. Stats are computed over the whole
+// hierarchy even when depth cuts the tree short.
+func (s *TreeService) GetCourseTree(
+	ctx context.Context,
+	courseID uuid.UUID,
+	depth TreeDepth,
+) (*model.CourseTree, error) {
+	course, err := s.courses.GetByID(ctx, courseID, false)
+	if err != nil {
+		return nil, fmt.Errorf("course not found: %w", err)
+	}
+
+	units, err := s.units.ListByCourseID(ctx, courseID, false)
+	if err != nil {
+		return nil, err
+	}
+	skills, err := s.skills.ListByCourseID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	lessons, err := s.lessons.ListByCourseID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	exerciseCounts, err := s.exercises.CountByLessonForCourse(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	exercisesByLesson := map[uuid.UUID][]*model.Exercise{}
+	if depth >= DepthExercises {
+		exercises, err := s.exercises.ListByCourseID(ctx, courseID)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range exercises {
+			exercisesByLesson[e.LessonID] = append(exercisesByLesson[e.LessonID], e)
+		}
+	}
+
+	lessonsBySkill := map[uuid.UUID][]*model.LessonNode{}
+	for _, l := range lessons {
+		node := &model.LessonNode{
+			Lesson: l,
+			Stats: model.TreeStats{
+				Exercises:         exerciseCounts[l.ID],
+				EstimatedDuration: l.EstimatedDuration,
+			},
+		}
+		if depth >= DepthExercises {
+			node.Exercises = append([]*model.Exercise{}, exercisesByLesson[l.ID]...)
+		}
+		lessonsBySkill[l.SkillID] = append(lessonsBySkill[l.SkillID], node)
+	}
+
+	skillsByUnit := map[uuid.UUID][]*model.SkillNode{}
+	for _, sk := range skills {
+		node := &model.SkillNode{Skill: sk}
+		for _, child := range lessonsBySkill[sk.ID] {
+			node.AddChild(child)
+		}
+		if depth >= DepthLessons {
+			node.Lessons = append([]*model.LessonNode{}, lessonsBySkill[sk.ID]...)
+		}
+		skillsByUnit[sk.UnitID] = append(skillsByUnit[sk.UnitID], node)
+	}
+
+	tree := &model.CourseTree{Course: course, Units: []*model.UnitNode{}}
+	for _, u := range units {
+		node := &model.UnitNode{Unit: u}
+		for _, child := range skillsByUnit[u.ID] {
+			node.AddChild(child)
+		}
+		if depth >= DepthSkills {
+			node.Skills = append([]*model.SkillNode{}, skillsByUnit[u.ID]...)
+		}
+		tree.AddChild(node)
+		tree.Units = append(tree.Units, node)
+	}
+
+	return tree, nil
+}
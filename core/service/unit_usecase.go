@@ -2,22 +2,33 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/bytebeatz/bandroom-cms/core/model"
 	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/bytebeatz/bandroom-cms/core/workflow"
 	"github.com/google/uuid"
 )
 
 // UnitService handles business logic for units.
 type UnitService struct {
-	repo repository.UnitRepository
+	repo      repository.UnitRepository
+	courses   repository.CourseRepository
+	revisions repository.RevisionRepository
+	tx        repository.Transactor
 }
 
 // NewUnitService initializes a new UnitService.
-func NewUnitService(repo repository.UnitRepository) *UnitService {
-	return &UnitService{repo: repo}
+func NewUnitService(
+	repo repository.UnitRepository,
+	courses repository.CourseRepository,
+	revisions repository.RevisionRepository,
+	tx repository.Transactor,
+) *UnitService {
+	return &UnitService{repo: repo, courses: courses, revisions: revisions, tx: tx}
 }
 
 // CreateUnit handles creation logic including UUIDs, timestamps, versioning.
@@ -26,42 +37,134 @@ func (s *UnitService) CreateUnit(ctx context.Context, unit *model.Unit) error {
 	unit.CreatedAt = time.Now().UTC()
 	unit.UpdatedAt = unit.CreatedAt
 
-	if unit.Version == 0 {
-		unit.Version = 1
-	}
+	unit.Version = 1
+	unit.Status = model.StateDraft
 
 	fmt.Printf("Creating unit: %+v\n", unit)
-	return s.repo.Create(ctx, unit)
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, unit); err != nil {
+			return err
+		}
+		return recordRevision(ctx, s.revisions, model.EntityUnit, unit.ID, unit.Version, unit,
+			currentUserID(ctx), unit.CreatedAt)
+	})
 }
 
 // UpdateUnit handles updating unit metadata and versioning.
 func (s *UnitService) UpdateUnit(ctx context.Context, updated *model.Unit) error {
-	existing, err := s.repo.GetByID(ctx, updated.ID)
+	existing, err := s.repo.GetByID(ctx, updated.ID, false)
 	if err != nil {
 		return fmt.Errorf("unit not found: %w", err)
 	}
 
+	// Version holds the version the client last read; reject stale writes
+	if updated.Version != existing.Version {
+		return repository.ErrVersionConflict
+	}
+
+	// Units stay in their course
+	updated.CourseID = existing.CourseID
 	updated.CreatedAt = existing.CreatedAt
+	// Workflow state only changes through transitions, except that an edit
+	// sends approved content back to draft
+	if updated.Status, err = workflow.AfterEdit(existing.Status); err != nil {
+		return err
+	}
 	updated.UpdatedAt = time.Now().UTC()
-	updated.Version = existing.Version + 1
 
-	return s.repo.Update(ctx, updated)
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		// Keep the version being replaced, in case it predates revision history
+		if err := recordRevision(ctx, s.revisions, model.EntityUnit, existing.ID, existing.Version,
+			existing, nil, existing.UpdatedAt); err != nil {
+			return err
+		}
+
+		if err := s.repo.Update(ctx, updated); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("unit not found: %w", err)
+			}
+			return err
+		}
+		return recordRevision(ctx, s.revisions, model.EntityUnit, updated.ID, updated.Version, updated,
+			currentUserID(ctx), updated.UpdatedAt)
+	})
 }
 
-// DeleteUnit deletes a unit by ID.
+// DeleteUnit soft-deletes a unit and everything beneath it.
 func (s *UnitService) DeleteUnit(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("unit not found: %w", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// RestoreUnit brings back a soft-deleted unit and the content deleted with it.
+func (s *UnitService) RestoreUnit(ctx context.Context, id uuid.UUID) (*model.Unit, error) {
+	if err := s.repo.Restore(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("unit not found: %w", err)
+		}
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id, false)
 }
 
 // GetUnitByID fetches a unit by UUID.
-func (s *UnitService) GetUnitByID(ctx context.Context, id uuid.UUID) (*model.Unit, error) {
-	return s.repo.GetByID(ctx, id)
+func (s *UnitService) GetUnitByID(
+	ctx context.Context,
+	id uuid.UUID,
+	includeDeleted bool,
+) (*model.Unit, error) {
+	return s.repo.GetByID(ctx, id, includeDeleted)
 }
 
 // ListUnitsByCourseID returns all units for a given course.
 func (s *UnitService) ListUnitsByCourseID(
 	ctx context.Context,
 	courseID uuid.UUID,
+	includeDeleted bool,
+) ([]*model.Unit, error) {
+	return s.repo.ListByCourseID(ctx, courseID, includeDeleted)
+}
+
+// ListUnits returns one page of units matching q.
+func (s *UnitService) ListUnits(
+	ctx context.Context,
+	q repository.ListQuery,
+) (*repository.Page[*model.Unit], error) {
+	return s.repo.List(ctx, q)
+}
+
+// ReorderUnits rewrites the order of a course's units. The ID list must
+// contain exactly the course's current units.
+func (s *UnitService) ReorderUnits(
+	ctx context.Context,
+	courseID uuid.UUID,
+	ids []uuid.UUID,
 ) ([]*model.Unit, error) {
-	return s.repo.ListByCourseID(ctx, courseID)
+	if _, err := s.courses.GetByID(ctx, courseID, false); err != nil {
+		return nil, fmt.Errorf("course not found: %w", err)
+	}
+
+	units, err := s.repo.ListByCourseID(ctx, courseID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	order := childOrder[*model.Unit]{
+		entity: model.EntityUnit,
+		id:     func(u *model.Unit) uuid.UUID { return u.ID },
+		parent: func(u *model.Unit) uuid.UUID { return u.CourseID },
+		index:  func(u *model.Unit) int { return u.OrderIndex },
+		move: func(u *model.Unit, _ uuid.UUID, index int, at time.Time) {
+			u.OrderIndex, u.UpdatedAt = index, at
+		},
+		version:   func(u *model.Unit) int { return u.Version },
+		updatedAt: func(u *model.Unit) time.Time { return u.UpdatedAt },
+		save:      s.repo.UpdateOrder,
+	}
+	return order.reorder(ctx, s.tx, s.revisions, courseID, ids, units, false)
 }
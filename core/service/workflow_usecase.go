@@ -0,0 +1,447 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/bytebeatz/bandroom-cms/core/repository"
+	"github.com/bytebeatz/bandroom-cms/core/validation"
+	"github.com/bytebeatz/bandroom-cms/core/workflow"
+	"github.com/google/uuid"
+)
+
+// WorkflowService moves content through the publishing workflow.
+type WorkflowService struct {
+	courses   repository.CourseRepository
+	units     repository.UnitRepository
+	skills    repository.SkillRepository
+	lessons   repository.LessonRepository
+	exercises repository.ExerciseRepository
+	options   repository.ExerciseOptionRepository
+	status    repository.WorkflowRepository
+	revisions repository.RevisionRepository
+	tx        repository.Transactor
+}
+
+func NewWorkflowService(
+	courses repository.CourseRepository,
+	units repository.UnitRepository,
+	skills repository.SkillRepository,
+	lessons repository.LessonRepository,
+	exercises repository.ExerciseRepository,
+	options repository.ExerciseOptionRepository,
+	status repository.WorkflowRepository,
+	revisions repository.RevisionRepository,
+	tx repository.Transactor,
+) *WorkflowService {
+	return &WorkflowService{
+		courses:   courses,
+		units:     units,
+		skills:    skills,
+		lessons:   lessons,
+		exercises: exercises,
+		options:   options,
+		status:    status,
+		revisions: revisions,
+		tx:        tx,
+	}
+}
+
+// contentNode is one entity of a course in the workflow's view of it.
+type contentNode struct {
+	entity   model.EntityType
+	id       uuid.UUID
+	title    string
+	value    any // *model.Course, *model.Unit, ...
+	parent   *contentNode
+	children []*contentNode
+}
+
+func (n *contentNode) status() model.WorkflowState {
+	_, status, _ := workflowFields(n.value)
+	return *status
+}
+
+// workflowFields points at the fields a status change touches.
+func workflowFields(v any) (version *int, status *model.WorkflowState, updatedAt *time.Time) {
+	switch e := v.(type) {
+	case *model.Course:
+		return &e.Version, &e.Status, &e.UpdatedAt
+	case *model.Unit:
+		return &e.Version, &e.Status, &e.UpdatedAt
+	case *model.Skill:
+		return &e.Version, &e.Status, &e.UpdatedAt
+	case *model.Lesson:
+		return &e.Version, &e.Status, &e.UpdatedAt
+	case *model.Exercise:
+		return &e.Version, &e.Status, &e.UpdatedAt
+	}
+	panic(fmt.Sprintf("workflow: unexpected content type %T", v))
+}
+
+// Transition applies action to an entity as the given role and returns every
+// status change made.
+//
+// Publishing requires the parent to be published already and each published
+// entity to pass its publishing checks. With cascade set, approved
+// descendants are published along with it. Unpublishing and archiving always
+// carry down to descendants, since nothing may stay live under content that
+// is not. Reopening archived content requires a parent that is not archived.
+func (s *WorkflowService) Transition(
+	ctx context.Context,
+	entityType model.EntityType,
+	id uuid.UUID,
+	action workflow.Action,
+	role string,
+	cascade bool,
+) ([]model.StatusChange, error) {
+	t, err := workflow.Lookup(action)
+	if err != nil {
+		return nil, err
+	}
+
+	courseID, err := s.courseOf(ctx, entityType, id)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := s.loadCourse(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	node, ok := nodes[id]
+	if !ok || node.entity != entityType {
+		return nil, fmt.Errorf("%s not found", entityType)
+	}
+
+	if err := t.Check(node.status(), role); err != nil {
+		return nil, err
+	}
+	// The parent a transition depends on; locked below so it cannot change
+	// before the transition commits
+	var gate *contentNode
+	if p := node.parent; p != nil {
+		switch {
+		case t.To == model.StatePublished && p.status() != model.StatePublished:
+			return nil, fmt.Errorf("%w: %s '%s' is %s, not published",
+				workflow.ErrBlocked, p.entity, p.title, p.status())
+		case t.Action == workflow.ActionReopen && p.status() == model.StateArchived:
+			return nil, fmt.Errorf("%w: %s '%s' is archived", workflow.ErrBlocked, p.entity, p.title)
+		}
+		if t.To == model.StatePublished || t.Action == workflow.ActionReopen {
+			gate = p
+		}
+	}
+
+	affected := []*contentNode{node}
+	switch t.Action {
+	case workflow.ActionPublish:
+		if cascade {
+			affected = descendants(affected, node, func(n *contentNode) bool {
+				return n.status() == model.StateApproved
+			})
+		}
+	case workflow.ActionUnpublish:
+		affected = descendants(affected, node, func(n *contentNode) bool {
+			return n.status() == model.StatePublished
+		})
+	case workflow.ActionArchive:
+		affected = descendants(affected, node, func(n *contentNode) bool {
+			return n.status() != model.StateArchived
+		})
+	}
+
+	if t.To == model.StatePublished {
+		if err := s.checkPublishable(ctx, affected); err != nil {
+			return nil, err
+		}
+	}
+
+	changes := make([]model.StatusChange, 0, len(affected))
+	for _, n := range affected {
+		version, _, _ := workflowFields(n.value)
+		changes = append(changes, model.StatusChange{
+			EntityType: n.entity,
+			EntityID:   n.id,
+			From:       n.status(),
+			To:         t.To,
+			Version:    *version,
+		})
+	}
+
+	// The checks above ran on the course as loaded; the versions in changes
+	// and the lock on the parent make the transition fail with a version
+	// conflict if any of it changed since
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if gate != nil {
+			version, _, _ := workflowFields(gate.value)
+			if err := s.status.LockStatus(ctx, gate.entity, gate.id, gate.status(), *version); err != nil {
+				return err
+			}
+		}
+
+		// Keep the versions being replaced, in case they predate revision history
+		for _, n := range affected {
+			version, _, updatedAt := workflowFields(n.value)
+			snapshot, err := revisionSnapshot(ctx, s.options, n.value)
+			if err != nil {
+				return err
+			}
+			if err := recordRevision(ctx, s.revisions, n.entity, n.id, *version, snapshot,
+				nil, *updatedAt); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now().UTC()
+		if err := s.status.SetStatus(ctx, changes, now); err != nil {
+			return err
+		}
+
+		for _, n := range affected {
+			version, status, updatedAt := workflowFields(n.value)
+			*version++
+			*status = t.To
+			*updatedAt = now
+			if c, ok := n.value.(*model.Course); ok {
+				c.IsPublished = t.To == model.StatePublished
+			}
+			snapshot, err := revisionSnapshot(ctx, s.options, n.value)
+			if err != nil {
+				return err
+			}
+			if err := recordRevision(ctx, s.revisions, n.entity, n.id, *version, snapshot,
+				currentUserID(ctx), now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// ScheduleCourse sets when a course is to be published and unpublished;
+// nil clears either time. The current user is recorded as the scheduler of
+// each time that changes.
+func (s *WorkflowService) ScheduleCourse(
+	ctx context.Context,
+	courseID uuid.UUID,
+	publishAt, unpublishAt *time.Time,
+) (*model.Course, error) {
+	course, err := s.courses.GetByID(ctx, courseID, false)
+	if err != nil {
+		return nil, fmt.Errorf("course not found: %w", err)
+	}
+
+	if publishAt != nil && unpublishAt != nil && !unpublishAt.After(*publishAt) {
+		var errs validation.Errors
+		errs.Add("unpublish_at", "must be after publish_at")
+		return nil, errs
+	}
+
+	by := currentUserID(ctx)
+	if !sameTime(course.PublishAt, publishAt) {
+		course.PublishAt, course.PublishScheduledBy = publishAt, by
+	}
+	if !sameTime(course.UnpublishAt, unpublishAt) {
+		course.UnpublishAt, course.UnpublishScheduledBy = unpublishAt, by
+	}
+	if publishAt == nil {
+		course.PublishScheduledBy = nil
+	}
+	if unpublishAt == nil {
+		course.UnpublishScheduledBy = nil
+	}
+
+	if err := s.courses.SetSchedule(ctx, course); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("course not found: %w", err)
+		}
+		return nil, err
+	}
+	return course, nil
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// descendants appends the descendants of n that match to list. The walk does
+// not continue below an entity that does not match.
+func descendants(
+	list []*contentNode,
+	n *contentNode,
+	match func(*contentNode) bool,
+) []*contentNode {
+	for _, child := range n.children {
+		if match(child) {
+			list = append(list, child)
+			list = descendants(list, child, match)
+		}
+	}
+	return list
+}
+
+// checkPublishable runs the publishing checks on every node: containers must
+// not be empty, a lesson's TotalExercises must match its exercises, and
+// exercises must pass their type's validation. Only children that will be
+// live count, i.e. those already published and those among nodes.
+func (s *WorkflowService) checkPublishable(ctx context.Context, nodes []*contentNode) error {
+	publishing := make(map[uuid.UUID]bool, len(nodes))
+	for _, n := range nodes {
+		publishing[n.id] = true
+	}
+	live := func(n *contentNode) int {
+		count := 0
+		for _, child := range n.children {
+			if publishing[child.id] || child.status() == model.StatePublished {
+				count++
+			}
+		}
+		return count
+	}
+
+	var errs validation.Errors
+	for _, n := range nodes {
+		field := fmt.Sprintf("%s.%s", n.entity, n.id)
+		switch v := n.value.(type) {
+		case *model.Course, *model.Unit, *model.Skill:
+			if live(n) == 0 {
+				errs.Add(field, "%s '%s' has no published %ss", n.entity, n.title, childEntity[n.entity])
+			}
+		case *model.Lesson:
+			if count := live(n); count == 0 {
+				errs.Add(field, "lesson '%s' must have at least one published exercise", n.title)
+			} else if v.TotalExercises != count {
+				errs.Add(field, "lesson '%s' has total_exercises %d but %d published exercises",
+					n.title, v.TotalExercises, count)
+			}
+		case *model.Exercise:
+			options, err := s.options.ListByExerciseID(ctx, v.ID)
+			if err != nil {
+				return err
+			}
+			values := make([]model.ExerciseOption, 0, len(options))
+			for _, o := range options {
+				values = append(values, *o)
+			}
+			if err := validation.Validate(v, values); err != nil {
+				errs.Add(field, "exercise '%s' is invalid: %v", n.title, err)
+			}
+		}
+	}
+	return errs.Err()
+}
+
+// childEntity names the kind of content directly beneath each container.
+var childEntity = map[model.EntityType]model.EntityType{
+	model.EntityCourse: model.EntityUnit,
+	model.EntityUnit:   model.EntitySkill,
+	model.EntitySkill:  model.EntityLesson,
+	model.EntityLesson: model.EntityExercise,
+}
+
+// courseOf finds the course an entity belongs to.
+func (s *WorkflowService) courseOf(
+	ctx context.Context,
+	entityType model.EntityType,
+	id uuid.UUID,
+) (uuid.UUID, error) {
+	notFound := func(err error) (uuid.UUID, error) {
+		return uuid.Nil, fmt.Errorf("%s not found: %w", entityType, err)
+	}
+	skillCourse := func(skillID uuid.UUID) (uuid.UUID, error) {
+		skill, err := s.skills.GetByID(ctx, skillID, false)
+		if err != nil {
+			return notFound(err)
+		}
+		return skill.CourseID, nil
+	}
+
+	switch entityType {
+	case model.EntityCourse:
+		return id, nil
+	case model.EntityUnit:
+		unit, err := s.units.GetByID(ctx, id, false)
+		if err != nil {
+			return notFound(err)
+		}
+		return unit.CourseID, nil
+	case model.EntitySkill:
+		return skillCourse(id)
+	case model.EntityLesson:
+		lesson, err := s.lessons.GetByID(ctx, id, false)
+		if err != nil {
+			return notFound(err)
+		}
+		return skillCourse(lesson.SkillID)
+	case model.EntityExercise:
+		exercise, err := s.exercises.GetByID(ctx, id, false)
+		if err != nil {
+			return notFound(err)
+		}
+		return skillCourse(exercise.SkillID)
+	}
+	return uuid.Nil, fmt.Errorf("unknown entity type %q", entityType)
+}
+
+// loadCourse reads a course and all its live content, keyed by ID.
+func (s *WorkflowService) loadCourse(
+	ctx context.Context,
+	courseID uuid.UUID,
+) (map[uuid.UUID]*contentNode, error) {
+	course, err := s.courses.GetByID(ctx, courseID, false)
+	if err != nil {
+		return nil, fmt.Errorf("course not found: %w", err)
+	}
+	units, err := s.units.ListByCourseID(ctx, courseID, false)
+	if err != nil {
+		return nil, err
+	}
+	skills, err := s.skills.ListByCourseID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	lessons, err := s.lessons.ListByCourseID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	exercises, err := s.exercises.ListByCourseID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := map[uuid.UUID]*contentNode{}
+	add := func(entity model.EntityType, id uuid.UUID, title string, value any, parentID uuid.UUID) {
+		n := &contentNode{entity: entity, id: id, title: title, value: value}
+		if parent, ok := nodes[parentID]; ok {
+			n.parent = parent
+			parent.children = append(parent.children, n)
+		}
+		nodes[id] = n
+	}
+
+	add(model.EntityCourse, course.ID, course.Title, course, uuid.Nil)
+	for _, u := range units {
+		add(model.EntityUnit, u.ID, u.Title, u, u.CourseID)
+	}
+	for _, sk := range skills {
+		add(model.EntitySkill, sk.ID, sk.Title, sk, sk.UnitID)
+	}
+	for _, l := range lessons {
+		add(model.EntityLesson, l.ID, l.Title, l, l.SkillID)
+	}
+	for _, e := range exercises {
+		add(model.EntityExercise, e.ID, e.Title, e, e.LessonID)
+	}
+	return nodes, nil
+}
@@ -0,0 +1,82 @@
+package skillgraph
+
+import (
+	"slices"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/google/uuid"
+)
+
+// Analyze works out the unlock order, depths, unreachable and orphaned skills
+// of the graph. Prerequisites outside the graph count as missing, so the
+// skills that need them are unreachable.
+func (g *Graph) Analyze(courseID uuid.UUID) *model.SkillGraph {
+	sg := &model.SkillGraph{CourseID: courseID}
+
+	dependents := make(map[uuid.UUID][]uuid.UUID, len(g.order))
+	nodes := make(map[uuid.UUID]*model.SkillGraphNode, len(g.order))
+	for _, id := range g.order {
+		node := &model.SkillGraphNode{Skill: g.skills[id]}
+		for _, p := range node.Skill.PrerequisiteSkillIDs {
+			if _, ok := g.skills[p]; !ok && !slices.Contains(node.MissingPrerequisiteIDs, p) {
+				node.MissingPrerequisiteIDs = append(node.MissingPrerequisiteIDs, p)
+			}
+		}
+		for _, p := range g.prereq[id] {
+			dependents[p] = append(dependents[p], id)
+			sg.Edges = append(sg.Edges, model.SkillGraphEdge{From: p, To: id})
+		}
+		nodes[id] = node
+		sg.Nodes = append(sg.Nodes, node)
+	}
+
+	// Kahn's algorithm: a skill is unlocked once all its prerequisites are.
+	// Skills with missing prerequisites never are, nor is anything after them.
+	remaining := make(map[uuid.UUID]int, len(g.order))
+	var queue []uuid.UUID
+	for _, id := range g.order {
+		remaining[id] = len(g.prereq[id])
+		if remaining[id] == 0 && len(nodes[id].MissingPrerequisiteIDs) == 0 {
+			queue = append(queue, id)
+		}
+	}
+	depth := make(map[uuid.UUID]int, len(g.order))
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		d := 0
+		for _, p := range g.prereq[id] {
+			d = max(d, depth[p]+1)
+		}
+		depth[id] = d
+		nodes[id].Depth = &d
+
+		for _, next := range dependents[id] {
+			remaining[next]--
+			if remaining[next] == 0 && len(nodes[next].MissingPrerequisiteIDs) == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	for _, id := range g.order {
+		node := nodes[id]
+		if node.Depth != nil {
+			sg.UnlockOrder = append(sg.UnlockOrder, id)
+		} else {
+			sg.Unreachable = append(sg.Unreachable, id)
+		}
+		if len(g.order) > 1 && len(node.Skill.PrerequisiteSkillIDs) == 0 && len(dependents[id]) == 0 {
+			node.Orphaned = true
+			sg.Orphaned = append(sg.Orphaned, id)
+		}
+	}
+
+	// Shallower skills first, course order within a level; every prerequisite
+	// is strictly shallower than the skill it unlocks, so this is topological.
+	slices.SortStableFunc(sg.UnlockOrder, func(a, b uuid.UUID) int {
+		return depth[a] - depth[b]
+	})
+	return sg
+}
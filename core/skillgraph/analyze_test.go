@@ -0,0 +1,139 @@
+package skillgraph
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name        string
+		specs       []string
+		unlockOrder []string
+		depths      map[string]int
+		unreachable []string
+		orphaned    []string
+		missing     map[string][]uuid.UUID
+	}{
+		{
+			name:        "chain",
+			specs:       []string{"A", "B: A", "C: B"},
+			unlockOrder: []string{"A", "B", "C"},
+			depths:      map[string]int{"A": 0, "B": 1, "C": 2},
+		},
+		{
+			name:        "diamond in reverse course order",
+			specs:       []string{"D: B C", "C: A", "B: A", "A"},
+			unlockOrder: []string{"A", "C", "B", "D"},
+			depths:      map[string]int{"A": 0, "B": 1, "C": 1, "D": 2},
+		},
+		{
+			name:        "longest chain sets the depth",
+			specs:       []string{"A", "B: A", "C: A B"},
+			unlockOrder: []string{"A", "B", "C"},
+			depths:      map[string]int{"C": 2},
+		},
+		{
+			name:        "cycle and what follows it",
+			specs:       []string{"A", "B: A C", "C: B", "D: C"},
+			unlockOrder: []string{"A"},
+			unreachable: []string{"B", "C", "D"},
+		},
+		{
+			name:        "missing prerequisite",
+			specs:       []string{"A: X", "B: A", "C"},
+			unlockOrder: []string{"C"},
+			unreachable: []string{"A", "B"},
+			orphaned:    []string{"C"},
+			missing:     map[string][]uuid.UUID{"A": {id("X")}},
+		},
+		{
+			name:        "missing prerequisite listed twice",
+			specs:       []string{"A: X X"},
+			unreachable: []string{"A"},
+			missing:     map[string][]uuid.UUID{"A": {id("X")}},
+		},
+		{
+			name:        "a lone skill is not orphaned",
+			specs:       []string{"A"},
+			unlockOrder: []string{"A"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New(course(tt.specs...))
+			sg := g.Analyze(id("course"))
+
+			if got := names(g, sg.UnlockOrder); !slices.Equal(got, nonNil(tt.unlockOrder)) {
+				t.Errorf("UnlockOrder = %v, want %v", got, tt.unlockOrder)
+			}
+			if got := names(g, sg.Unreachable); !slices.Equal(got, nonNil(tt.unreachable)) {
+				t.Errorf("Unreachable = %v, want %v", got, tt.unreachable)
+			}
+			if got := names(g, sg.Orphaned); !slices.Equal(got, nonNil(tt.orphaned)) {
+				t.Errorf("Orphaned = %v, want %v", got, tt.orphaned)
+			}
+			for _, n := range sg.Nodes {
+				name := n.Skill.Title
+				if want, ok := tt.depths[name]; ok && (n.Depth == nil || *n.Depth != want) {
+					t.Errorf("depth of %s = %v, want %d", name, n.Depth, want)
+				}
+				if !slices.Equal(n.MissingPrerequisiteIDs, tt.missing[name]) {
+					t.Errorf("missing prerequisites of %s = %v, want %v",
+						name, n.MissingPrerequisiteIDs, tt.missing[name])
+				}
+				if unreachable := slices.Contains(tt.unreachable, name); unreachable != (n.Depth == nil) {
+					t.Errorf("depth of %s = %v, unreachable %v", name, n.Depth, unreachable)
+				}
+			}
+		})
+	}
+}
+
+// nonNil turns a nil list into an empty one, as names returns.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func TestRender(t *testing.T) {
+	g := New(course(`"hi"`, `B: "hi" X`, "C"))
+	sg := g.Analyze(id("course"))
+
+	tests := []struct {
+		name   string
+		render func() string
+		want   []string
+	}{
+		{"DOT", func() string { return DOT(sg) }, []string{
+			`[label="\"hi\""]`,
+			`"` + id("B").String() + `" [label="B", style="rounded,dashed", color=red];`,
+			`"` + id("C").String() + `" [label="C", color=gray];`,
+			`"` + id("X").String() + `" [label="missing skill", style=dashed, color=red];`,
+			`"` + id(`"hi"`).String() + `" -> "` + id("B").String() + `";`,
+		}},
+		{"Mermaid", func() string { return Mermaid(sg) }, []string{
+			`s1["#quot;hi#quot;"]`,
+			`m1["missing skill"]`,
+			"s1 --> s2",
+			"m1 -.-> s2",
+			"class s2,m1 unreachable",
+			"class s3 orphaned",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := tt.render()
+			for _, w := range tt.want {
+				if !strings.Contains(out, w) {
+					t.Errorf("output lacks %s:\n%s", w, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Package skillgraph models the prerequisite relationships between the skills
+// of a course.
+package skillgraph
+
+import (
+	"slices"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/google/uuid"
+)
+
+// Graph is a directed graph with an edge from each skill to each of its
+// prerequisites. Edges to skills outside the graph are ignored.
+type Graph struct {
+	skills map[uuid.UUID]*model.Skill
+	order  []uuid.UUID // skills in the order given, for deterministic output
+	prereq map[uuid.UUID][]uuid.UUID
+}
+
+// New builds the graph of the given skills.
+func New(skills []*model.Skill) *Graph {
+	g := &Graph{
+		skills: make(map[uuid.UUID]*model.Skill, len(skills)),
+		prereq: make(map[uuid.UUID][]uuid.UUID, len(skills)),
+	}
+	for _, s := range skills {
+		if _, dup := g.skills[s.ID]; !dup {
+			g.order = append(g.order, s.ID)
+		}
+		g.skills[s.ID] = s
+	}
+	for _, id := range g.order {
+		for _, p := range g.skills[id].PrerequisiteSkillIDs {
+			if _, ok := g.skills[p]; ok && !slices.Contains(g.prereq[id], p) {
+				g.prereq[id] = append(g.prereq[id], p)
+			}
+		}
+	}
+	return g
+}
+
+// Skill returns the skill with the given ID, or nil.
+func (g *Graph) Skill(id uuid.UUID) *model.Skill {
+	return g.skills[id]
+}
+
+// CycleFrom returns a prerequisite cycle reachable from the given skill as
+// the list of skills along it, starting and ending with the same skill
+// (e.g. A → B → C → A). It returns nil when there is none.
+func (g *Graph) CycleFrom(start uuid.UUID) []uuid.UUID {
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+	state := map[uuid.UUID]int{}
+	var path []uuid.UUID
+
+	var visit func(id uuid.UUID) []uuid.UUID
+	visit = func(id uuid.UUID) []uuid.UUID {
+		state[id] = onPath
+		path = append(path, id)
+		for _, next := range g.prereq[id] {
+			switch state[next] {
+			case onPath:
+				// The cycle is the part of the path from next onwards
+				for i, p := range path {
+					if p == next {
+						cycle := append([]uuid.UUID{}, path[i:]...)
+						return append(cycle, next)
+					}
+				}
+			case unvisited:
+				if cycle := visit(next); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = done
+		return nil
+	}
+
+	if _, ok := g.skills[start]; !ok {
+		return nil
+	}
+	return visit(start)
+}
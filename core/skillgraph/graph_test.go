@@ -0,0 +1,83 @@
+package skillgraph
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/google/uuid"
+)
+
+// id gives each skill name a fixed ID.
+func id(name string) uuid.UUID {
+	return uuid.NewMD5(uuid.Nil, []byte(name))
+}
+
+// course builds skills from specs like "C: A B", a skill C requiring A and B.
+func course(specs ...string) []*model.Skill {
+	skills := make([]*model.Skill, 0, len(specs))
+	for _, spec := range specs {
+		name, prereqs, _ := strings.Cut(spec, ":")
+		s := &model.Skill{ID: id(name), Title: name}
+		for _, p := range strings.Fields(prereqs) {
+			s.PrerequisiteSkillIDs = append(s.PrerequisiteSkillIDs, id(p))
+		}
+		skills = append(skills, s)
+	}
+	return skills
+}
+
+// names maps IDs back to the skill names of a graph, "?" for unknown ones.
+func names(g *Graph, ids []uuid.UUID) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = "?"
+		if s := g.Skill(id); s != nil {
+			out[i] = s.Title
+		}
+	}
+	return out
+}
+
+func TestCycleFrom(t *testing.T) {
+	tests := []struct {
+		name   string
+		skills []*model.Skill
+		start  string
+		want   []string
+	}{
+		{"no prerequisites", course("A", "B"), "A", nil},
+		{"chain", course("A", "B: A", "C: B"), "C", nil},
+		{"diamond", course("A", "B: A", "C: A", "D: B C"), "D", nil},
+		{"self", course("A: A"), "A", []string{"A", "A"}},
+		{"pair", course("A: B", "B: A"), "A", []string{"A", "B", "A"}},
+		{"further along", course("A: B", "B: C", "C: D", "D: B"), "A", []string{"B", "C", "D", "B"}},
+		{"not reachable from start", course("A", "B: C", "C: B"), "A", nil},
+		{"missing prerequisite", course("A: X"), "A", nil},
+		{"repeated prerequisite", course("A", "B: A A"), "B", nil},
+		{"unknown start", course("A: A"), "X", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New(tt.skills)
+			if got := names(g, g.CycleFrom(id(tt.start))); !slices.Equal(got, tt.want) {
+				t.Errorf("CycleFrom(%s) = %v, want %v", tt.start, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewKeepsLastDuplicate(t *testing.T) {
+	skills := course("A", "B", "A: B")
+	g := New(skills)
+	if g.Skill(id("A")) != skills[2] {
+		t.Error("Skill(A) is not the last skill given as A")
+	}
+	if got := names(g, g.order); !slices.Equal(got, []string{"A", "B"}) {
+		t.Errorf("order = %v, want [A B]", got)
+	}
+	if got := names(g, g.prereq[id("A")]); !slices.Equal(got, []string{"B"}) {
+		t.Errorf("prerequisites of A = %v, want [B]", got)
+	}
+}
@@ -0,0 +1,113 @@
+package skillgraph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+	"github.com/google/uuid"
+)
+
+// DOT renders the graph in Graphviz DOT, with edges pointing from each
+// prerequisite to the skill it unlocks. Unreachable skills are drawn dashed
+// red, orphaned ones grey, and missing prerequisites as placeholder nodes.
+func DOT(sg *model.SkillGraph) string {
+	var b strings.Builder
+	b.WriteString("digraph skills {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=rounded];\n")
+
+	missing := missingPrerequisites(sg)
+	for _, n := range sg.Nodes {
+		attrs := "label=" + strconv.Quote(n.Skill.Title)
+		switch {
+		case n.Depth == nil:
+			attrs += `, style="rounded,dashed", color=red`
+		case n.Orphaned:
+			attrs += ", color=gray"
+		}
+		fmt.Fprintf(&b, "  %q [%s];\n", n.Skill.ID.String(), attrs)
+	}
+	for _, id := range missing {
+		fmt.Fprintf(&b, "  %q [label=\"missing skill\", style=dashed, color=red];\n", id.String())
+	}
+
+	for _, e := range sg.Edges {
+		fmt.Fprintf(&b, "  %q -> %q;\n", e.From.String(), e.To.String())
+	}
+	for _, n := range sg.Nodes {
+		for _, p := range n.MissingPrerequisiteIDs {
+			fmt.Fprintf(&b, "  %q -> %q [style=dashed, color=red];\n", p.String(), n.Skill.ID.String())
+		}
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart, styled like DOT. Node
+// IDs are short aliases since Mermaid IDs cannot be quoted.
+func Mermaid(sg *model.SkillGraph) string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+
+	alias := make(map[uuid.UUID]string, len(sg.Nodes))
+	var unreachable, orphaned []string
+	for i, n := range sg.Nodes {
+		a := fmt.Sprintf("s%d", i+1)
+		alias[n.Skill.ID] = a
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", a, mermaidLabel(n.Skill.Title))
+		switch {
+		case n.Depth == nil:
+			unreachable = append(unreachable, a)
+		case n.Orphaned:
+			orphaned = append(orphaned, a)
+		}
+	}
+	for i, id := range missingPrerequisites(sg) {
+		a := fmt.Sprintf("m%d", i+1)
+		alias[id] = a
+		fmt.Fprintf(&b, "  %s[\"missing skill\"]\n", a)
+		unreachable = append(unreachable, a)
+	}
+
+	for _, e := range sg.Edges {
+		fmt.Fprintf(&b, "  %s --> %s\n", alias[e.From], alias[e.To])
+	}
+	for _, n := range sg.Nodes {
+		for _, p := range n.MissingPrerequisiteIDs {
+			fmt.Fprintf(&b, "  %s -.-> %s\n", alias[p], alias[n.Skill.ID])
+		}
+	}
+
+	if len(unreachable) > 0 {
+		b.WriteString("  classDef unreachable stroke:#d33,stroke-dasharray:4 4\n")
+		fmt.Fprintf(&b, "  class %s unreachable\n", strings.Join(unreachable, ","))
+	}
+	if len(orphaned) > 0 {
+		b.WriteString("  classDef orphaned stroke:#999,color:#999\n")
+		fmt.Fprintf(&b, "  class %s orphaned\n", strings.Join(orphaned, ","))
+	}
+	return b.String()
+}
+
+// missingPrerequisites lists each missing prerequisite once, in node order.
+func missingPrerequisites(sg *model.SkillGraph) []uuid.UUID {
+	seen := map[uuid.UUID]bool{}
+	var ids []uuid.UUID
+	for _, n := range sg.Nodes {
+		for _, p := range n.MissingPrerequisiteIDs {
+			if !seen[p] {
+				seen[p] = true
+				ids = append(ids, p)
+			}
+		}
+	}
+	return ids
+}
+
+// mermaidLabel escapes the characters that would end a quoted Mermaid label.
+func mermaidLabel(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(s)
+}
@@ -0,0 +1,304 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+)
+
+// Limits on the number of options a choice-based exercise may carry.
+const (
+	MinChoiceOptions = 2
+	MaxChoiceOptions = 8
+	MinMatchingPairs = 2
+	MaxMatchingPairs = 8
+)
+
+// matchingSides lists the media kind expected on the left and right side of
+// each pair. An empty kind means the side is plain text (the option label).
+var matchingSides = map[model.MatchingType][2]string{
+	model.MatchTextToText:   {"", ""},
+	model.MatchImageToText:  {MediaImage, ""},
+	model.MatchAudioToText:  {MediaAudio, ""},
+	model.MatchTextToAudio:  {"", MediaAudio},
+	model.MatchImageToImage: {MediaImage, MediaImage},
+	model.MatchAudioToAudio: {MediaAudio, MediaAudio},
+}
+
+func init() {
+	Register(model.ExerciseMultipleChoice, multipleChoiceValidator{})
+	Register(model.ExerciseAudioRecognition, audioRecognitionValidator{})
+	Register(model.ExercisePlayback, playbackValidator{})
+	Register(model.ExerciseFillInTheBlank, fillInTheBlankValidator{})
+	Register(model.ExerciseMatching, matchingValidator{})
+	Register(model.ExerciseTyping, typingValidator{})
+}
+
+// AllowsMultiple reports whether a choice exercise accepts several correct options.
+func AllowsMultiple(e *model.Exercise) bool {
+	v, _ := e.Metadata["allow_multiple"].(bool)
+	return v
+}
+
+// multiple_choice: optional image/audio stimulus, 2–8 options with at least one
+// correct answer (exactly one unless metadata.allow_multiple is true).
+type multipleChoiceValidator struct{}
+
+func (multipleChoiceValidator) ValidateExercise(e *model.Exercise) Errors {
+	var errs Errors
+	checkOptionalBool(&errs, e.Metadata, "allow_multiple")
+	checkOptionalBool(&errs, e.Metadata, "shuffle")
+	if e.MediaURL != nil && *e.MediaURL != "" && MediaKindOf(*e.MediaURL) == "" {
+		errs.Add("media_url", "must be an audio or image file")
+	}
+	return errs
+}
+
+func (multipleChoiceValidator) ValidateOptions(
+	e *model.Exercise,
+	opts []model.ExerciseOption,
+) Errors {
+	return validateChoiceOptions(opts, AllowsMultiple(e))
+}
+
+// audio_recognition: an audio clip the learner identifies from 2–8 options.
+type audioRecognitionValidator struct{}
+
+func (audioRecognitionValidator) ValidateExercise(e *model.Exercise) Errors {
+	var errs Errors
+	checkMedia(&errs, "media_url", e.MediaURL, MediaAudio)
+	checkOptionalBool(&errs, e.Metadata, "allow_multiple")
+	checkOptionalNonNegative(&errs, e.Metadata, "max_plays")
+	return errs
+}
+
+func (audioRecognitionValidator) ValidateOptions(
+	e *model.Exercise,
+	opts []model.ExerciseOption,
+) Errors {
+	return validateChoiceOptions(opts, AllowsMultiple(e))
+}
+
+// playback: the learner reproduces an audio clip; metadata.expected_sequence
+// holds the notes or beats to be played back.
+type playbackValidator struct{}
+
+func (playbackValidator) ValidateExercise(e *model.Exercise) Errors {
+	var errs Errors
+	checkMedia(&errs, "media_url", e.MediaURL, MediaAudio)
+
+	seq, ok := StringList(e.Metadata["expected_sequence"])
+	switch {
+	case !ok:
+		errs.Add("metadata.expected_sequence", "must be a list of strings")
+	case len(seq) == 0:
+		errs.Add("metadata.expected_sequence", "must not be empty")
+	}
+	checkOptionalNonNegative(&errs, e.Metadata, "tolerance_ms")
+	return errs
+}
+
+func (playbackValidator) ValidateOptions(_ *model.Exercise, opts []model.ExerciseOption) Errors {
+	return rejectOptions(opts)
+}
+
+// fill_in_the_blank: the prompt holds one "___" per blank and metadata.answers
+// holds the accepted answer (or list of alternatives) for each blank. Options
+// are an optional word bank.
+type fillInTheBlankValidator struct{}
+
+func (fillInTheBlankValidator) ValidateExercise(e *model.Exercise) Errors {
+	var errs Errors
+
+	blanks := BlankCount(e.Prompt)
+	if blanks == 0 {
+		errs.Add("prompt", "must contain at least one %q blank", BlankMarker)
+	}
+
+	answers, ok := BlankAnswers(e.Metadata["answers"])
+	switch {
+	case !ok:
+		errs.Add("metadata.answers", "must be a list of strings or lists of strings")
+	case blanks > 0 && len(answers) != blanks:
+		errs.Add("metadata.answers", "must have %d entries (one per blank), got %d",
+			blanks, len(answers))
+	default:
+		for i, alts := range answers {
+			if len(alts) == 0 || hasBlankString(alts) {
+				errs.Add(fmt.Sprintf("metadata.answers[%d]", i), "must not be empty")
+			}
+		}
+	}
+	checkOptionalBool(&errs, e.Metadata, "case_sensitive")
+	return errs
+}
+
+func (fillInTheBlankValidator) ValidateOptions(
+	_ *model.Exercise,
+	opts []model.ExerciseOption,
+) Errors {
+	var errs Errors
+	for i, o := range opts {
+		if strings.TrimSpace(o.Label) == "" {
+			errs.Add(fmt.Sprintf("options[%d].label", i), "is required")
+		}
+	}
+	return errs
+}
+
+// matching: options come in pairs sharing a Value, with media on each side as
+// dictated by the exercise's MatchingType.
+type matchingValidator struct{}
+
+func (matchingValidator) ValidateExercise(e *model.Exercise) Errors {
+	var errs Errors
+	if e.MatchingType == nil {
+		errs.Add("matching_type", "is required for matching exercises")
+	} else if _, ok := matchingSides[*e.MatchingType]; !ok {
+		errs.Add("matching_type", "unsupported matching type %q", *e.MatchingType)
+	}
+	checkOptionalBool(&errs, e.Metadata, "shuffle")
+	return errs
+}
+
+func (matchingValidator) ValidateOptions(
+	e *model.Exercise,
+	opts []model.ExerciseOption,
+) Errors {
+	pairs, errs := PairOptions(opts)
+	if len(pairs) < MinMatchingPairs || len(pairs) > MaxMatchingPairs {
+		errs.Add("options", "must form between %d and %d pairs, got %d",
+			MinMatchingPairs, MaxMatchingPairs, len(pairs))
+	}
+
+	if e.MatchingType == nil {
+		return errs
+	}
+	sides, ok := matchingSides[*e.MatchingType]
+	if !ok {
+		return errs
+	}
+
+	for _, p := range pairs {
+		checkMatchingSide(&errs, p.LeftIndex, p.Left, sides[0])
+		checkMatchingSide(&errs, p.RightIndex, p.Right, sides[1])
+	}
+	return errs
+}
+
+// typing: free text answer compared against metadata.accepted_answers.
+type typingValidator struct{}
+
+func (typingValidator) ValidateExercise(e *model.Exercise) Errors {
+	var errs Errors
+
+	accepted, ok := StringList(e.Metadata["accepted_answers"])
+	switch {
+	case !ok:
+		errs.Add("metadata.accepted_answers", "must be a list of strings")
+	case len(accepted) == 0:
+		errs.Add("metadata.accepted_answers", "must not be empty")
+	case hasBlankString(accepted):
+		errs.Add("metadata.accepted_answers", "must not contain empty answers")
+	}
+	checkOptionalBool(&errs, e.Metadata, "case_sensitive")
+	return errs
+}
+
+func (typingValidator) ValidateOptions(_ *model.Exercise, opts []model.ExerciseOption) Errors {
+	return rejectOptions(opts)
+}
+
+func validateChoiceOptions(opts []model.ExerciseOption, allowMultiple bool) Errors {
+	var errs Errors
+
+	if len(opts) < MinChoiceOptions || len(opts) > MaxChoiceOptions {
+		errs.Add("options", "must have between %d and %d options, got %d",
+			MinChoiceOptions, MaxChoiceOptions, len(opts))
+	}
+
+	seen := map[string]bool{}
+	correct := 0
+	for i, o := range opts {
+		field := fmt.Sprintf("options[%d]", i)
+		if strings.TrimSpace(o.Label) == "" && (o.MediaURL == nil || *o.MediaURL == "") {
+			errs.Add(field+".label", "is required when the option has no media")
+		}
+		if strings.TrimSpace(o.Value) == "" {
+			errs.Add(field+".value", "is required")
+		} else if seen[o.Value] {
+			errs.Add(field+".value", "duplicate value %q", o.Value)
+		}
+		seen[o.Value] = true
+		if o.MediaURL != nil && *o.MediaURL != "" && MediaKindOf(*o.MediaURL) == "" {
+			errs.Add(field+".media_url", "must be an audio or image file")
+		}
+		if o.IsCorrect {
+			correct++
+		}
+	}
+
+	switch {
+	case correct == 0:
+		errs.Add("options", "at least one option must be correct")
+	case !allowMultiple && correct > 1:
+		errs.Add("options", "exactly one option must be correct unless metadata.allow_multiple is set")
+	}
+	return errs
+}
+
+func checkMatchingSide(errs *Errors, i int, o model.ExerciseOption, kind string) {
+	field := fmt.Sprintf("options[%d]", i)
+	if kind == "" {
+		if strings.TrimSpace(o.Label) == "" {
+			errs.Add(field+".label", "is required for a text side")
+		}
+		return
+	}
+	checkMedia(errs, field+".media_url", o.MediaURL, kind)
+}
+
+func checkMedia(errs *Errors, field string, mediaURL *string, kind string) {
+	if mediaURL == nil || strings.TrimSpace(*mediaURL) == "" {
+		errs.Add(field, "%s media is required", kind)
+		return
+	}
+	if got := MediaKindOf(*mediaURL); got != kind {
+		errs.Add(field, "must be an %s file", kind)
+	}
+}
+
+func checkOptionalBool(errs *Errors, meta model.JSONB, key string) {
+	if v, ok := meta[key]; ok && v != nil {
+		if _, ok := v.(bool); !ok {
+			errs.Add("metadata."+key, "must be a boolean")
+		}
+	}
+}
+
+func checkOptionalNonNegative(errs *Errors, meta model.JSONB, key string) {
+	if v, ok := meta[key]; ok && v != nil {
+		n, ok := v.(float64)
+		if !ok || n < 0 {
+			errs.Add("metadata."+key, "must be a non-negative number")
+		}
+	}
+}
+
+func rejectOptions(opts []model.ExerciseOption) Errors {
+	var errs Errors
+	if len(opts) > 0 {
+		errs.Add("options", "this exercise type does not use options")
+	}
+	return errs
+}
+
+func hasBlankString(list []string) bool {
+	for _, s := range list {
+		if strings.TrimSpace(s) == "" {
+			return true
+		}
+	}
+	return false
+}
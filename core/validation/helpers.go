@@ -0,0 +1,146 @@
+package validation
+
+import (
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+)
+
+// MediaKind values returned by MediaKindOf.
+const (
+	MediaAudio = "audio"
+	MediaImage = "image"
+)
+
+var mediaExtensions = map[string]string{
+	".mp3":  MediaAudio,
+	".wav":  MediaAudio,
+	".ogg":  MediaAudio,
+	".oga":  MediaAudio,
+	".m4a":  MediaAudio,
+	".aac":  MediaAudio,
+	".flac": MediaAudio,
+	".png":  MediaImage,
+	".jpg":  MediaImage,
+	".jpeg": MediaImage,
+	".gif":  MediaImage,
+	".webp": MediaImage,
+	".svg":  MediaImage,
+}
+
+// MediaKindOf guesses whether a media URL points at audio or an image from its
+// file extension. It returns an empty string when the kind is unknown.
+func MediaKindOf(mediaURL string) string {
+	p := mediaURL
+	if u, err := url.Parse(mediaURL); err == nil {
+		p = u.Path
+	}
+	return mediaExtensions[strings.ToLower(path.Ext(p))]
+}
+
+// StringList reads a JSON array of strings out of decoded metadata.
+func StringList(v any) ([]string, bool) {
+	switch list := v.(type) {
+	case []string:
+		return list, true
+	case []any:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, s)
+		}
+		return out, true
+	default:
+		return nil, false
+	}
+}
+
+// BlankAnswers reads metadata.answers for fill-in-the-blank exercises. Each
+// entry is either a single accepted string or a list of accepted alternatives.
+func BlankAnswers(v any) ([][]string, bool) {
+	list, ok := v.([]any)
+	if !ok {
+		if strs, ok := v.([]string); ok {
+			out := make([][]string, 0, len(strs))
+			for _, s := range strs {
+				out = append(out, []string{s})
+			}
+			return out, true
+		}
+		return nil, false
+	}
+
+	out := make([][]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			out = append(out, []string{s})
+			continue
+		}
+		alts, ok := StringList(item)
+		if !ok {
+			return nil, false
+		}
+		out = append(out, alts)
+	}
+	return out, true
+}
+
+// BlankCount returns how many blank markers ("___") appear in a prompt.
+func BlankCount(prompt string) int {
+	return strings.Count(prompt, BlankMarker)
+}
+
+// BlankMarker is the placeholder authors put in fill-in-the-blank prompts.
+const BlankMarker = "___"
+
+// OptionPair is one left/right pair of a matching exercise. LeftIndex and
+// RightIndex point back into the option slice the pair was built from.
+type OptionPair struct {
+	Key        string
+	Left       model.ExerciseOption
+	Right      model.ExerciseOption
+	LeftIndex  int
+	RightIndex int
+}
+
+// PairOptions groups matching options into pairs. Both sides of a pair share
+// the same Value; the side with the lower OrderIndex is the left (prompt) side.
+func PairOptions(opts []model.ExerciseOption) ([]OptionPair, Errors) {
+	var errs Errors
+	groups := map[string][]int{}
+	var keys []string
+
+	for i, o := range opts {
+		if _, seen := groups[o.Value]; !seen {
+			keys = append(keys, o.Value)
+		}
+		groups[o.Value] = append(groups[o.Value], i)
+	}
+
+	pairs := make([]OptionPair, 0, len(keys))
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) != 2 {
+			errs.Add("options", "pair %q must have exactly 2 options, got %d", key, len(group))
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool {
+			return opts[group[i]].OrderIndex < opts[group[j]].OrderIndex
+		})
+		pairs = append(pairs, OptionPair{
+			Key:        key,
+			Left:       opts[group[0]],
+			Right:      opts[group[1]],
+			LeftIndex:  group[0],
+			RightIndex: group[1],
+		})
+	}
+
+	return pairs, errs
+}
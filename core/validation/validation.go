@@ -0,0 +1,127 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+)
+
+// FieldError describes a single invalid field in a payload.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors is a list of field-level validation failures. It implements error so
+// it can travel through the service layer and be unwrapped by handlers.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", fe.Field, fe.Message))
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+// Add appends a field error.
+func (e *Errors) Add(field, format string, args ...any) {
+	*e = append(*e, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Err returns nil when there are no errors, so callers can `return errs.Err()`.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// ExerciseValidator enforces the rules of one ExerciseType.
+type ExerciseValidator interface {
+	// ValidateExercise checks the exercise row itself: metadata shape and media.
+	ValidateExercise(e *model.Exercise) Errors
+	// ValidateOptions checks option counts and answer keys for the exercise.
+	ValidateOptions(e *model.Exercise, opts []model.ExerciseOption) Errors
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[model.ExerciseType]ExerciseValidator{}
+)
+
+// Register installs the validator for an exercise type, replacing any existing one.
+func Register(t model.ExerciseType, v ExerciseValidator) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[t] = v
+}
+
+// Lookup returns the validator registered for an exercise type.
+func Lookup(t model.ExerciseType) (ExerciseValidator, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	v, ok := registry[t]
+	return v, ok
+}
+
+// ValidateExercise runs the common checks plus the type-specific exercise checks.
+func ValidateExercise(e *model.Exercise) error {
+	errs := validateCommon(e)
+
+	v, ok := Lookup(e.Type)
+	if !ok {
+		errs.Add("type", "unsupported exercise type %q", e.Type)
+		return errs.Err()
+	}
+
+	errs = append(errs, v.ValidateExercise(e)...)
+	return errs.Err()
+}
+
+// ValidateOptions runs the type-specific option checks for an exercise.
+func ValidateOptions(e *model.Exercise, opts []model.ExerciseOption) error {
+	v, ok := Lookup(e.Type)
+	if !ok {
+		var errs Errors
+		errs.Add("type", "unsupported exercise type %q", e.Type)
+		return errs
+	}
+	return v.ValidateOptions(e, opts).Err()
+}
+
+// Validate runs every check for an exercise together with its options.
+func Validate(e *model.Exercise, opts []model.ExerciseOption) error {
+	var errs Errors
+	if err := ValidateExercise(e); err != nil {
+		errs = append(errs, err.(Errors)...)
+	}
+	if v, ok := Lookup(e.Type); ok {
+		errs = append(errs, v.ValidateOptions(e, opts)...)
+	}
+	return errs.Err()
+}
+
+func validateCommon(e *model.Exercise) Errors {
+	var errs Errors
+
+	if strings.TrimSpace(e.Title) == "" {
+		errs.Add("title", "is required")
+	}
+	if strings.TrimSpace(e.Prompt) == "" {
+		errs.Add("prompt", "is required")
+	}
+	if e.Points < 0 {
+		errs.Add("points", "must not be negative")
+	}
+	if e.Grade != 0 && (e.Grade < 1 || e.Grade > 5) {
+		errs.Add("grade", "must be between 1 and 5")
+	}
+	if e.MatchingType != nil && e.Type != model.ExerciseMatching {
+		errs.Add("matching_type", "is only allowed for matching exercises")
+	}
+
+	return errs
+}
@@ -0,0 +1,252 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+)
+
+func str(s string) *string { return &s }
+
+func exercise(t model.ExerciseType, meta model.JSONB) *model.Exercise {
+	return &model.Exercise{Title: "T", Prompt: "P", Type: t, Metadata: meta}
+}
+
+func opt(label, value string, correct bool) model.ExerciseOption {
+	return model.ExerciseOption{Label: label, Value: value, IsCorrect: correct}
+}
+
+// ordered sets each option's OrderIndex to its position.
+func ordered(opts ...model.ExerciseOption) []model.ExerciseOption {
+	for i := range opts {
+		opts[i].OrderIndex = i
+	}
+	return opts
+}
+
+func withMedia(o model.ExerciseOption, url string) model.ExerciseOption {
+	o.MediaURL = &url
+	return o
+}
+
+func matching(mt model.MatchingType) *model.Exercise {
+	e := exercise(model.ExerciseMatching, nil)
+	e.MatchingType = &mt
+	return e
+}
+
+func TestValidate(t *testing.T) {
+	choice := []model.ExerciseOption{opt("A", "a", true), opt("B", "b", false)}
+	nine := make([]model.ExerciseOption, 9)
+	for i := range nine {
+		nine[i] = opt(fmt.Sprint(i), fmt.Sprint(i), i == 0)
+	}
+	pairs := ordered(opt("C", "1", false), opt("Do", "1", false), opt("D", "2", false), opt("Re", "2", false))
+
+	tests := []struct {
+		name string
+		e    *model.Exercise
+		opts []model.ExerciseOption
+		want []string // fields with errors, sorted
+	}{
+		// Common rules
+		{"valid", exercise(model.ExerciseMultipleChoice, nil), choice, nil},
+		{"title and prompt required", &model.Exercise{Title: " ", Type: model.ExerciseMultipleChoice}, choice,
+			[]string{"prompt", "title"}},
+		{"points and grade", func() *model.Exercise {
+			e := exercise(model.ExerciseMultipleChoice, nil)
+			e.Points, e.Grade = -1, 6
+			return e
+		}(), choice, []string{"grade", "points"}},
+		{"matching type on another type", func() *model.Exercise {
+			e := exercise(model.ExerciseMultipleChoice, nil)
+			e.MatchingType = new(model.MatchingType)
+			*e.MatchingType = model.MatchTextToText
+			return e
+		}(), choice, []string{"matching_type"}},
+		{"unknown type", exercise("dictation", nil), nil, []string{"type"}},
+
+		// multiple_choice
+		{"one option", exercise(model.ExerciseMultipleChoice, nil), choice[:1], []string{"options"}},
+		{"nine options", exercise(model.ExerciseMultipleChoice, nil), nine, []string{"options"}},
+		{"no correct option", exercise(model.ExerciseMultipleChoice, nil),
+			[]model.ExerciseOption{opt("A", "a", false), opt("B", "b", false)}, []string{"options"}},
+		{"two correct options", exercise(model.ExerciseMultipleChoice, nil),
+			[]model.ExerciseOption{opt("A", "a", true), opt("B", "b", true)}, []string{"options"}},
+		{"two correct options allowed", exercise(model.ExerciseMultipleChoice, model.JSONB{"allow_multiple": true}),
+			[]model.ExerciseOption{opt("A", "a", true), opt("B", "b", true)}, nil},
+		{"allow_multiple not a boolean",
+			exercise(model.ExerciseMultipleChoice, model.JSONB{"allow_multiple": "yes"}),
+			choice, []string{"metadata.allow_multiple"}},
+		{"duplicate value", exercise(model.ExerciseMultipleChoice, nil),
+			[]model.ExerciseOption{opt("A", "a", true), opt("B", "a", false)}, []string{"options[1].value"}},
+		{"missing value", exercise(model.ExerciseMultipleChoice, nil),
+			[]model.ExerciseOption{opt("A", " ", true), opt("B", "b", false)}, []string{"options[0].value"}},
+		{"missing label", exercise(model.ExerciseMultipleChoice, nil),
+			[]model.ExerciseOption{opt("", "a", true), opt("B", "b", false)}, []string{"options[0].label"}},
+		{"media instead of label", exercise(model.ExerciseMultipleChoice, nil),
+			[]model.ExerciseOption{withMedia(opt("", "a", true), "a.png"), opt("B", "b", false)}, nil},
+		{"option media of unknown kind", exercise(model.ExerciseMultipleChoice, nil),
+			[]model.ExerciseOption{withMedia(opt("A", "a", true), "a.pdf"), opt("B", "b", false)},
+			[]string{"options[0].media_url"}},
+		{"stimulus of unknown kind", func() *model.Exercise {
+			e := exercise(model.ExerciseMultipleChoice, nil)
+			e.MediaURL = str("notes.txt")
+			return e
+		}(), choice, []string{"media_url"}},
+
+		// audio_recognition
+		{"audio recognition", func() *model.Exercise {
+			e := exercise(model.ExerciseAudioRecognition, model.JSONB{"max_plays": 3.0})
+			e.MediaURL = str("https://cdn.example.com/clip.MP3?v=2")
+			return e
+		}(), choice, nil},
+		{"audio recognition without audio", exercise(model.ExerciseAudioRecognition, nil), choice,
+			[]string{"media_url"}},
+		{"audio recognition with an image", func() *model.Exercise {
+			e := exercise(model.ExerciseAudioRecognition, nil)
+			e.MediaURL = str("clip.png")
+			return e
+		}(), choice, []string{"media_url"}},
+		{"negative max_plays", func() *model.Exercise {
+			e := exercise(model.ExerciseAudioRecognition, model.JSONB{"max_plays": -1.0})
+			e.MediaURL = str("clip.wav")
+			return e
+		}(), choice, []string{"metadata.max_plays"}},
+
+		// playback
+		{"playback", func() *model.Exercise {
+			e := exercise(model.ExercisePlayback, model.JSONB{"expected_sequence": []any{"C4", "E4"}})
+			e.MediaURL = str("clip.ogg")
+			return e
+		}(), nil, nil},
+		{"playback with options and a bad tolerance", func() *model.Exercise {
+			e := exercise(model.ExercisePlayback, model.JSONB{
+				"expected_sequence": []any{"C4"}, "tolerance_ms": "10",
+			})
+			e.MediaURL = str("clip.ogg")
+			return e
+		}(), choice, []string{"metadata.tolerance_ms", "options"}},
+		{"empty sequence", func() *model.Exercise {
+			e := exercise(model.ExercisePlayback, model.JSONB{"expected_sequence": []any{}})
+			e.MediaURL = str("clip.ogg")
+			return e
+		}(), nil, []string{"metadata.expected_sequence"}},
+		{"sequence of numbers", func() *model.Exercise {
+			e := exercise(model.ExercisePlayback, model.JSONB{"expected_sequence": []any{60.0}})
+			e.MediaURL = str("clip.ogg")
+			return e
+		}(), nil, []string{"metadata.expected_sequence"}},
+
+		// fill_in_the_blank
+		{"fill in the blanks", func() *model.Exercise {
+			e := exercise(model.ExerciseFillInTheBlank, model.JSONB{
+				"answers": []any{"D", []any{"F", "F natural"}},
+			})
+			e.Prompt = "C ___ E ___ G"
+			return e
+		}(), []model.ExerciseOption{opt("D", "", false), opt("F", "", false)}, nil},
+		{"no blank", exercise(model.ExerciseFillInTheBlank, model.JSONB{"answers": []any{"D"}}), nil,
+			[]string{"prompt"}},
+		{"answers for fewer blanks", func() *model.Exercise {
+			e := exercise(model.ExerciseFillInTheBlank, model.JSONB{"answers": []string{"D"}})
+			e.Prompt = "___ and ___"
+			return e
+		}(), nil, []string{"metadata.answers"}},
+		{"answers not a list", func() *model.Exercise {
+			e := exercise(model.ExerciseFillInTheBlank, model.JSONB{"answers": "D"})
+			e.Prompt = "___"
+			return e
+		}(), nil, []string{"metadata.answers"}},
+		{"empty answers", func() *model.Exercise {
+			e := exercise(model.ExerciseFillInTheBlank, model.JSONB{"answers": []any{" ", []any{}}})
+			e.Prompt = "___ ___"
+			return e
+		}(), nil, []string{"metadata.answers[0]", "metadata.answers[1]"}},
+		{"word without a label", func() *model.Exercise {
+			e := exercise(model.ExerciseFillInTheBlank, model.JSONB{"answers": []any{"D"}})
+			e.Prompt = "___"
+			return e
+		}(), []model.ExerciseOption{opt("", "d", false)}, []string{"options[0].label"}},
+
+		// matching
+		{"text pairs", matching(model.MatchTextToText), pairs, nil},
+		{"no matching type", exercise(model.ExerciseMatching, nil), pairs, []string{"matching_type"}},
+		{"unknown matching type", matching("smell_to_text"), pairs, []string{"matching_type"}},
+		{"one pair", matching(model.MatchTextToText), pairs[:2], []string{"options"}},
+		{"unpaired option", matching(model.MatchTextToText),
+			ordered(append(slices.Clone(pairs), opt("E", "3", false))...), []string{"options"}},
+		{"text side without label", matching(model.MatchTextToText),
+			ordered(opt("C", "1", false), opt("", "1", false), opt("D", "2", false), opt("Re", "2", false)),
+			[]string{"options[1].label"}},
+		{"image side without image", matching(model.MatchImageToText),
+			ordered(
+				withMedia(opt("", "1", false), "c.png"), opt("Do", "1", false),
+				opt("D", "2", false), opt("Re", "2", false),
+			),
+			[]string{"options[2].media_url"}},
+		{"audio side with an image", matching(model.MatchAudioToAudio),
+			ordered(
+				withMedia(opt("", "1", false), "c.mp3"), withMedia(opt("", "1", false), "c.png"),
+				withMedia(opt("", "2", false), "d.mp3"), withMedia(opt("", "2", false), "d.mp3"),
+			),
+			[]string{"options[1].media_url"}},
+		{"pairs by order index", matching(model.MatchTextToAudio),
+			[]model.ExerciseOption{
+				{Value: "1", MediaURL: str("do.mp3"), OrderIndex: 1}, {Label: "C", Value: "1", OrderIndex: 0},
+				{Value: "2", MediaURL: str("re.mp3"), OrderIndex: 3}, {Label: "D", Value: "2", OrderIndex: 2},
+			}, nil},
+
+		// typing
+		{"typing", exercise(model.ExerciseTyping, model.JSONB{"accepted_answers": []any{"P5"}}), nil, nil},
+		{"no accepted answers", exercise(model.ExerciseTyping, nil), nil, []string{"metadata.accepted_answers"}},
+		{"empty accepted answers", exercise(model.ExerciseTyping, model.JSONB{"accepted_answers": []any{}}), nil,
+			[]string{"metadata.accepted_answers"}},
+		{"blank accepted answer",
+			exercise(model.ExerciseTyping, model.JSONB{"accepted_answers": []any{"P5", ""}}), nil,
+			[]string{"metadata.accepted_answers"}},
+		{"typing with options", exercise(model.ExerciseTyping, model.JSONB{
+			"accepted_answers": []any{"P5"}, "case_sensitive": "no",
+		}), choice, []string{"metadata.case_sensitive", "options"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.e, tt.opts)
+			var got []string
+			if err != nil {
+				var errs Errors
+				if !errors.As(err, &errs) {
+					t.Fatalf("Validate() error = %v, want Errors", err)
+				}
+				for _, fe := range errs {
+					got = append(got, fe.Field)
+				}
+				slices.Sort(got)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Validate() = %v, want errors on %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMediaKindOf(t *testing.T) {
+	tests := []struct {
+		url, want string
+	}{
+		{"clip.mp3", MediaAudio},
+		{"https://cdn.example.com/a/clip.FLAC?sig=x.png", MediaAudio},
+		{"/files/c.jpeg", MediaImage},
+		{"c.svg", MediaImage},
+		{"notes.pdf", ""},
+		{"noext", ""},
+	}
+	for _, tt := range tests {
+		if got := MediaKindOf(tt.url); got != tt.want {
+			t.Errorf("MediaKindOf(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
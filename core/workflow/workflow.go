@@ -0,0 +1,137 @@
+// Package workflow defines the publishing state machine shared by courses,
+// units, skills, lessons and exercises, and which roles may drive it.
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+)
+
+// Roles that take part in the workflow. Admins may perform every transition.
+const (
+	RoleAdmin    = "admin"
+	RoleReviewer = "reviewer"
+	RoleEditor   = "editor"
+)
+
+// Roles lists every role allowed to request a transition.
+var Roles = []string{RoleAdmin, RoleReviewer, RoleEditor}
+
+// Action names a transition between workflow states.
+type Action string
+
+const (
+	ActionSubmit    Action = "submit"    // draft → in_review
+	ActionReject    Action = "reject"    // in_review → draft
+	ActionApprove   Action = "approve"   // in_review → approved
+	ActionPublish   Action = "publish"   // approved → published
+	ActionUnpublish Action = "unpublish" // published → approved
+	ActionArchive   Action = "archive"   // draft, approved or published → archived
+	ActionReopen    Action = "reopen"    // archived → draft
+)
+
+var (
+	// ErrUnknownAction is returned for an action that is not defined.
+	ErrUnknownAction = errors.New("unknown workflow action")
+	// ErrForbidden is returned when the caller's role may not perform an action.
+	ErrForbidden = errors.New("transition not allowed for role")
+	// ErrInvalidTransition is returned when the action does not apply to the
+	// entity's current state.
+	ErrInvalidTransition = errors.New("invalid workflow transition")
+	// ErrBlocked is returned when the state of a parent rules the action out,
+	// e.g. publishing a lesson whose skill is not published.
+	ErrBlocked = errors.New("transition blocked by parent")
+	// ErrPublished is returned when published content is edited; it has to be
+	// unpublished first so the change goes through review again.
+	ErrPublished = errors.New("published content cannot be edited")
+)
+
+// Transition describes what an action does and who may perform it.
+type Transition struct {
+	Action Action
+	From   []model.WorkflowState
+	To     model.WorkflowState
+	Roles  []string
+}
+
+var transitions = map[Action]Transition{
+	ActionSubmit: {
+		Action: ActionSubmit,
+		From:   []model.WorkflowState{model.StateDraft},
+		To:     model.StateInReview,
+		Roles:  []string{RoleEditor, RoleReviewer, RoleAdmin},
+	},
+	ActionReject: {
+		Action: ActionReject,
+		From:   []model.WorkflowState{model.StateInReview},
+		To:     model.StateDraft,
+		Roles:  []string{RoleReviewer, RoleAdmin},
+	},
+	ActionApprove: {
+		Action: ActionApprove,
+		From:   []model.WorkflowState{model.StateInReview},
+		To:     model.StateApproved,
+		Roles:  []string{RoleReviewer, RoleAdmin},
+	},
+	ActionPublish: {
+		Action: ActionPublish,
+		From:   []model.WorkflowState{model.StateApproved},
+		To:     model.StatePublished,
+		Roles:  []string{RoleAdmin},
+	},
+	ActionUnpublish: {
+		Action: ActionUnpublish,
+		From:   []model.WorkflowState{model.StatePublished},
+		To:     model.StateApproved,
+		Roles:  []string{RoleAdmin},
+	},
+	ActionArchive: {
+		Action: ActionArchive,
+		From:   []model.WorkflowState{model.StateDraft, model.StateApproved, model.StatePublished},
+		To:     model.StateArchived,
+		Roles:  []string{RoleAdmin},
+	},
+	ActionReopen: {
+		Action: ActionReopen,
+		From:   []model.WorkflowState{model.StateArchived},
+		To:     model.StateDraft,
+		Roles:  []string{RoleAdmin},
+	},
+}
+
+// Lookup returns the transition performed by an action.
+func Lookup(a Action) (Transition, error) {
+	t, ok := transitions[a]
+	if !ok {
+		return Transition{}, fmt.Errorf("%w: %q", ErrUnknownAction, a)
+	}
+	return t, nil
+}
+
+// Check reports whether role may apply t to content currently in state from.
+func (t Transition) Check(from model.WorkflowState, role string) error {
+	if !slices.Contains(t.Roles, role) {
+		return fmt.Errorf("%w: %s may not %s", ErrForbidden, role, t.Action)
+	}
+	if !slices.Contains(t.From, from) {
+		return fmt.Errorf("%w: cannot %s content that is %s", ErrInvalidTransition, t.Action, from)
+	}
+	return nil
+}
+
+// AfterEdit returns the state content in state from is left in by an edit.
+// Approved content goes back to draft, since the approval covered what was
+// there before, and published content may not be edited at all.
+func AfterEdit(from model.WorkflowState) (model.WorkflowState, error) {
+	switch from {
+	case model.StatePublished:
+		return from, fmt.Errorf("%w: unpublish it first", ErrPublished)
+	case model.StateApproved:
+		return model.StateDraft, nil
+	default:
+		return from, nil
+	}
+}
@@ -0,0 +1,103 @@
+package workflow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bytebeatz/bandroom-cms/core/model"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		action  Action
+		to      model.WorkflowState
+		wantErr error
+	}{
+		{ActionSubmit, model.StateInReview, nil},
+		{ActionReject, model.StateDraft, nil},
+		{ActionApprove, model.StateApproved, nil},
+		{ActionPublish, model.StatePublished, nil},
+		{ActionUnpublish, model.StateApproved, nil},
+		{ActionArchive, model.StateArchived, nil},
+		{ActionReopen, model.StateDraft, nil},
+		{"delete", "", ErrUnknownAction},
+		{"", "", ErrUnknownAction},
+	}
+	for _, tt := range tests {
+		tr, err := Lookup(tt.action)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("Lookup(%q) error = %v, want %v", tt.action, err, tt.wantErr)
+			continue
+		}
+		if tr.To != tt.to || (err == nil && tr.Action != tt.action) {
+			t.Errorf("Lookup(%q) = %s to %s, want to %s", tt.action, tr.Action, tr.To, tt.to)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		action  Action
+		from    model.WorkflowState
+		role    string
+		wantErr error
+	}{
+		{ActionSubmit, model.StateDraft, RoleEditor, nil},
+		{ActionSubmit, model.StateDraft, RoleReviewer, nil},
+		{ActionSubmit, model.StateInReview, RoleEditor, ErrInvalidTransition},
+		{ActionReject, model.StateInReview, RoleReviewer, nil},
+		{ActionReject, model.StateInReview, RoleEditor, ErrForbidden},
+		{ActionApprove, model.StateInReview, RoleReviewer, nil},
+		{ActionApprove, model.StateInReview, RoleAdmin, nil},
+		{ActionApprove, model.StateInReview, RoleEditor, ErrForbidden},
+		{ActionApprove, model.StateDraft, RoleReviewer, ErrInvalidTransition},
+		{ActionPublish, model.StateApproved, RoleAdmin, nil},
+		{ActionPublish, model.StateApproved, RoleReviewer, ErrForbidden},
+		{ActionPublish, model.StateInReview, RoleAdmin, ErrInvalidTransition},
+		{ActionPublish, model.StatePublished, RoleAdmin, ErrInvalidTransition},
+		{ActionUnpublish, model.StatePublished, RoleAdmin, nil},
+		{ActionUnpublish, model.StateApproved, RoleAdmin, ErrInvalidTransition},
+		{ActionArchive, model.StateDraft, RoleAdmin, nil},
+		{ActionArchive, model.StateApproved, RoleAdmin, nil},
+		{ActionArchive, model.StatePublished, RoleAdmin, nil},
+		{ActionArchive, model.StateInReview, RoleAdmin, ErrInvalidTransition},
+		{ActionArchive, model.StateArchived, RoleAdmin, ErrInvalidTransition},
+		{ActionReopen, model.StateArchived, RoleAdmin, nil},
+		{ActionReopen, model.StateArchived, RoleEditor, ErrForbidden},
+		{ActionReopen, model.StateDraft, RoleAdmin, ErrInvalidTransition},
+		// The role is checked before the state
+		{ActionPublish, model.StateDraft, RoleEditor, ErrForbidden},
+		{ActionSubmit, model.StateDraft, "", ErrForbidden},
+		{ActionSubmit, model.StateDraft, "learner", ErrForbidden},
+	}
+	for _, tt := range tests {
+		tr, err := Lookup(tt.action)
+		if err != nil {
+			t.Fatalf("Lookup(%q) error = %v", tt.action, err)
+		}
+		if err := tr.Check(tt.from, tt.role); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s from %s as %q: error = %v, want %v",
+				tt.action, tt.from, tt.role, err, tt.wantErr)
+		}
+	}
+}
+
+func TestAfterEdit(t *testing.T) {
+	tests := []struct {
+		from    model.WorkflowState
+		want    model.WorkflowState
+		wantErr error
+	}{
+		{model.StateDraft, model.StateDraft, nil},
+		{model.StateInReview, model.StateInReview, nil},
+		{model.StateApproved, model.StateDraft, nil},
+		{model.StatePublished, model.StatePublished, ErrPublished},
+		{model.StateArchived, model.StateArchived, nil},
+	}
+	for _, tt := range tests {
+		got, err := AfterEdit(tt.from)
+		if got != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Errorf("AfterEdit(%s) = %s, %v; want %s, %v", tt.from, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
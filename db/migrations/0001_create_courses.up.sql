@@ -0,0 +1,18 @@
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE IF NOT EXISTS courses (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    slug         TEXT UNIQUE NOT NULL,
+    title        TEXT NOT NULL,
+    description  TEXT NOT NULL DEFAULT '',
+    language     TEXT NOT NULL DEFAULT 'en',
+    difficulty   INT NOT NULL DEFAULT 1,
+    is_published BOOLEAN NOT NULL DEFAULT FALSE,
+    tags         TEXT[],
+    metadata     JSONB,
+    version      INT NOT NULL DEFAULT 1,
+    deleted_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    creator_id   UUID
+);
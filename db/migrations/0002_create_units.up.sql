@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS units (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    course_id   UUID NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    title       TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    order_index INT NOT NULL DEFAULT 0,
+    version     INT NOT NULL DEFAULT 1,
+    deleted_at  TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_units_course_id ON units (course_id, order_index);
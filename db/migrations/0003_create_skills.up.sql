@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS skills (
+    id                     UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    course_id              UUID NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    unit_id                UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    slug                   TEXT NOT NULL,
+    title                  TEXT NOT NULL,
+    icon                   TEXT NOT NULL DEFAULT '',
+    order_index            INT NOT NULL DEFAULT 0,
+    difficulty             INT NOT NULL DEFAULT 1,
+    max_crowns             INT NOT NULL DEFAULT 0,
+    base_xp_reward         INT NOT NULL DEFAULT 0,
+    xp_per_crown           INT NOT NULL DEFAULT 0,
+    prerequisite_skill_ids TEXT[],
+    creator_id             UUID NOT NULL,
+    tags                   TEXT[],
+    metadata               JSONB,
+    version                INT NOT NULL DEFAULT 1,
+    deleted_at             TIMESTAMPTZ,
+    created_at             TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at             TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_skills_unit_id ON skills (unit_id, order_index);
+CREATE INDEX IF NOT EXISTS idx_skills_course_id ON skills (course_id);
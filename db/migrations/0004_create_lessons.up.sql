@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS lessons (
+    id                 UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    skill_id           UUID NOT NULL REFERENCES skills(id) ON DELETE CASCADE,
+    slug               TEXT NOT NULL,
+    title              TEXT NOT NULL,
+    description        TEXT NOT NULL DEFAULT '',
+    order_index        INT NOT NULL DEFAULT 0,
+    total_exercises    INT NOT NULL DEFAULT 0,
+    base_xp            INT NOT NULL DEFAULT 0,
+    bonus_xp           INT NOT NULL DEFAULT 0,
+    reward_gems        INT NOT NULL DEFAULT 0,
+    reward_hearts      INT NOT NULL DEFAULT 0,
+    reward_condition   TEXT NOT NULL DEFAULT '',
+    estimated_duration INT NOT NULL DEFAULT 0,
+    difficulty_rating  REAL NOT NULL DEFAULT 0,
+    is_testable        BOOLEAN NOT NULL DEFAULT FALSE,
+    creator_id         UUID NOT NULL,
+    tags               TEXT[],
+    metadata           JSONB,
+    version            INT NOT NULL DEFAULT 1,
+    deleted_at         TIMESTAMPTZ,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_lessons_skill_id ON lessons (skill_id, order_index);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS exercise_answers;
+DROP TABLE IF EXISTS exercise_options;
+DROP TABLE IF EXISTS exercises;
@@ -0,0 +1,49 @@
+CREATE TABLE IF NOT EXISTS exercises (
+    id            UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    skill_id      UUID NOT NULL REFERENCES skills(id) ON DELETE CASCADE,
+    lesson_id     UUID NOT NULL REFERENCES lessons(id) ON DELETE CASCADE,
+    title         TEXT NOT NULL,
+    type          TEXT NOT NULL,
+    matching_type TEXT,
+    prompt        TEXT NOT NULL DEFAULT '',
+    media_url     TEXT,
+    order_index   INT NOT NULL DEFAULT 0,
+    points        INT NOT NULL DEFAULT 0,
+    grade         INT NOT NULL DEFAULT 0,
+    syllabus      TEXT NOT NULL DEFAULT '',
+    objective_tag TEXT NOT NULL DEFAULT '',
+    metadata      JSONB,
+    deleted_at    TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_exercises_lesson_id ON exercises (lesson_id, order_index);
+CREATE INDEX IF NOT EXISTS idx_exercises_skill_id ON exercises (skill_id, order_index);
+
+CREATE TABLE IF NOT EXISTS exercise_options (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    exercise_id UUID NOT NULL REFERENCES exercises(id) ON DELETE CASCADE,
+    label       TEXT NOT NULL DEFAULT '',
+    value       TEXT NOT NULL DEFAULT '',
+    is_correct  BOOLEAN NOT NULL DEFAULT FALSE,
+    media_url   TEXT,
+    order_index INT NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_exercise_options_exercise_id
+    ON exercise_options (exercise_id, order_index);
+
+CREATE TABLE IF NOT EXISTS exercise_answers (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id      UUID NOT NULL,
+    exercise_id  UUID NOT NULL REFERENCES exercises(id) ON DELETE CASCADE,
+    attempt_id   UUID NOT NULL,
+    response     JSONB,
+    is_correct   BOOLEAN NOT NULL DEFAULT FALSE,
+    submitted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_exercise_answers_exercise_id ON exercise_answers (exercise_id);
@@ -0,0 +1 @@
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS revisions (
+    id UUID PRIMARY KEY,
+    entity_type TEXT NOT NULL,
+    entity_id UUID NOT NULL,
+    version INT NOT NULL,
+    snapshot JSONB NOT NULL,
+    author_id UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (entity_type, entity_id, version)
+);
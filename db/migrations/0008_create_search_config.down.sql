@@ -0,0 +1 @@
+DROP FUNCTION IF EXISTS search_config(TEXT);
@@ -0,0 +1,26 @@
+-- Maps a course language code (e.g. "en", "pt-BR") to the text search
+-- configuration used to stem its content. Unknown languages fall back to
+-- "simple", which only lowercases.
+CREATE OR REPLACE FUNCTION search_config(lang TEXT) RETURNS regconfig
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT (CASE lower(split_part(coalesce(lang, ''), '-', 1))
+        WHEN 'en' THEN 'english'
+        WHEN 'de' THEN 'german'
+        WHEN 'fr' THEN 'french'
+        WHEN 'es' THEN 'spanish'
+        WHEN 'it' THEN 'italian'
+        WHEN 'pt' THEN 'portuguese'
+        WHEN 'nl' THEN 'dutch'
+        WHEN 'sv' THEN 'swedish'
+        WHEN 'da' THEN 'danish'
+        WHEN 'no' THEN 'norwegian'
+        WHEN 'nb' THEN 'norwegian'
+        WHEN 'fi' THEN 'finnish'
+        WHEN 'ru' THEN 'russian'
+        WHEN 'hu' THEN 'hungarian'
+        WHEN 'ro' THEN 'romanian'
+        WHEN 'tr' THEN 'turkish'
+        ELSE 'simple'
+    END)::regconfig
+$$;
+
@@ -0,0 +1,5 @@
+ALTER TABLE exercises DROP COLUMN IF EXISTS status;
+ALTER TABLE lessons DROP COLUMN IF EXISTS status;
+ALTER TABLE skills DROP COLUMN IF EXISTS status;
+ALTER TABLE units DROP COLUMN IF EXISTS status;
+ALTER TABLE courses DROP COLUMN IF EXISTS status;
@@ -0,0 +1,19 @@
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'draft';
+ALTER TABLE units ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'draft';
+ALTER TABLE skills ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'draft';
+ALTER TABLE lessons ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'draft';
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'draft';
+
+-- Courses that were already live keep being served
+UPDATE courses SET status = 'published' WHERE is_published;
+
+ALTER TABLE courses ADD CONSTRAINT courses_status_check
+    CHECK (status IN ('draft', 'in_review', 'approved', 'published', 'archived'));
+ALTER TABLE units ADD CONSTRAINT units_status_check
+    CHECK (status IN ('draft', 'in_review', 'approved', 'published', 'archived'));
+ALTER TABLE skills ADD CONSTRAINT skills_status_check
+    CHECK (status IN ('draft', 'in_review', 'approved', 'published', 'archived'));
+ALTER TABLE lessons ADD CONSTRAINT lessons_status_check
+    CHECK (status IN ('draft', 'in_review', 'approved', 'published', 'archived'));
+ALTER TABLE exercises ADD CONSTRAINT exercises_status_check
+    CHECK (status IN ('draft', 'in_review', 'approved', 'published', 'archived'));
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_courses_unpublish_at;
+DROP INDEX IF EXISTS idx_courses_publish_at;
+ALTER TABLE courses DROP COLUMN IF EXISTS unpublish_scheduled_by;
+ALTER TABLE courses DROP COLUMN IF EXISTS publish_scheduled_by;
+ALTER TABLE courses DROP COLUMN IF EXISTS unpublish_at;
+ALTER TABLE courses DROP COLUMN IF EXISTS publish_at;
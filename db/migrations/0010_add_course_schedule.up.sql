@@ -0,0 +1,10 @@
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ;
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS unpublish_at TIMESTAMPTZ;
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS publish_scheduled_by UUID;
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS unpublish_scheduled_by UUID;
+
+-- The scheduler only ever looks for courses with something due
+CREATE INDEX IF NOT EXISTS idx_courses_publish_at ON courses (publish_at)
+    WHERE publish_at IS NOT NULL AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_courses_unpublish_at ON courses (unpublish_at)
+    WHERE unpublish_at IS NOT NULL AND deleted_at IS NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS release_channels;
+DROP TRIGGER IF EXISTS releases_immutable ON releases;
+DROP TABLE IF EXISTS releases;
+DROP FUNCTION IF EXISTS releases_immutable();
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS releases (
+    id UUID PRIMARY KEY,
+    course_id UUID NOT NULL REFERENCES courses(id),
+    version INT NOT NULL,
+    content_hash TEXT NOT NULL,
+    content JSONB NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (course_id, version)
+);
+
+-- Releases are immutable once written
+CREATE OR REPLACE FUNCTION releases_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'releases are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER releases_immutable
+    BEFORE UPDATE OR DELETE ON releases
+    FOR EACH ROW EXECUTE FUNCTION releases_immutable();
+
+CREATE TABLE IF NOT EXISTS release_channels (
+    course_id UUID NOT NULL REFERENCES courses(id),
+    channel TEXT NOT NULL CHECK (channel IN ('beta', 'stable')),
+    release_id UUID NOT NULL REFERENCES releases(id),
+    promoted_by UUID,
+    promoted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (course_id, channel)
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_exercise_options_media_url;
+DROP INDEX IF EXISTS idx_exercises_media_url;
+DROP TABLE IF EXISTS media_assets;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS media_assets (
+    id UUID PRIMARY KEY,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    checksum TEXT NOT NULL,
+    object_path TEXT NOT NULL UNIQUE,
+    url TEXT NOT NULL,
+    duration_ms INT,
+    uploaded_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_media_assets_url ON media_assets (url);
+CREATE INDEX IF NOT EXISTS idx_exercises_media_url ON exercises (media_url);
+CREATE INDEX IF NOT EXISTS idx_exercise_options_media_url ON exercise_options (media_url);
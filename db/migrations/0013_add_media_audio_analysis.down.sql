@@ -0,0 +1,6 @@
+ALTER TABLE media_assets DROP COLUMN IF EXISTS loudness_flags;
+ALTER TABLE media_assets DROP COLUMN IF EXISTS waveform;
+ALTER TABLE media_assets DROP COLUMN IF EXISTS rms_db;
+ALTER TABLE media_assets DROP COLUMN IF EXISTS peak_db;
+ALTER TABLE media_assets DROP COLUMN IF EXISTS channels;
+ALTER TABLE media_assets DROP COLUMN IF EXISTS sample_rate;
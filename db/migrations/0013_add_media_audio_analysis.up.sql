@@ -0,0 +1,6 @@
+ALTER TABLE media_assets ADD COLUMN IF NOT EXISTS sample_rate INT;
+ALTER TABLE media_assets ADD COLUMN IF NOT EXISTS channels INT;
+ALTER TABLE media_assets ADD COLUMN IF NOT EXISTS peak_db DOUBLE PRECISION;
+ALTER TABLE media_assets ADD COLUMN IF NOT EXISTS rms_db DOUBLE PRECISION;
+ALTER TABLE media_assets ADD COLUMN IF NOT EXISTS waveform DOUBLE PRECISION[];
+ALTER TABLE media_assets ADD COLUMN IF NOT EXISTS loudness_flags TEXT[];
@@ -0,0 +1,3 @@
+ALTER TABLE media_assets DROP COLUMN IF EXISTS variants;
+ALTER TABLE media_assets DROP COLUMN IF EXISTS height;
+ALTER TABLE media_assets DROP COLUMN IF EXISTS width;
@@ -0,0 +1,3 @@
+ALTER TABLE media_assets ADD COLUMN IF NOT EXISTS width INT;
+ALTER TABLE media_assets ADD COLUMN IF NOT EXISTS height INT;
+ALTER TABLE media_assets ADD COLUMN IF NOT EXISTS variants JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,23 @@
+DROP TRIGGER IF EXISTS skills_language_changed ON skills;
+DROP TRIGGER IF EXISTS courses_language_changed ON courses;
+DROP TRIGGER IF EXISTS exercises_course_language ON exercises;
+DROP TRIGGER IF EXISTS lessons_course_language ON lessons;
+DROP TRIGGER IF EXISTS skills_course_language ON skills;
+DROP TRIGGER IF EXISTS units_course_language ON units;
+DROP FUNCTION IF EXISTS skills_language_changed();
+DROP FUNCTION IF EXISTS courses_language_changed();
+DROP FUNCTION IF EXISTS course_language_from_skill();
+DROP FUNCTION IF EXISTS course_language_from_course();
+
+ALTER TABLE exercises DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE lessons DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE skills DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE units DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE courses DROP COLUMN IF EXISTS search_vector;
+
+ALTER TABLE exercises DROP COLUMN IF EXISTS course_language;
+ALTER TABLE lessons DROP COLUMN IF EXISTS course_language;
+ALTER TABLE skills DROP COLUMN IF EXISTS course_language;
+ALTER TABLE units DROP COLUMN IF EXISTS course_language;
+
+DROP FUNCTION IF EXISTS search_tags(TEXT[]);
@@ -0,0 +1,107 @@
+-- Search vectors are stored and indexed instead of built for every row at
+-- query time. Stemming follows the course language, so every table below
+-- courses keeps a copy of it in course_language, set by triggers.
+
+-- array_to_string is only stable, which generated columns do not allow
+CREATE OR REPLACE FUNCTION search_tags(tags TEXT[]) RETURNS text
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT coalesce(array_to_string(tags, ' '), '')
+$$;
+
+ALTER TABLE units ADD COLUMN IF NOT EXISTS course_language TEXT NOT NULL DEFAULT '';
+ALTER TABLE skills ADD COLUMN IF NOT EXISTS course_language TEXT NOT NULL DEFAULT '';
+ALTER TABLE lessons ADD COLUMN IF NOT EXISTS course_language TEXT NOT NULL DEFAULT '';
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS course_language TEXT NOT NULL DEFAULT '';
+
+UPDATE units u SET course_language = c.language FROM courses c WHERE c.id = u.course_id;
+UPDATE skills s SET course_language = c.language FROM courses c WHERE c.id = s.course_id;
+UPDATE lessons l SET course_language = s.course_language FROM skills s WHERE s.id = l.skill_id;
+UPDATE exercises e SET course_language = s.course_language FROM skills s WHERE s.id = e.skill_id;
+
+-- Titles weigh more (A) than descriptions and prompts (B), and those more
+-- than tags (C)
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector(search_config(language), title), 'A') ||
+    setweight(to_tsvector(search_config(language), description), 'B') ||
+    setweight(to_tsvector(search_config(language), search_tags(tags)), 'C')
+) STORED;
+ALTER TABLE units ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector(search_config(course_language), title), 'A') ||
+    setweight(to_tsvector(search_config(course_language), description), 'B')
+) STORED;
+ALTER TABLE skills ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector(search_config(course_language), title), 'A') ||
+    setweight(to_tsvector(search_config(course_language), search_tags(tags)), 'C')
+) STORED;
+ALTER TABLE lessons ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector(search_config(course_language), title), 'A') ||
+    setweight(to_tsvector(search_config(course_language), description), 'B') ||
+    setweight(to_tsvector(search_config(course_language), search_tags(tags)), 'C')
+) STORED;
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector(search_config(course_language), title), 'A') ||
+    setweight(to_tsvector(search_config(course_language), prompt), 'B') ||
+    setweight(to_tsvector(search_config(course_language), objective_tag || ' ' || syllabus), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_courses_search ON courses USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_units_search ON units USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_skills_search ON skills USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_lessons_search ON lessons USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_exercises_search ON exercises USING GIN (search_vector);
+
+-- New and moved rows take the language of their course
+CREATE OR REPLACE FUNCTION course_language_from_course() RETURNS trigger AS $$
+BEGIN
+    NEW.course_language := coalesce((SELECT language FROM courses WHERE id = NEW.course_id), '');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION course_language_from_skill() RETURNS trigger AS $$
+BEGIN
+    NEW.course_language := coalesce((SELECT course_language FROM skills WHERE id = NEW.skill_id), '');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER units_course_language
+    BEFORE INSERT OR UPDATE OF course_id ON units
+    FOR EACH ROW EXECUTE FUNCTION course_language_from_course();
+CREATE TRIGGER skills_course_language
+    BEFORE INSERT OR UPDATE OF course_id ON skills
+    FOR EACH ROW EXECUTE FUNCTION course_language_from_course();
+CREATE TRIGGER lessons_course_language
+    BEFORE INSERT OR UPDATE OF skill_id ON lessons
+    FOR EACH ROW EXECUTE FUNCTION course_language_from_skill();
+CREATE TRIGGER exercises_course_language
+    BEFORE INSERT OR UPDATE OF skill_id ON exercises
+    FOR EACH ROW EXECUTE FUNCTION course_language_from_skill();
+
+-- A course changing language restems all of its content, and a skill moving
+-- to another course takes its lessons and exercises along
+CREATE OR REPLACE FUNCTION courses_language_changed() RETURNS trigger AS $$
+BEGIN
+    UPDATE units SET course_language = NEW.language WHERE course_id = NEW.id;
+    UPDATE skills SET course_language = NEW.language WHERE course_id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION skills_language_changed() RETURNS trigger AS $$
+BEGIN
+    UPDATE lessons SET course_language = NEW.course_language WHERE skill_id = NEW.id;
+    UPDATE exercises SET course_language = NEW.course_language WHERE skill_id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER courses_language_changed
+    AFTER UPDATE OF language ON courses
+    FOR EACH ROW WHEN (OLD.language IS DISTINCT FROM NEW.language)
+    EXECUTE FUNCTION courses_language_changed();
+-- Not UPDATE OF course_language, which misses changes made by triggers
+CREATE TRIGGER skills_language_changed
+    AFTER UPDATE ON skills
+    FOR EACH ROW WHEN (OLD.course_language IS DISTINCT FROM NEW.course_language)
+    EXECUTE FUNCTION skills_language_changed();
@@ -3,10 +3,12 @@ package server
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -14,7 +16,10 @@ import (
 	"github.com/bytebeatz/bandroom-cms/api/router"
 	"github.com/bytebeatz/bandroom-cms/config"
 	_interface "github.com/bytebeatz/bandroom-cms/core/interface"
+	"github.com/bytebeatz/bandroom-cms/core/pack"
 	"github.com/bytebeatz/bandroom-cms/core/service"
+	"github.com/bytebeatz/bandroom-cms/db"
+	"github.com/bytebeatz/bandroom-cms/storage"
 	"github.com/gin-gonic/gin"
 )
 
@@ -27,30 +32,158 @@ func Start() error {
 	ctx := context.Background()
 	config.InitGCS(ctx)
 
+	// Apply pending schema migrations (disable with AUTO_MIGRATE=false)
+	if config.AppConfig.AutoMigrate {
+		if err := db.RunMigrations(ctx, config.DB); err != nil {
+			return fmt.Errorf("running migrations: %w", err)
+		}
+	}
+
+	// Media files live in GCS, a local directory or memory (STORAGE_BACKEND)
+	blobStore, err := storage.NewBlobStore(config.AppConfig)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+
 	// Init repositories & services
+	mediaService := service.NewMediaService(
+		_interface.NewMediaPG(config.DB),
+		blobStore,
+		config.AppConfig.SignedURLExpiry,
+	)
+
+	revisionRepo := _interface.NewRevisionPG(config.DB)
+	transactor := _interface.NewTransactorPG(config.DB)
+
 	courseRepo := _interface.NewCoursePG(config.DB)
-	courseService := service.NewCourseService(courseRepo)
+	courseService := service.NewCourseService(courseRepo, revisionRepo, transactor)
 	courseHandler := handler.NewCourseHandler(courseService)
 
 	unitRepo := _interface.NewUnitPG(config.DB)
-	unitService := service.NewUnitService(unitRepo)
+	unitService := service.NewUnitService(unitRepo, courseRepo, revisionRepo, transactor)
 	unitHandler := handler.NewUnitHandler(unitService)
 
 	skillRepo := _interface.NewSkillPG(config.DB)
-	skillService := service.NewSkillService(skillRepo)
+	skillService := service.NewSkillService(skillRepo, unitRepo, revisionRepo, transactor)
 	skillHandler := handler.NewSkillHandler(skillService)
 
 	lessonRepo := _interface.NewLessonPG(config.DB)
-	lessonService := service.NewLessonService(lessonRepo)
+	lessonService := service.NewLessonService(lessonRepo, skillRepo, revisionRepo, transactor)
 	lessonHandler := handler.NewLessonHandler(lessonService)
 
+	exerciseRepo := _interface.NewExercisePG(config.DB)
+	exerciseOptionRepo := _interface.NewExerciseOptionPG(config.DB)
+	exerciseService := service.NewExerciseService(
+		exerciseRepo,
+		exerciseOptionRepo,
+		lessonRepo,
+		skillRepo,
+		revisionRepo,
+		transactor,
+	)
+	exerciseHandler := handler.NewExerciseHandler(exerciseService, mediaService)
+	exerciseOptionHandler := handler.NewExerciseOptionHandler(exerciseService, mediaService)
+
+	revisionService := service.NewRevisionService(
+		revisionRepo,
+		courseService,
+		unitService,
+		skillService,
+		lessonService,
+		exerciseService,
+	)
+	revisionHandler := handler.NewRevisionHandler(revisionService)
+
+	searchService := service.NewSearchService(_interface.NewSearchPG(config.DB))
+	searchHandler := handler.NewSearchHandler(searchService)
+
+	treeService := service.NewTreeService(
+		courseRepo,
+		unitRepo,
+		skillRepo,
+		lessonRepo,
+		exerciseRepo,
+	)
+	treeHandler := handler.NewTreeHandler(treeService)
+
+	skillGraphService := service.NewSkillGraphService(courseRepo, unitRepo, skillRepo)
+	skillGraphHandler := handler.NewSkillGraphHandler(skillGraphService)
+
+	workflowService := service.NewWorkflowService(
+		courseRepo,
+		unitRepo,
+		skillRepo,
+		lessonRepo,
+		exerciseRepo,
+		exerciseOptionRepo,
+		_interface.NewWorkflowPG(config.DB),
+		revisionRepo,
+		transactor,
+	)
+	workflowHandler := handler.NewWorkflowHandler(workflowService)
+
+	releaseService := service.NewReleaseService(
+		_interface.NewReleasePG(config.DB),
+		courseRepo,
+		exerciseOptionRepo,
+		treeService,
+	)
+	releaseHandler := handler.NewReleaseHandler(releaseService)
+
+	packService := service.NewPackService(releaseService, pack.Media{
+		Stored: func(url string) bool {
+			return storage.IsStored(blobStore, url)
+		},
+		Open: func(ctx context.Context, url string) (io.ReadCloser, error) {
+			return storage.OpenURL(ctx, blobStore, url)
+		},
+	})
+	packHandler := handler.NewPackHandler(packService)
+
+	portableService := service.NewPortableService(
+		_interface.NewImportPG(config.DB),
+		courseRepo,
+		exerciseOptionRepo,
+		revisionRepo,
+		treeService,
+		transactor,
+	)
+	portableHandler := handler.NewPortableHandler(portableService)
+
+	mediaHandler := handler.NewMediaHandler(mediaService)
+
+	scheduler := service.NewScheduler(
+		courseRepo,
+		workflowService,
+		_interface.NewLockPG(config.DB),
+		transactor,
+		config.AppConfig.SchedulerInterval,
+	)
+
 	// Setup Gin router with all handlers
-	r := router.SetupRouter(courseHandler, unitHandler, skillHandler, lessonHandler)
+	r := router.SetupRouter(
+		courseHandler,
+		unitHandler,
+		skillHandler,
+		lessonHandler,
+		exerciseHandler,
+		exerciseOptionHandler,
+		revisionHandler,
+		searchHandler,
+		treeHandler,
+		skillGraphHandler,
+		workflowHandler,
+		releaseHandler,
+		packHandler,
+		portableHandler,
+		mediaHandler,
+	)
 
 	// Graceful shutdown setup
 	srv := &httpServer{
-		Engine: r,
-		Port:   config.AppConfig.Port,
+		Engine:  r,
+		Port:    config.AppConfig.Port,
+		Workers: []func(context.Context){scheduler.Run},
 	}
 	return srv.Run()
 }
@@ -59,6 +192,10 @@ func Start() error {
 type httpServer struct {
 	Engine *gin.Engine
 	Port   string
+
+	// Workers run in the background for the life of the server; their
+	// context is cancelled on shutdown and Run waits for them to return.
+	Workers []func(ctx context.Context)
 }
 
 func (s *httpServer) Run() error {
@@ -67,6 +204,16 @@ func (s *httpServer) Run() error {
 		Handler: s.Engine,
 	}
 
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	for _, work := range s.Workers {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			work(workerCtx)
+		}()
+	}
+
 	// Start server in goroutine
 	go func() {
 		log.Printf("Bandroom CMS server running at http://localhost:%s", s.Port)
@@ -84,6 +231,9 @@ func (s *httpServer) Run() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	return srv.Shutdown(ctx)
+	err := srv.Shutdown(ctx)
+	stopWorkers()
+	workers.Wait()
+	return err
 }
 
@@ -0,0 +1,187 @@
+// Package storage keeps uploaded media files in a blob store: Google Cloud
+// Storage in production, a local directory for development and
+// self-hosting, or memory for tests. Objects are private; clients read them
+// through signed, expiring URLs.
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytebeatz/bandroom-cms/config"
+)
+
+// ErrNotFound is returned for objects that do not exist.
+var ErrNotFound = errors.New("object not found")
+
+// ErrInvalidPath is returned for object paths that are empty, absolute or
+// contain "." or ".." elements.
+var ErrInvalidPath = errors.New("invalid object path")
+
+// ErrInvalidSignature is returned for signed URLs that were not made by
+// the store or have expired.
+var ErrInvalidSignature = errors.New("invalid or expired signature")
+
+// FilesPath is where the CMS serves the files of stores that have no URLs
+// of their own.
+const FilesPath = "/files/"
+
+// Object describes a stored file.
+type Object struct {
+	Path        string
+	Size        int64
+	ContentType string
+	Updated     time.Time
+}
+
+// UploadOptions describe an object being uploaded.
+type UploadOptions struct {
+	ContentType string
+}
+
+// BlobStore stores files under slash-separated object paths such as
+// "media/<id>.wav".
+type BlobStore interface {
+	// Upload writes an object, replacing any object at the same path.
+	Upload(ctx context.Context, path string, r io.Reader, opts UploadOptions) error
+	// Download streams an object, which the caller must close.
+	Download(ctx context.Context, path string) (io.ReadCloser, error)
+	Delete(ctx context.Context, path string) error
+	Stat(ctx context.Context, path string) (*Object, error)
+	// List returns the objects whose paths start with prefix, by path.
+	List(ctx context.Context, prefix string) ([]Object, error)
+	// SignedURL returns a URL that allows reading an object until it expires.
+	SignedURL(ctx context.Context, path string, expiresIn time.Duration) (string, error)
+
+	// URL returns the URL content refers to an object by. Objects are
+	// private, so it only identifies the object; use SignedURL to read it.
+	URL(path string) string
+	// ObjectPath returns the object a URL points at; ok is false for URLs
+	// outside the store.
+	ObjectPath(url string) (path string, ok bool)
+}
+
+// Backends that can be chosen with STORAGE_BACKEND.
+const (
+	BackendGCS    = "gcs"
+	BackendLocal  = "local"
+	BackendMemory = "memory"
+)
+
+// NewBlobStore returns the store chosen by cfg.StorageBackend. The GCS
+// store needs the client made by config.InitGCS.
+func NewBlobStore(cfg *config.Config) (BlobStore, error) {
+	switch cfg.StorageBackend {
+	case BackendGCS:
+		if config.GCSClient == nil {
+			return nil, errors.New("STORAGE_BACKEND=gcs needs GCS_ENABLED=true")
+		}
+		return NewGCSStore(config.GCSClient, cfg.GCSBucket, GCSOptions{
+			PublicURL:   cfg.GCSPublicURL,
+			SignerEmail: cfg.GCSSignerEmail,
+			SignerKey:   cfg.GCSSignerKey,
+		})
+	case BackendLocal:
+		return NewLocalStore(cfg.StorageDir, cfg.PublicURL, []byte(cfg.StorageSigningKey))
+	case BackendMemory:
+		return NewMemoryStore(cfg.PublicURL, []byte(cfg.StorageSigningKey))
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q (want gcs, local or memory)", cfg.StorageBackend)
+	}
+}
+
+// IsStored reports whether a URL points into the store.
+func IsStored(store BlobStore, url string) bool {
+	_, ok := store.ObjectPath(url)
+	return ok
+}
+
+// OpenURL streams the object behind a URL in the store.
+func OpenURL(ctx context.Context, store BlobStore, url string) (io.ReadCloser, error) {
+	path, ok := store.ObjectPath(url)
+	if !ok {
+		return nil, fmt.Errorf("%s is not in storage", url)
+	}
+	return store.Download(ctx, path)
+}
+
+// FileVerifier is implemented by stores whose files the CMS serves at
+// FilesPath. It checks the expires and signature query parameters of a URL
+// made by SignedURL.
+type FileVerifier interface {
+	VerifySignature(path, expires, signature string) error
+}
+
+func checkPath(path string) error {
+	if path == "" || path == "." || !fs.ValidPath(path) {
+		return fmt.Errorf("%w: %q", ErrInvalidPath, path)
+	}
+	return nil
+}
+
+// cmsURLs maps object paths to URLs under FilesPath on the CMS and signs
+// them with an HMAC key.
+type cmsURLs struct {
+	base string // e.g. http://localhost:8080/files/
+	key  []byte
+}
+
+func newCMSURLs(publicURL string, key []byte) (cmsURLs, error) {
+	if len(key) == 0 {
+		return cmsURLs{}, errors.New("storage needs STORAGE_SIGNING_KEY to sign URLs")
+	}
+	return cmsURLs{base: strings.TrimSuffix(publicURL, "/") + FilesPath, key: key}, nil
+}
+
+func (u cmsURLs) URL(path string) string {
+	return u.base + (&url.URL{Path: path}).EscapedPath()
+}
+
+func (u cmsURLs) ObjectPath(rawURL string) (string, bool) {
+	rest, _, _ := strings.Cut(rawURL, "?")
+	rest, found := strings.CutPrefix(rest, u.base)
+	if !found {
+		return "", false
+	}
+	path, err := url.PathUnescape(rest)
+	if err != nil || checkPath(path) != nil {
+		return "", false
+	}
+	return path, true
+}
+
+func (u cmsURLs) SignedURL(ctx context.Context, path string, expiresIn time.Duration) (string, error) {
+	if err := checkPath(path); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(time.Now().Add(expiresIn).Unix(), 10)
+	query := url.Values{"expires": {expires}, "signature": {u.sign(path, expires)}}
+	return u.URL(path) + "?" + query.Encode(), nil
+}
+
+func (u cmsURLs) VerifySignature(path, expires, signature string) error {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(u.sign(path, expires))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (u cmsURLs) sign(path, expires string) string {
+	mac := hmac.New(sha256.New, u.key)
+	mac.Write([]byte(path + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	gcs "cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// GCSStore keeps objects in a Google Cloud Storage bucket.
+type GCSStore struct {
+	client  *gcs.Client
+	bucket  string
+	baseURL string // public URL of the bucket, ending in "/"
+	signer  GCSOptions
+}
+
+// GCSOptions configure a GCSStore.
+type GCSOptions struct {
+	// PublicURL is where the bucket's objects are addressed, such as a CDN
+	// in front of it; it defaults to https://storage.googleapis.com/<bucket>.
+	PublicURL string
+	// SignerEmail and SignerKey (PEM) are the service account URLs are
+	// signed as. Without them the client's own credentials sign.
+	SignerEmail string
+	SignerKey   []byte
+}
+
+// NewGCSStore returns a store for a bucket.
+func NewGCSStore(client *gcs.Client, bucket string, opts GCSOptions) (*GCSStore, error) {
+	if client == nil {
+		return nil, errors.New("GCS store needs a client")
+	}
+	if bucket == "" {
+		return nil, errors.New("GCS store needs GCS_BUCKET")
+	}
+	publicURL := opts.PublicURL
+	if publicURL == "" {
+		publicURL = "https://storage.googleapis.com/" + bucket
+	}
+	return &GCSStore{
+		client:  client,
+		bucket:  bucket,
+		baseURL: strings.TrimSuffix(publicURL, "/") + "/",
+		signer:  opts,
+	}, nil
+}
+
+func (s *GCSStore) object(path string) *gcs.ObjectHandle {
+	return s.client.Bucket(s.bucket).Object(path)
+}
+
+func (s *GCSStore) Upload(ctx context.Context, path string, r io.Reader, opts UploadOptions) error {
+	if err := checkPath(path); err != nil {
+		return err
+	}
+	wc := s.object(path).NewWriter(ctx)
+	wc.ContentType = opts.ContentType
+	// No ACL: objects get the bucket's default, private access
+	wc.CacheControl = "private, max-age=86400"
+
+	if _, err := io.Copy(wc, r); err != nil {
+		wc.Close()
+		return fmt.Errorf("upload failed: %w", err)
+	}
+	if err := wc.Close(); err != nil {
+		return fmt.Errorf("finalizing upload failed: %w", err)
+	}
+	return nil
+}
+
+func (s *GCSStore) Download(ctx context.Context, path string) (io.ReadCloser, error) {
+	reader, err := s.object(path).NewReader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", gcsError(err))
+	}
+	return reader, nil
+}
+
+func (s *GCSStore) Delete(ctx context.Context, path string) error {
+	if err := s.object(path).Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete file: %w", gcsError(err))
+	}
+	log.Printf("Deleted file: %s", path)
+	return nil
+}
+
+func (s *GCSStore) Stat(ctx context.Context, path string) (*Object, error) {
+	attrs, err := s.object(path).Attrs(ctx)
+	if err != nil {
+		return nil, gcsError(err)
+	}
+	return gcsObject(attrs), nil
+}
+
+func (s *GCSStore) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	it := s.client.Bucket(s.bucket).Objects(ctx, &gcs.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("listing %s: %w", prefix, err)
+		}
+		objects = append(objects, *gcsObject(attrs))
+	}
+}
+
+// SignedURL returns a V4 signed GET URL.
+func (s *GCSStore) SignedURL(
+	ctx context.Context,
+	path string,
+	expiresIn time.Duration,
+) (string, error) {
+	url, err := s.client.Bucket(s.bucket).SignedURL(path, &gcs.SignedURLOptions{
+		Scheme:         gcs.SigningSchemeV4,
+		Method:         "GET",
+		Expires:        time.Now().Add(expiresIn),
+		GoogleAccessID: s.signer.SignerEmail,
+		PrivateKey:     s.signer.SignerKey,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to generate signed URL: %w", err)
+	}
+	return url, nil
+}
+
+func (s *GCSStore) URL(path string) string {
+	return s.baseURL + path
+}
+
+// ObjectPath accepts the URLs made by URL, the storage.googleapis.com URLs
+// of earlier uploads and gs:// URLs.
+func (s *GCSStore) ObjectPath(url string) (string, bool) {
+	for _, prefix := range []string{
+		s.baseURL,
+		"https://storage.googleapis.com/" + s.bucket + "/",
+		"gs://" + s.bucket + "/",
+	} {
+		if rest, found := strings.CutPrefix(url, prefix); found && rest != "" {
+			return rest, true
+		}
+	}
+	return "", false
+}
+
+func gcsObject(attrs *gcs.ObjectAttrs) *Object {
+	return &Object{
+		Path:        attrs.Name,
+		Size:        attrs.Size,
+		ContentType: attrs.ContentType,
+		Updated:     attrs.Updated,
+	}
+}
+
+// gcsError makes missing objects match ErrNotFound.
+func gcsError(err error) error {
+	if errors.Is(err, gcs.ErrObjectNotExist) {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	return err
+}
@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// tempPrefix marks files still being written by Upload.
+const tempPrefix = ".upload-"
+
+// LocalStore keeps objects as files under a directory, for development and
+// self-hosting. The CMS serves them at FilesPath to holders of a URL signed
+// with an HMAC key; content types come from the file extension.
+type LocalStore struct {
+	cmsURLs
+	root string
+}
+
+// NewLocalStore returns a store under dir, creating it if needed. publicURL
+// is where the CMS is reachable, such as http://localhost:8080, and key
+// signs its URLs.
+func NewLocalStore(dir, publicURL string, key []byte) (*LocalStore, error) {
+	if dir == "" {
+		return nil, errors.New("local store needs STORAGE_DIR")
+	}
+	urls, err := newCMSURLs(publicURL, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating storage directory: %w", err)
+	}
+	return &LocalStore{cmsURLs: urls, root: dir}, nil
+}
+
+func (s *LocalStore) file(p string) (string, error) {
+	if err := checkPath(p); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(p)), nil
+}
+
+// Upload writes to a temporary file first so readers never see a partial
+// object.
+func (s *LocalStore) Upload(ctx context.Context, p string, r io.Reader, opts UploadOptions) error {
+	name, err := s.file(p)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return fmt.Errorf("upload failed: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), tempPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("upload failed: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("upload failed: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("finalizing upload failed: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("finalizing upload failed: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStore) Download(ctx context.Context, p string) (io.ReadCloser, error) {
+	name, err := s.file(p)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", localError(err))
+	}
+	if info, err := f.Stat(); err != nil || info.IsDir() {
+		f.Close()
+		return nil, fmt.Errorf("failed to download file: %w", ErrNotFound)
+	}
+	return f, nil
+}
+
+func (s *LocalStore) Delete(ctx context.Context, p string) error {
+	name, err := s.file(p)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil {
+		return fmt.Errorf("failed to delete file: %w", localError(err))
+	}
+	return nil
+}
+
+func (s *LocalStore) Stat(ctx context.Context, p string) (*Object, error) {
+	name, err := s.file(p)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, localError(err)
+	}
+	if info.IsDir() {
+		return nil, ErrNotFound
+	}
+	return localObject(p, info), nil
+}
+
+func (s *LocalStore) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	err := filepath.WalkDir(s.root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), tempPrefix) {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, name)
+		if err != nil {
+			return err
+		}
+		p := filepath.ToSlash(rel)
+		if !strings.HasPrefix(p, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, *localObject(p, info))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", prefix, err)
+	}
+	return objects, nil
+}
+
+func localObject(p string, info fs.FileInfo) *Object {
+	ct := mime.TypeByExtension(path.Ext(p))
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+	return &Object{
+		Path:        p,
+		Size:        info.Size(),
+		ContentType: ct,
+		Updated:     info.ModTime().UTC(),
+	}
+}
+
+// localError makes missing files match ErrNotFound.
+func localError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	return err
+}
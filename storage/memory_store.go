@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps objects in memory, for tests. Like LocalStore, the CMS
+// serves them at FilesPath to holders of a signed URL.
+type MemoryStore struct {
+	cmsURLs
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+}
+
+type memoryObject struct {
+	data []byte
+	Object
+}
+
+// NewMemoryStore returns an empty store. publicURL is where the CMS is
+// reachable, such as http://localhost:8080, and key signs its URLs.
+func NewMemoryStore(publicURL string, key []byte) (*MemoryStore, error) {
+	urls, err := newCMSURLs(publicURL, key)
+	if err != nil {
+		return nil, err
+	}
+	return &MemoryStore{cmsURLs: urls, objects: make(map[string]memoryObject)}, nil
+}
+
+func (s *MemoryStore) Upload(ctx context.Context, path string, r io.Reader, opts UploadOptions) error {
+	if err := checkPath(path); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("upload failed: %w", err)
+	}
+	ct := opts.ContentType
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[path] = memoryObject{
+		data: data,
+		Object: Object{
+			Path:        path,
+			Size:        int64(len(data)),
+			ContentType: ct,
+			Updated:     time.Now().UTC(),
+		},
+	}
+	return nil
+}
+
+func (s *MemoryStore) Download(ctx context.Context, path string) (io.ReadCloser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	obj, ok := s.objects[path]
+	if !ok {
+		return nil, fmt.Errorf("failed to download file: %w", ErrNotFound)
+	}
+	// Uploads replace data rather than changing it, so it can be shared
+	return io.NopCloser(bytes.NewReader(obj.data)), nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.objects[path]; !ok {
+		return fmt.Errorf("failed to delete file: %w", ErrNotFound)
+	}
+	delete(s.objects, path)
+	return nil
+}
+
+func (s *MemoryStore) Stat(ctx context.Context, path string) (*Object, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	obj, ok := s.objects[path]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	info := obj.Object
+	return &info, nil
+}
+
+func (s *MemoryStore) List(ctx context.Context, prefix string) ([]Object, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var objects []Object
+	for path, obj := range s.objects {
+		if strings.HasPrefix(path, prefix) {
+			objects = append(objects, obj.Object)
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Path < objects[j].Path })
+	return objects, nil
+}